@@ -0,0 +1,147 @@
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Line    int
+	re      *regexp.Regexp
+}
+
+type Ruleset struct {
+	Rules []Rule
+}
+
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Parse reads a CODEOWNERS file. Owners are written as @user_id for a single
+// user or @org/team for a whole team; the leading @ is stripped.
+func Parse(content string) (*Ruleset, error) {
+	rs := &Ruleset{}
+	for i, raw := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		fields := strings.Fields(line)
+		pattern := fields[0]
+		if strings.HasPrefix(pattern, "!") {
+			return nil, &ParseError{Line: lineNo, Msg: "negated patterns are not supported"}
+		}
+
+		re, err := compile(pattern)
+		if err != nil {
+			return nil, &ParseError{Line: lineNo, Msg: "invalid pattern " + pattern}
+		}
+
+		owners := make([]string, 0, len(fields)-1)
+		for _, o := range fields[1:] {
+			if !strings.HasPrefix(o, "@") || len(o) == 1 {
+				return nil, &ParseError{Line: lineNo, Msg: "owner must start with @: " + o}
+			}
+			owners = append(owners, strings.TrimPrefix(o, "@"))
+		}
+
+		rs.Rules = append(rs.Rules, Rule{Pattern: pattern, Owners: owners, Line: lineNo, re: re})
+	}
+	return rs, nil
+}
+
+// Owners returns the owners of the last rule matching path. A matching rule
+// without owners leaves the path explicitly unowned.
+func (rs *Ruleset) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(rs.Rules) - 1; i >= 0; i-- {
+		if rs.Rules[i].re.MatchString(path) {
+			return rs.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnersByPath resolves owners for every path and drops unowned ones.
+func (rs *Ruleset) OwnersByPath(paths []string) map[string][]string {
+	out := make(map[string][]string, len(paths))
+	for _, p := range paths {
+		if owners := rs.Owners(p); len(owners) > 0 {
+			out[p] = owners
+		}
+	}
+	return out
+}
+
+func IsTeam(owner string) bool {
+	return strings.Contains(owner, "/")
+}
+
+func TeamName(owner string) string {
+	return owner[strings.LastIndex(owner, "/")+1:]
+}
+
+// compile turns a gitignore-style pattern into a regular expression. Patterns
+// containing a slash are anchored at the repository root, others match at any
+// depth; a pattern matching a directory also matches everything below it.
+func compile(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '*' && i+1 < len(p) && p[i+1] == '*':
+			i++
+			atStart := i == 1
+			followedBySlash := i+1 < len(p) && p[i+1] == '/'
+			switch {
+			case followedBySlash && (atStart || p[i-2] == '/'):
+				b.WriteString("(?:.*/)?")
+				i++
+			default:
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,101 @@
+package codeowners
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPatternMatching(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/services/pr_service.go", true},
+		{"*.go", "main.gox", false},
+		{"docs/*", "docs/api.md", true},
+		{"docs/*", "docs/api/v1.md", true},
+		{"docs/*.md", "docs/api/v1.md", false},
+		{"**/testdata", "testdata/a.json", true},
+		{"**/testdata", "internal/services/testdata/github/opened.json", true},
+		{"internal/**/repository.go", "internal/repository.go", true},
+		{"internal/**/repository.go", "internal/a/b/repository.go", true},
+		{"internal/**", "internal/a/b.go", true},
+		{"internal/**", "cmd/internal/b.go", false},
+		{"/migrations", "migrations/0001_init.sql", true},
+		{"/migrations", "backend/migrations/0001_init.sql", false},
+		{"migrations", "backend/migrations/0001_init.sql", true},
+		{"build/", "build/out.bin", true},
+		{"build/", "src/build/out.bin", true},
+		{"build/", "build", false},
+		{"Makefile", "Makefile", true},
+		{"Makefile", "/Makefile", true},
+		{"a+b.(c)", "a+b.(c)", true},
+		{"a+b.(c)", "aab.xc", false},
+		{"file[1].txt", "file[1].txt", true},
+		{"file[1].txt", "file1.txt", false},
+		{"v?.json", "v1.json", true},
+		{"v?.json", "v10.json", false},
+	}
+	for _, tc := range cases {
+		rs, err := Parse(tc.pattern + " @owner")
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.pattern, err)
+		}
+		if got := rs.Owners(tc.path) != nil; got != tc.match {
+			t.Errorf("%q matches %q = %v, want %v", tc.pattern, tc.path, got, tc.match)
+		}
+	}
+}
+
+func TestLastMatchWins(t *testing.T) {
+	rs, err := Parse(`
+# default owners
+*                 @org/backend
+*.md              @writer   # docs
+/internal/billing/ @alice @org/payments
+/internal/billing/README.md
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string][]string{
+		"main.go":                        {"org/backend"},
+		"docs/guide.md":                  {"writer"},
+		"internal/billing/invoice.go":    {"alice", "org/payments"},
+		"internal/billing/notes.md":      {"alice", "org/payments"},
+		"internal/billing/README.md":     nil,
+		"internal/billing/sub/README.md": {"alice", "org/payments"},
+	}
+	for path, want := range cases {
+		if got := rs.Owners(path); !slices.Equal(got, want) {
+			t.Errorf("Owners(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	byPath := rs.OwnersByPath([]string{"main.go", "internal/billing/README.md"})
+	if len(byPath) != 1 || !slices.Equal(byPath["main.go"], []string{"org/backend"}) {
+		t.Errorf("OwnersByPath = %v, want only main.go", byPath)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, content := range []string{
+		"!*.go @owner",
+		"*.go owner",
+		"*.go @",
+		"/ @owner",
+	} {
+		_, err := Parse("# header\n" + content)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded", content)
+			continue
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Line != 2 {
+			t.Errorf("Parse(%q) error = %v, want a ParseError on line 2", content, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditQuery struct {
+	Actor      string     `form:"actor"`
+	Action     string     `form:"action"`
+	EntityType string     `form:"entity_type"`
+	EntityID   string     `form:"entity_id"`
+	RequestID  string     `form:"request_id"`
+	Since      *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until      *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	AfterID    int64      `form:"after_id"`
+	Limit      int        `form:"limit"`
+	Format     string     `form:"format"` // export only: jsonl or csv
+}
+
+type AuditEntryDTO struct {
+	ID         int64           `json:"id"`
+	Actor      string          `json:"actor"`
+	TokenID    *int64          `json:"token_id,omitempty"`
+	RequestID  string          `json:"request_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type AuditListResponse struct {
+	Entries []AuditEntryDTO `json:"entries"`
+	// NextAfterID is passed as after_id to fetch the next page; zero when
+	// there are no more entries.
+	NextAfterID int64 `json:"next_after_id,omitempty"`
+}
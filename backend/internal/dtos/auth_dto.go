@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+type IssueTokenRequest struct {
+	UserID string   `json:"user_id" binding:"required"`
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+type RevokeTokenRequest struct {
+	TokenID int64 `json:"token_id" binding:"required"`
+}
+
+type APITokenDTO struct {
+	TokenID   int64      `json:"token_id"`
+	UserID    string     `json:"user_id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+type IssueTokenResponse struct {
+	Token APITokenDTO `json:"token"`
+	// Secret is shown only once; the service keeps just its hash.
+	Secret string `json:"secret"`
+}
+
+type APITokenListResponse struct {
+	Tokens []APITokenDTO `json:"tokens"`
+}
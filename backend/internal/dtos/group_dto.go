@@ -0,0 +1,52 @@
+package dtos
+
+type ReviewerGroupDTO struct {
+	Name        string         `json:"group_name"`
+	Description string         `json:"description"`
+	Members     []string       `json:"members"`
+	Rules       []GroupRuleDTO `json:"rules"`
+}
+
+type GroupRuleDTO struct {
+	RuleID       int64  `json:"rule_id"`
+	GroupName    string `json:"group_name"`
+	Label        string `json:"label,omitempty"`
+	TitlePattern string `json:"title_pattern,omitempty"`
+}
+
+type AddReviewerGroupRequest struct {
+	Name        string   `json:"group_name" binding:"required"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"`
+}
+
+type SetGroupMembersRequest struct {
+	Name    string   `json:"group_name" binding:"required"`
+	Members []string `json:"members"`
+}
+
+type DeleteReviewerGroupRequest struct {
+	Name string `json:"group_name" binding:"required"`
+}
+
+type AddGroupRuleRequest struct {
+	GroupName    string `json:"group_name" binding:"required"`
+	Label        string `json:"label"`
+	TitlePattern string `json:"title_pattern"`
+}
+
+type DeleteGroupRuleRequest struct {
+	RuleID int64 `json:"rule_id" binding:"required"`
+}
+
+type ReviewerGroupResponse struct {
+	Group ReviewerGroupDTO `json:"group"`
+}
+
+type ReviewerGroupListResponse struct {
+	Groups []ReviewerGroupDTO `json:"groups"`
+}
+
+type GroupRuleResponse struct {
+	Rule GroupRuleDTO `json:"rule"`
+}
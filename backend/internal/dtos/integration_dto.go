@@ -0,0 +1,49 @@
+package dtos
+
+import "time"
+
+// InboundWebhook is a webhook request from an external system, kept raw so
+// its signature can be checked.
+type InboundWebhook struct {
+	Event      string
+	DeliveryID string
+	Signature  string
+	Body       []byte
+}
+
+type IntegrationResponse struct {
+	// Result is created, updated, merged, exists, duplicate or ignored.
+	Result        string `json:"result"`
+	PullRequestID string `json:"pull_request_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+type SetIdentityRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	UserID   string `json:"user_id" binding:"required"`
+}
+
+type DeleteIdentityRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Username string `json:"username" binding:"required"`
+}
+
+type IdentityQuery struct {
+	Provider string `form:"provider"`
+}
+
+type IdentityDTO struct {
+	Provider  string    `json:"provider"`
+	Username  string    `json:"username"`
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type IdentityResponse struct {
+	Identity IdentityDTO `json:"identity"`
+}
+
+type IdentityListResponse struct {
+	Identities []IdentityDTO `json:"identities"`
+}
@@ -0,0 +1,24 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobProgressDTO struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+type JobDTO struct {
+	JobID      int64           `json:"job_id"`
+	Kind       string          `json:"kind"`
+	Status     string          `json:"status"`
+	Progress   JobProgressDTO  `json:"progress"`
+	Items      json.RawMessage `json:"items"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
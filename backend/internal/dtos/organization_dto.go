@@ -0,0 +1,22 @@
+package dtos
+
+import "time"
+
+type AddOrganizationRequest struct {
+	Slug string `json:"slug" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+type OrganizationDTO struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type OrganizationResponse struct {
+	Organization OrganizationDTO `json:"organization"`
+}
+
+type OrganizationListResponse struct {
+	Organizations []OrganizationDTO `json:"organizations"`
+}
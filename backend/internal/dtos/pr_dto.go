@@ -7,18 +7,41 @@ import (
 )
 
 type CreatePRRequest struct {
-	PullRequestID string `json:"pull_request_id" binding:"required"`
-	Title         string `json:"pull_request_name" binding:"required"`
-	Author        string `json:"author_id" binding:"required"`
+	PullRequestID string   `json:"pull_request_id" binding:"required"`
+	Title         string   `json:"pull_request_name" binding:"required"`
+	Author        string   `json:"author_id" binding:"required"`
+	Repository    string   `json:"repository"`
+	SourceBranch  string   `json:"source_branch"`
+	TargetBranch  string   `json:"target_branch"`
+	Description   string   `json:"description"`
+	URL           string   `json:"url"`
+	Labels        []string `json:"labels"`
+	ChangedFiles  []string `json:"changed_files"`
+	RequiredTags  []string `json:"required_tags"`
+}
+
+type UpdatePRRequest struct {
+	PullRequestID string    `json:"pull_request_id" binding:"required"`
+	Title         *string   `json:"pull_request_name"`
+	Repository    *string   `json:"repository"`
+	SourceBranch  *string   `json:"source_branch"`
+	TargetBranch  *string   `json:"target_branch"`
+	Description   *string   `json:"description"`
+	URL           *string   `json:"url"`
+	Labels        *[]string `json:"labels"`
+	// IfMatch is the version from the If-Match header, nil when absent.
+	IfMatch *int64 `json:"-"`
 }
 
 type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id" binding:"required"`
+	IfMatch       *int64 `json:"-"`
 }
 
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id" binding:"required"`
 	OldUserID     string `json:"old_reviewer_id" binding:"required"`
+	IfMatch       *int64 `json:"-"`
 }
 
 type PRResponse struct {
@@ -31,13 +54,23 @@ type ReassignResponse struct {
 }
 
 type PullRequestDTO struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	Title             string     `json:"pull_request_name"`
-	AuthorUserID      string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         time.Time  `json:"createdAt,omitempty"`
-	UpdatedAt         *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID     string   `json:"pull_request_id"`
+	Title             string   `json:"pull_request_name"`
+	AuthorUserID      string   `json:"author_id"`
+	Status            string   `json:"status"`
+	Repository        string   `json:"repository"`
+	SourceBranch      string   `json:"source_branch"`
+	TargetBranch      string   `json:"target_branch"`
+	Description       string   `json:"description"`
+	URL               string   `json:"url"`
+	Labels            []string `json:"labels"`
+	RequiredTags      []string `json:"required_tags"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	// ReviewerMatches maps each reviewer to the required tags they matched.
+	ReviewerMatches map[string][]string `json:"reviewer_matched_tags,omitempty"`
+	CreatedAt       time.Time           `json:"createdAt,omitempty"`
+	UpdatedAt       *time.Time          `json:"mergedAt,omitempty"`
+	Version         int64               `json:"version"`
 }
 
 type PullRequestShort struct {
@@ -50,4 +83,72 @@ type PullRequestShort struct {
 type PRWithReviewers struct {
 	PR        models.PullRequest
 	Reviewers []models.User
+	// RequiredGroups maps reviewer internal id to the reviewer group their slot is reserved for.
+	RequiredGroups map[int64]int64
+}
+
+type AssignmentEventDTO struct {
+	EventID       int64     `json:"event_id"`
+	PullRequestID string    `json:"pull_request_id"`
+	Kind          string    `json:"kind"`
+	Seed          int64     `json:"seed"`
+	Reviewers     []string  `json:"reviewers"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type AssignmentEventListResponse struct {
+	PullRequestID string               `json:"pull_request_id"`
+	Events        []AssignmentEventDTO `json:"events"`
+}
+
+type ReplayAssignmentRequest struct {
+	EventID int64 `json:"event_id" binding:"required"`
+}
+
+type ReplayAssignmentResponse struct {
+	Event    AssignmentEventDTO `json:"event"`
+	Replayed []string           `json:"replayed_reviewers"`
+	Matches  bool               `json:"matches"`
+	Error    string             `json:"error,omitempty"`
+}
+
+type ExcludedCandidateDTO struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+type SlotExplanationDTO struct {
+	Slot       int                    `json:"slot"`
+	ReviewerID string                 `json:"reviewer_id"`
+	Rule       string                 `json:"rule"`
+	Detail     string                 `json:"detail"`
+	Considered []string               `json:"considered"`
+	Excluded   []ExcludedCandidateDTO `json:"excluded"`
+	EventID    int64                  `json:"event_id"`
+	AssignedAt time.Time              `json:"assignedAt"`
+}
+
+type ExplainResponse struct {
+	PullRequestID     string               `json:"pull_request_id"`
+	AssignedReviewers []string             `json:"assigned_reviewers"`
+	Explanations      []SlotExplanationDTO `json:"explanations"`
+}
+
+type SuggestedReviewerDTO struct {
+	UserID      string   `json:"user_id"`
+	Username    string   `json:"username"`
+	Rank        int      `json:"rank"`
+	Score       int      `json:"score"`
+	Selected    bool     `json:"selected"`
+	Rule        string   `json:"rule"`
+	Seniority   string   `json:"seniority,omitempty"`
+	MatchedTags []string `json:"matched_tags"`
+	RecentPairs int      `json:"recent_pairs"`
+	OpenReviews int      `json:"open_reviews"`
+}
+
+type SuggestReviewersResponse struct {
+	PullRequestID string                 `json:"pull_request_id"`
+	Reviewers     []string               `json:"reviewers"`
+	Candidates    []SuggestedReviewerDTO `json:"candidates"`
 }
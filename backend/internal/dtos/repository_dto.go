@@ -0,0 +1,45 @@
+package dtos
+
+type RepositoryDTO struct {
+	Name         string   `json:"repository_name"`
+	URL          string   `json:"url"`
+	OwnerTeams   []string `json:"owner_teams"`
+	ReviewerPool []string `json:"reviewer_pool"`
+}
+
+type AddRepositoryRequest struct {
+	Name         string   `json:"repository_name" binding:"required"`
+	URL          string   `json:"url"`
+	OwnerTeams   []string `json:"owner_teams"`
+	ReviewerPool []string `json:"reviewer_pool"`
+}
+
+type UpdateRepositoryRequest struct {
+	Name         string    `json:"repository_name" binding:"required"`
+	URL          *string   `json:"url"`
+	OwnerTeams   *[]string `json:"owner_teams"`
+	ReviewerPool *[]string `json:"reviewer_pool"`
+}
+
+type DeleteRepositoryRequest struct {
+	Name string `json:"repository_name" binding:"required"`
+}
+
+type RepositoryResponse struct {
+	Repository RepositoryDTO `json:"repository"`
+}
+
+type RepositoryListResponse struct {
+	Repositories []RepositoryDTO `json:"repositories"`
+}
+
+type UploadCodeownersRequest struct {
+	Name    string `json:"repository_name" binding:"required"`
+	Content string `json:"content"`
+}
+
+type CodeownersResponse struct {
+	Name    string `json:"repository_name"`
+	Content string `json:"content"`
+	Rules   int    `json:"rules"`
+}
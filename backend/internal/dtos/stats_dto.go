@@ -20,3 +20,17 @@ type AssignmentStats struct {
 	PRStats          []PRStats       `json:"pr_stats"`
 	TotalAssignments int             `json:"total_assignments"`
 }
+
+type PairStats struct {
+	AuthorUserID   string `json:"author_id"`
+	ReviewerUserID string `json:"reviewer_id"`
+	Count          int    `json:"count"`
+}
+
+type PairMatrix struct {
+	WindowDays int                       `json:"window_days"`
+	Authors    []string                  `json:"authors"`
+	Reviewers  []string                  `json:"reviewers"`
+	Matrix     map[string]map[string]int `json:"matrix"`
+	Pairs      []PairStats               `json:"pairs"`
+}
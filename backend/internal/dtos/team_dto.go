@@ -1,14 +1,17 @@
 package dtos
 
 type TeamMemberDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	IsActive  bool   `json:"is_active"`
+	Seniority string `json:"seniority,omitempty"`
 }
 
 type TeamDTO struct {
-	TeamName string          `json:"team_name"`
-	Members  []TeamMemberDTO `json:"members"`
+	TeamName     string          `json:"team_name"`
+	ReviewPolicy string          `json:"review_policy,omitempty"`
+	Members      []TeamMemberDTO `json:"members"`
+	Version      int64           `json:"version"`
 }
 
 type AddTeamRequest struct {
@@ -23,14 +26,70 @@ type TeamResponse struct {
 type BulkDeactivateRequest struct {
 	TeamName string   `json:"team_name"`
 	UserIDs  []string `json:"user_ids"`
+	DryRun   bool     `json:"dry_run"`
 }
 
 type BulkDeactivateResponse struct {
+	OperationID      int64                 `json:"operation_id,omitempty"`
+	DryRun           bool                  `json:"dry_run"`
 	DeactivatedUsers []string              `json:"deactivated_users"`
 	ReassignedPRs    []ReassignedPRSummary `json:"reassigned_prs"`
+	Warnings         []RestaffWarning      `json:"warnings"`
+}
+
+// RestaffWarning reports a PR whose deactivated reviewers could not all be
+// replaced.
+type RestaffWarning struct {
+	PullRequestID      string   `json:"pull_request_id"`
+	Code               string   `json:"code"` // UNDERSTAFFED or NO_REVIEWERS
+	UnreplacedUsers    []string `json:"unreplaced_users"`
+	RemainingReviewers int      `json:"remaining_reviewers"`
 }
 
 type ReassignedPRSummary struct {
 	PullRequestID string            `json:"pull_request_id"`
 	Replacements  map[string]string `json:"replacements"` // old_user_id -> new_user_id
 }
+
+// BulkPRResult is the per-PR outcome reported by an asynchronous bulk
+// deactivation job.
+type BulkPRResult struct {
+	PullRequestID string            `json:"pull_request_id"`
+	Status        string            `json:"status"` // REASSIGNED, UNCHANGED or FAILED
+	Replacements  map[string]string `json:"replacements"`
+	Errors        []string          `json:"errors,omitempty"`
+}
+
+type UndoBulkDeactivateRequest struct {
+	OperationID int64 `json:"operation_id" binding:"required"`
+}
+
+type UndoBulkDeactivateResponse struct {
+	OperationID   int64                 `json:"operation_id"`
+	RestoredUsers []string              `json:"restored_users"`
+	RestoredPRs   []ReassignedPRSummary `json:"restored_prs"` // replacements map current -> original reviewer
+	SkippedPRs    []SkippedPR           `json:"skipped_prs"`
+}
+
+type SkippedPR struct {
+	PullRequestID string `json:"pull_request_id"`
+	Reason        string `json:"reason"` // MERGED or CHANGED
+}
+
+type SetTeamRoleRequest struct {
+	TeamName string `json:"team_name" binding:"required"`
+	UserID   string `json:"user_id" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+type SetTeamRoleResponse struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+type SetReviewPolicyRequest struct {
+	TeamName     string `json:"team_name" binding:"required"`
+	ReviewPolicy string `json:"review_policy" binding:"required"`
+	IfMatch      *int64 `json:"-"`
+}
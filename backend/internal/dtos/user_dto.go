@@ -6,10 +6,12 @@ type SetIsActiveRequest struct {
 }
 
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID    string   `json:"user_id"`
+	Username  string   `json:"username"`
+	TeamName  string   `json:"team_name"`
+	IsActive  bool     `json:"is_active"`
+	Tags      []string `json:"tags"`
+	Seniority string   `json:"seniority,omitempty"`
 }
 
 type SetIsActiveResponse struct {
@@ -27,3 +29,21 @@ type ReviewPullRequest struct {
 	AuthorUserID    string `json:"author_id"`
 	Status          string `json:"status"`
 }
+
+type UserTagsRequest struct {
+	UserID string   `json:"user_id"`
+	Tags   []string `json:"tags"`
+}
+
+type UserTagsResponse struct {
+	User User `json:"user"`
+}
+
+type SetSeniorityRequest struct {
+	UserID    string `json:"user_id"`
+	Seniority string `json:"seniority"`
+}
+
+type SetSeniorityResponse struct {
+	User User `json:"user"`
+}
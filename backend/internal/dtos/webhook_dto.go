@@ -0,0 +1,88 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AddWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	// Secret signs the payloads; one is generated when it is empty.
+	Secret string `json:"secret"`
+}
+
+type DeleteWebhookRequest struct {
+	SubscriptionID int64 `json:"subscription_id" binding:"required"`
+}
+
+type RedeliverWebhookRequest struct {
+	DeliveryID int64 `json:"delivery_id" binding:"required"`
+}
+
+type WebhookDeliveryQuery struct {
+	SubscriptionID int64  `form:"subscription_id"`
+	Status         string `form:"status"`
+	AfterID        int64  `form:"after_id"`
+	Limit          int    `form:"limit"`
+}
+
+type WebhookSubscriptionDTO struct {
+	SubscriptionID int64     `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Events         []string  `json:"events"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type AddWebhookResponse struct {
+	Subscription WebhookSubscriptionDTO `json:"subscription"`
+	// Secret is shown only once.
+	Secret string `json:"secret"`
+}
+
+type WebhookListResponse struct {
+	Subscriptions []WebhookSubscriptionDTO `json:"subscriptions"`
+}
+
+type WebhookDeliveryDTO struct {
+	DeliveryID     int64           `json:"delivery_id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	Delivery WebhookDeliveryDTO `json:"delivery"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries  []WebhookDeliveryDTO `json:"deliveries"`
+	NextAfterID int64                `json:"next_after_id,omitempty"`
+}
+
+// WebhookEvent is the body POSTed to subscribers.
+type WebhookEvent struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"`
+}
+
+// ReviewerEventData describes reviewer.assigned and reviewer.reassigned.
+type ReviewerEventData struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	// ReplacedReviewerID is set for reassignments.
+	ReplacedReviewerID string `json:"replaced_reviewer_id,omitempty"`
+}
+
+type UserEventData struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
@@ -9,11 +9,28 @@ const (
 	CodeNotFound   Code = "NOT_FOUND"
 	CodeInternal   Code = "INTERNAL"
 
-	CodeTeamExists  Code = "TEAM_EXISTS"
-	CodePRExists    Code = "PR_EXISTS"
-	CodePRMerged    Code = "PR_MERGED"
-	CodeNotAssigned Code = "NOT_ASSIGNED"
-	CodeNoCandidate Code = "NO_CANDIDATE"
+	CodeTeamExists       Code = "TEAM_EXISTS"
+	CodeRepositoryExists Code = "REPOSITORY_EXISTS"
+	CodeGroupExists      Code = "GROUP_EXISTS"
+	CodeOrgExists        Code = "ORGANIZATION_EXISTS"
+	CodePRExists         Code = "PR_EXISTS"
+	CodePRMerged         Code = "PR_MERGED"
+	CodeNotAssigned      Code = "NOT_ASSIGNED"
+	CodeNoCandidate      Code = "NO_CANDIDATE"
+	CodeRequiredGroup    Code = "REQUIRED_GROUP_UNAVAILABLE"
+	CodeSeniorityPolicy  Code = "SENIORITY_POLICY_UNSATISFIED"
+	CodeCodeOwner        Code = "CODE_OWNER_UNAVAILABLE"
+	CodeAlreadyUndone    Code = "OPERATION_ALREADY_UNDONE"
+	// CodeConflict means the request raced with another change to the same
+	// data and may be retried as is.
+	CodeConflict Code = "CONCURRENT_MODIFICATION"
+	// CodeStaleVersion means the If-Match precondition did not hold.
+	CodeStaleVersion Code = "STALE_VERSION"
+
+	CodeUnauthorized      Code = "UNAUTHORIZED"
+	CodeInsufficientScope Code = "INSUFFICIENT_SCOPE"
+	CodeForbidden         Code = "FORBIDDEN"
+	CodeRateLimited       Code = "RATE_LIMITED"
 )
 
 type DomainError struct {
@@ -29,6 +46,12 @@ func New(code Code, msg string) error {
 	return &DomainError{Code: code, Message: msg}
 }
 
+// Retryable reports whether a request that failed with code can be retried
+// unchanged.
+func Retryable(code Code) bool {
+	return code == CodeConflict
+}
+
 func IsDomain(err error) (DomainError, bool) {
 	if err == nil {
 		return DomainError{}, false
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+type AuditHandler struct {
+	svc *services.AuditService
+}
+
+func NewAuditHandler(s *services.AuditService) *AuditHandler {
+	return &AuditHandler{svc: s}
+}
+
+func (h *AuditHandler) List(c *gin.Context) {
+	var q dtos.AuditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.List(c.Request.Context(), q)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *AuditHandler) Export(c *gin.Context) {
+	var q dtos.AuditQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	var contentType, filename string
+	switch q.Format {
+	case "", "jsonl":
+		contentType, filename = "application/x-ndjson", "audit.jsonl"
+	case "csv":
+		contentType, filename = "text/csv", "audit.csv"
+	default:
+		RenderError(c, errors.New(errors.CodeValidation, "format must be jsonl or csv"))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	if err := h.svc.Export(c.Request.Context(), q, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			RenderError(c, err)
+			return
+		}
+		// The status line is already sent; all we can do is cut the stream short.
+		log.Printf("audit export aborted: %v", err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+type AuthHandler struct {
+	svc services.AuthService
+}
+
+func NewAuthHandler(s services.AuthService) *AuthHandler {
+	return &AuthHandler{svc: s}
+}
+
+func (h *AuthHandler) IssueToken(c *gin.Context) {
+	var req dtos.IssueTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.IssueToken(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *AuthHandler) RevokeToken(c *gin.Context) {
+	var req dtos.RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	if err := h.svc.RevokeToken(c.Request.Context(), req); err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ListTokens(c *gin.Context) {
+	resp, err := h.svc.ListTokens(c.Request.Context())
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+const organizationHeader = "X-Organization"
+
+// Authenticate resolves the bearer token of every request and attaches the
+// caller, and with it the organization the request acts in, to the request
+// context.
+func Authenticate(auth services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		secret, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			secret = ""
+		}
+		org := strings.TrimSpace(c.GetHeader(organizationHeader))
+
+		principal, err := auth.Authenticate(c.Request.Context(), strings.TrimSpace(secret), org)
+		if err != nil {
+			RenderError(c, err)
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(services.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireScope rejects callers whose token lacks scope.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := services.PrincipalFrom(c.Request.Context())
+		if !ok {
+			RenderError(c, errors.New(errors.CodeUnauthorized, "missing bearer token"))
+			c.Abort()
+			return
+		}
+		if !principal.HasScope(scope) {
+			RenderError(c, errors.New(errors.CodeInsufficientScope, "token lacks scope "+scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
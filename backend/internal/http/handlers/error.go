@@ -10,6 +10,9 @@ import (
 
 func RenderError(c *gin.Context, err error) {
 	if de, ok := errors.IsDomain(err); ok {
+		if errors.Retryable(de.Code) {
+			c.Header("Retry-After", "1")
+		}
 		c.JSON(statusFromDomain(de.Code), gin.H{
 			"error": gin.H{
 				"code":    string(de.Code),
@@ -33,8 +36,17 @@ func statusFromDomain(code errors.Code) int {
 		return http.StatusNotFound
 	case errors.CodeValidation:
 		return http.StatusBadRequest
-	case errors.CodePRMerged:
+	case errors.CodeUnauthorized:
+		return http.StatusUnauthorized
+	case errors.CodeInsufficientScope, errors.CodeForbidden:
+		return http.StatusForbidden
+	case errors.CodeStaleVersion:
+		return http.StatusPreconditionFailed
+	case errors.CodePRMerged, errors.CodeRequiredGroup, errors.CodeSeniorityPolicy, errors.CodeCodeOwner,
+		errors.CodeAlreadyUndone, errors.CodeConflict:
 		return http.StatusConflict
+	case errors.CodeRateLimited:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusBadRequest
 	}
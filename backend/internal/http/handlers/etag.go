@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+)
+
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ifMatch parses the If-Match header into the expected version. A missing
+// header or "*" yields nil, meaning no precondition. If-Match uses the strong
+// comparison, which a weak tag never passes.
+func ifMatch(c *gin.Context) (*int64, error) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" || raw == "*" {
+		return nil, nil
+	}
+	if strings.HasPrefix(raw, "W/") {
+		return nil, errors.New(errors.CodeStaleVersion, "weak entity tags do not match in If-Match")
+	}
+	version, err := strconv.ParseInt(strings.Trim(raw, `"`), 10, 64)
+	if err != nil {
+		return nil, errors.New(errors.CodeValidation, "invalid If-Match header")
+	}
+	return &version, nil
+}
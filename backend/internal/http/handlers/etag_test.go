@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+)
+
+func TestIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		header  string
+		version int64
+		code    errors.Code
+	}{
+		{header: ""},
+		{header: "*"},
+		{header: `"7"`, version: 7},
+		{header: `W/"7"`, code: errors.CodeStaleVersion},
+		{header: `"seven"`, code: errors.CodeValidation},
+	}
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+		c.Request.Header.Set("If-Match", tc.header)
+
+		got, err := ifMatch(c)
+		if tc.code != "" {
+			if de, ok := errors.IsDomain(err); !ok || de.Code != tc.code {
+				t.Errorf("If-Match %s: error = %v, want %s", tc.header, err, tc.code)
+			}
+			continue
+		}
+		switch {
+		case err != nil:
+			t.Errorf("If-Match %s: %v", tc.header, err)
+		case tc.version == 0 && got != nil:
+			t.Errorf("If-Match %s = %d, want no precondition", tc.header, *got)
+		case tc.version != 0 && (got == nil || *got != tc.version):
+			t.Errorf("If-Match %s = %v, want %d", tc.header, got, tc.version)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+type GroupHandler struct {
+	svc services.GroupService
+}
+
+func NewGroupHandler(s services.GroupService) *GroupHandler {
+	return &GroupHandler{svc: s}
+}
+
+func (h *GroupHandler) Add(c *gin.Context) {
+	var in dtos.AddReviewerGroupRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	resp, err := h.svc.Add(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *GroupHandler) Get(c *gin.Context) {
+	name := c.Query("group_name")
+	if name == "" {
+		RenderError(c, errors.New(errors.CodeValidation, "group_name required"))
+		return
+	}
+	resp, err := h.svc.Get(c.Request.Context(), name)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *GroupHandler) List(c *gin.Context) {
+	resp, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *GroupHandler) SetMembers(c *gin.Context) {
+	var in dtos.SetGroupMembersRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	resp, err := h.svc.SetMembers(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *GroupHandler) Delete(c *gin.Context) {
+	var in dtos.DeleteReviewerGroupRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), in); err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *GroupHandler) AddRule(c *gin.Context) {
+	var in dtos.AddGroupRuleRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	resp, err := h.svc.AddRule(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *GroupHandler) DeleteRule(c *gin.Context) {
+	var in dtos.DeleteGroupRuleRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	if err := h.svc.DeleteRule(c.Request.Context(), in); err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+// maxWebhookBody bounds inbound webhook payloads; GitHub caps them at 25 MB,
+// but pull request events are far smaller.
+const maxWebhookBody = 5 << 20
+
+type IntegrationHandler struct {
+	svc services.IntegrationService
+}
+
+func NewIntegrationHandler(s services.IntegrationService) *IntegrationHandler {
+	return &IntegrationHandler{svc: s}
+}
+
+func (h *IntegrationHandler) GitHub(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.GitHub(c.Request.Context(), dtos.InboundWebhook{
+		Event:      c.GetHeader("X-GitHub-Event"),
+		DeliveryID: c.GetHeader("X-GitHub-Delivery"),
+		Signature:  c.GetHeader("X-Hub-Signature-256"),
+		Body:       body,
+	})
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *IntegrationHandler) GitLab(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	// Idempotency-Key is kept across retries of one event; older GitLab
+	// versions only send the event UUID.
+	deliveryID := c.GetHeader("Idempotency-Key")
+	if deliveryID == "" {
+		deliveryID = c.GetHeader("X-Gitlab-Event-UUID")
+	}
+	resp, err := h.svc.GitLab(c.Request.Context(), dtos.InboundWebhook{
+		Event:      c.GetHeader("X-Gitlab-Event"),
+		DeliveryID: deliveryID,
+		Signature:  c.GetHeader("X-Gitlab-Token"),
+		Body:       body,
+	})
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *IntegrationHandler) SetIdentity(c *gin.Context) {
+	var req dtos.SetIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.SetIdentity(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *IntegrationHandler) DeleteIdentity(c *gin.Context) {
+	var req dtos.DeleteIdentityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	if err := h.svc.DeleteIdentity(c.Request.Context(), req); err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *IntegrationHandler) ListIdentities(c *gin.Context) {
+	var q dtos.IdentityQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.ListIdentities(c.Request.Context(), q)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
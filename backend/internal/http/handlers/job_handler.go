@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+type JobHandler struct {
+	svc services.JobService
+}
+
+func NewJobHandler(s services.JobService) *JobHandler {
+	return &JobHandler{svc: s}
+}
+
+func (h *JobHandler) Get(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		RenderError(c, errors.New(errors.CodeValidation, "job id must be a positive integer"))
+		return
+	}
+
+	job, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
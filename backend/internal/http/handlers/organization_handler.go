@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+type OrganizationHandler struct {
+	svc services.OrganizationService
+}
+
+func NewOrganizationHandler(s services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{svc: s}
+}
+
+func (h *OrganizationHandler) Add(c *gin.Context) {
+	var req dtos.AddOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.Add(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *OrganizationHandler) List(c *gin.Context) {
+	resp, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
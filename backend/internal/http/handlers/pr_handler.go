@@ -29,15 +29,61 @@ func (h *PRHandler) Create(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.PR.Version)
 	c.JSON(http.StatusCreated, resp)
 }
 
+func (h *PRHandler) SuggestReviewers(c *gin.Context) {
+	var req dtos.CreatePRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.SuggestReviewers(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PRHandler) Update(c *gin.Context) {
+	var req dtos.UpdatePRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	expected, err := ifMatch(c)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	req.IfMatch = expected
+
+	resp, err := h.svc.Update(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	setETag(c, resp.PR.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *PRHandler) Merge(c *gin.Context) {
 	var req dtos.MergePRRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
 		return
 	}
+	expected, err := ifMatch(c)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	req.IfMatch = expected
 
 	resp, err := h.svc.Merge(c.Request.Context(), req)
 	if err != nil {
@@ -45,6 +91,7 @@ func (h *PRHandler) Merge(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.PR.Version)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -54,6 +101,12 @@ func (h *PRHandler) Reassign(c *gin.Context) {
 		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
 		return
 	}
+	expected, err := ifMatch(c)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	req.IfMatch = expected
 
 	resp, err := h.svc.Reassign(c.Request.Context(), req)
 	if err != nil {
@@ -61,5 +114,54 @@ func (h *PRHandler) Reassign(c *gin.Context) {
 		return
 	}
 
+	setETag(c, resp.PR.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PRHandler) Explain(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		RenderError(c, errors.New(errors.CodeValidation, "pull_request_id is required"))
+		return
+	}
+
+	resp, err := h.svc.Explain(c.Request.Context(), prID, c.Query("user_id"))
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PRHandler) AssignmentEvents(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		RenderError(c, errors.New(errors.CodeValidation, "pull_request_id is required"))
+		return
+	}
+
+	resp, err := h.svc.ListAssignmentEvents(c.Request.Context(), prID)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PRHandler) ReplayAssignment(c *gin.Context) {
+	var req dtos.ReplayAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.ReplayAssignment(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+// RateLimitIP charges every request to the bucket of its client IP. It runs
+// before Authenticate, so floods of bad credentials are cut off before any
+// token lookup or JWT verification.
+func RateLimitIP(limiter *services.RateLimiter) gin.HandlerFunc {
+	return rateLimit(limiter, func(c *gin.Context) string { return "ip:" + c.ClientIP() })
+}
+
+// RateLimit charges every request to the bucket of its caller: the API token,
+// the user behind a JWT or the bootstrap token. It must run after
+// Authenticate; requests without a principal are charged to their client IP.
+func RateLimit(limiter *services.RateLimiter) gin.HandlerFunc {
+	return rateLimit(limiter, callerKey)
+}
+
+func callerKey(c *gin.Context) string {
+	p, ok := services.PrincipalFrom(c.Request.Context())
+	switch {
+	case !ok:
+		return "ip:" + c.ClientIP()
+	case p.TokenID != 0:
+		return "token:" + strconv.FormatInt(p.TokenID, 10)
+	case p.UserID != 0:
+		// JWT callers have no token ID; their user is the stable identity.
+		return "user:" + strconv.FormatInt(p.UserID, 10)
+	default:
+		return "bootstrap"
+	}
+}
+
+func rateLimit(limiter *services.RateLimiter, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, wait := limiter.Allow(c.Request.Context(), c.Request.URL.Path, key(c))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(wait.Seconds())))))
+			RenderError(c, errors.New(errors.CodeRateLimited, "rate limit exceeded"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+// ipLimitedEngine serves /ping behind a per-IP limit of one request.
+func ipLimitedEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	limiter := services.NewRateLimiter(map[string]models.RateLimit{
+		services.DefaultRateLimitRule: {Rate: 0.001, Burst: 1},
+	}, services.NewMemoryRateLimitStore())
+	router := newEngine()
+	router.Use(RateLimitIP(limiter))
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return router
+}
+
+func ping(router *gin.Engine, peer, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.RemoteAddr = peer + ":40000"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	router := ipLimitedEngine()
+
+	if code := ping(router, "203.0.113.7", "198.51.100.1"); code != http.StatusNoContent {
+		t.Fatalf("first request = %d, want 204", code)
+	}
+	if code := ping(router, "203.0.113.7", "198.51.100.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("request with a new X-Forwarded-For = %d, want 429", code)
+	}
+	if code := ping(router, "203.0.113.8", ""); code != http.StatusNoContent {
+		t.Fatalf("request from another peer = %d, want 204", code)
+	}
+}
+
+func TestRateLimitIPTrustsConfiguredProxy(t *testing.T) {
+	router := ipLimitedEngine()
+	if err := router.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, client := range []string{"198.51.100.1", "198.51.100.2"} {
+		if code := ping(router, "10.0.0.5", client); code != http.StatusNoContent {
+			t.Fatalf("client %s behind the proxy = %d, want 204", client, code)
+		}
+	}
+	if code := ping(router, "10.0.0.6", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("repeated client behind another proxy = %d, want 429", code)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+type RepositoryHandler struct {
+	svc services.RepositoryService
+}
+
+func NewRepositoryHandler(s services.RepositoryService) *RepositoryHandler {
+	return &RepositoryHandler{svc: s}
+}
+
+func (h *RepositoryHandler) Add(c *gin.Context) {
+	var in dtos.AddRepositoryRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	resp, err := h.svc.Add(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *RepositoryHandler) Get(c *gin.Context) {
+	name := c.Query("repository_name")
+	if name == "" {
+		RenderError(c, errors.New(errors.CodeValidation, "repository_name required"))
+		return
+	}
+	resp, err := h.svc.Get(c.Request.Context(), name)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RepositoryHandler) List(c *gin.Context) {
+	resp, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RepositoryHandler) Update(c *gin.Context) {
+	var in dtos.UpdateRepositoryRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	resp, err := h.svc.Update(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RepositoryHandler) Delete(c *gin.Context) {
+	var in dtos.DeleteRepositoryRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), in); err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *RepositoryHandler) UploadCodeowners(c *gin.Context) {
+	var in dtos.UploadCodeownersRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	resp, err := h.svc.UploadCodeowners(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RepositoryHandler) GetCodeowners(c *gin.Context) {
+	name := c.Query("repository_name")
+	if name == "" {
+		RenderError(c, errors.New(errors.CodeValidation, "repository_name required"))
+		return
+	}
+	resp, err := h.svc.GetCodeowners(c.Request.Context(), name)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
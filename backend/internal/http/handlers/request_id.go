@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID tags every request with the caller's X-Request-ID, or a fresh one,
+// and echoes it back so audit entries can be matched to responses.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 100 {
+			buf := make([]byte, 16)
+			_, _ = rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Header(requestIDHeader, id)
+		c.Request = c.Request.WithContext(services.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
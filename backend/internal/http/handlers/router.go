@@ -2,32 +2,136 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
 )
 
 func NewRouter(
 	teamHandler *TeamHandler,
 	userHandler *UserHandler,
 	prHandler *PRHandler,
+	repositoryHandler *RepositoryHandler,
+	groupHandler *GroupHandler,
 	statsHandler *StatsHandler,
+	jobHandler *JobHandler,
+	authHandler *AuthHandler,
+	auditHandler *AuditHandler,
+	organizationHandler *OrganizationHandler,
+	webhookHandler *WebhookHandler,
+	integrationHandler *IntegrationHandler,
+	ipLimiter *services.RateLimiter,
+	limiter *services.RateLimiter,
+	debugRoutes bool,
 ) *gin.Engine {
-	router := gin.Default()
+	router := newEngine()
+	router.Use(RequestID(), RateLimitIP(ipLimiter))
+
+	// Inbound webhooks are verified by their own signatures rather than bearer
+	// tokens, so they are registered before Authenticate is added.
+	integrations := router.Group("/integrations")
+	integrations.POST("/github/webhook", integrationHandler.GitHub)
+	integrations.POST("/gitlab/webhook", integrationHandler.GitLab)
+
+	router.Use(Authenticate(authHandler.svc), RateLimit(limiter))
+
+	// Route groups sharing a path prefix are split by the scope they need.
+	teamRead := router.Group("/team", RequireScope(models.ScopeTeamRead))
+	teamRead.GET("/get", teamHandler.GetTeam)
+	teamWrite := router.Group("/team", RequireScope(models.ScopeTeamWrite))
+	teamWrite.POST("/add", teamHandler.AddTeam)
+	teamWrite.POST("/setPolicy", teamHandler.SetReviewPolicy)
+	teamWrite.POST("/setRole", teamHandler.SetRole)
+	teamAdmin := router.Group("/team", RequireScope(models.ScopeTeamAdmin))
+	teamAdmin.POST("/bulkDeactivate", teamHandler.BulkDeactivate)
+	teamAdmin.POST("/bulkDeactivate/undo", teamHandler.UndoBulkDeactivate)
 
-	team := router.Group("/team")
-	team.POST("/add", teamHandler.AddTeam)
-	team.GET("/get", teamHandler.GetTeam)
-	team.POST("/bulkDeactivate", teamHandler.BulkDeactivate)
+	usersRead := router.Group("/users", RequireScope(models.ScopePRRead))
+	usersRead.GET("/getReview", userHandler.GetReview)
+	usersWrite := router.Group("/users", RequireScope(models.ScopeTeamWrite))
+	usersWrite.POST("/setIsActive", userHandler.SetIsActive)
+	usersWrite.POST("/setSeniority", userHandler.SetSeniority)
+	usersWrite.POST("/setTags", userHandler.SetTags)
+	usersWrite.POST("/addTags", userHandler.AddTags)
+	usersWrite.POST("/removeTags", userHandler.RemoveTags)
 
-	users := router.Group("/users")
-	users.POST("/setIsActive", userHandler.SetIsActive)
-	users.GET("/getReview", userHandler.GetReview)
+	prRead := router.Group("/pullRequest", RequireScope(models.ScopePRRead))
+	prRead.POST("/suggestReviewers", prHandler.SuggestReviewers)
+	prRead.GET("/explain", prHandler.Explain)
+	prWrite := router.Group("/pullRequest", RequireScope(models.ScopePRWrite))
+	prWrite.POST("/create", prHandler.Create)
+	prWrite.POST("/update", prHandler.Update)
+	prWrite.POST("/merge", prHandler.Merge)
+	prWrite.POST("/reassign", prHandler.Reassign)
 
-	pr := router.Group("/pullRequest")
-	pr.POST("/create", prHandler.Create)
-	pr.POST("/merge", prHandler.Merge)
-	pr.POST("/reassign", prHandler.Reassign)
+	repositoryRead := router.Group("/repository", RequireScope(models.ScopeTeamRead))
+	repositoryRead.GET("/get", repositoryHandler.Get)
+	repositoryRead.GET("/list", repositoryHandler.List)
+	repositoryRead.GET("/codeowners", repositoryHandler.GetCodeowners)
+	repositoryWrite := router.Group("/repository", RequireScope(models.ScopeTeamWrite))
+	repositoryWrite.POST("/add", repositoryHandler.Add)
+	repositoryWrite.POST("/update", repositoryHandler.Update)
+	repositoryWrite.POST("/delete", repositoryHandler.Delete)
+	repositoryWrite.POST("/codeowners", repositoryHandler.UploadCodeowners)
 
-	stats := router.Group("/stats")
+	groupRead := router.Group("/reviewerGroup", RequireScope(models.ScopeTeamRead))
+	groupRead.GET("/get", groupHandler.Get)
+	groupRead.GET("/list", groupHandler.List)
+	groupWrite := router.Group("/reviewerGroup", RequireScope(models.ScopeTeamWrite))
+	groupWrite.POST("/add", groupHandler.Add)
+	groupWrite.POST("/setMembers", groupHandler.SetMembers)
+	groupWrite.POST("/delete", groupHandler.Delete)
+	groupWrite.POST("/addRule", groupHandler.AddRule)
+	groupWrite.POST("/deleteRule", groupHandler.DeleteRule)
+
+	stats := router.Group("/stats", RequireScope(models.ScopeStatsRead))
 	stats.GET("/assignments", statsHandler.GetAssignments)
+	stats.GET("/pairs", statsHandler.GetPairs)
+
+	jobs := router.Group("/jobs", RequireScope(models.ScopeTeamAdmin))
+	jobs.GET("/:id", jobHandler.Get)
+
+	tokens := router.Group("/auth/tokens", RequireScope(models.ScopeTokensAdmin))
+	tokens.POST("/issue", authHandler.IssueToken)
+	tokens.POST("/revoke", authHandler.RevokeToken)
+	tokens.GET("/list", authHandler.ListTokens)
+
+	audit := router.Group("/audit", RequireScope(models.ScopeAuditRead))
+	audit.GET("", auditHandler.List)
+	audit.GET("/export", auditHandler.Export)
 
+	orgs := router.Group("/organizations", RequireScope(models.ScopeOrgsAdmin))
+	orgs.POST("/add", organizationHandler.Add)
+	orgs.GET("/list", organizationHandler.List)
+
+	webhooks := router.Group("/webhooks", RequireScope(models.ScopeWebhooksAdmin))
+	webhooks.POST("/add", webhookHandler.Add)
+	webhooks.GET("/list", webhookHandler.List)
+	webhooks.POST("/delete", webhookHandler.Delete)
+	webhooks.GET("/deliveries", webhookHandler.Deliveries)
+	webhooks.POST("/redeliver", webhookHandler.Redeliver)
+
+	identities := router.Group("/integrations/identities", RequireScope(models.ScopeTeamAdmin))
+	identities.POST("/set", integrationHandler.SetIdentity)
+	identities.POST("/delete", integrationHandler.DeleteIdentity)
+	identities.GET("/list", integrationHandler.ListIdentities)
+
+	// Replaying assignment decisions is only exposed when explicitly enabled.
+	if debugRoutes {
+		debug := router.Group("/debug", RequireScope(models.ScopePRRead))
+		debug.GET("/assignmentEvents", prHandler.AssignmentEvents)
+		debug.POST("/replayAssignment", prHandler.ReplayAssignment)
+	}
+
+	return router
+}
+
+// newEngine returns an engine that trusts no proxy: gin would otherwise take
+// the client IP from any X-Forwarded-For, and every spoofed address would get
+// a fresh rate limit bucket. Deployments behind a proxy list it with
+// SetTrustedProxies.
+func newEngine() *gin.Engine {
+	router := gin.Default()
+	_ = router.SetTrustedProxies(nil)
 	return router
 }
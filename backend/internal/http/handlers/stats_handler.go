@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -24,3 +26,23 @@ func (h *StatsHandler) GetAssignments(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+func (h *StatsHandler) GetPairs(c *gin.Context) {
+	var windowDays int
+	if raw := c.Query("window_days"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			RenderError(c, errors.New(errors.CodeValidation, "window_days must be a positive integer"))
+			return
+		}
+		windowDays = v
+	}
+
+	stats, err := h.service.GetPairMatrix(c.Request.Context(), windowDays)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -29,6 +29,7 @@ func (h *TeamHandler) AddTeam(c *gin.Context) {
 		RenderError(c, err)
 		return
 	}
+	setETag(c, resp.Team.Version)
 	c.JSON(http.StatusCreated, resp)
 }
 
@@ -43,6 +44,7 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 		RenderError(c, err)
 		return
 	}
+	setETag(c, out.Version)
 	c.JSON(http.StatusOK, dtos.TeamResponse{Team: out})
 }
 
@@ -53,6 +55,16 @@ func (h *TeamHandler) BulkDeactivate(c *gin.Context) {
 		return
 	}
 
+	if c.Query("async") == "true" {
+		job, err := h.svc.BulkDeactivateAsync(c.Request.Context(), req)
+		if err != nil {
+			RenderError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
 	resp, err := h.svc.BulkDeactivate(c.Request.Context(), req)
 	if err != nil {
 		RenderError(c, err)
@@ -61,3 +73,58 @@ func (h *TeamHandler) BulkDeactivate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+func (h *TeamHandler) UndoBulkDeactivate(c *gin.Context) {
+	var req dtos.UndoBulkDeactivateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.UndoBulkDeactivate(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *TeamHandler) SetReviewPolicy(c *gin.Context) {
+	var req dtos.SetReviewPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+	expected, err := ifMatch(c)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+	req.IfMatch = expected
+
+	resp, err := h.svc.SetReviewPolicy(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	setETag(c, resp.Team.Version)
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *TeamHandler) SetRole(c *gin.Context) {
+	var req dtos.SetTeamRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.SetRole(c.Request.Context(), req)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
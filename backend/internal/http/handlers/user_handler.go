@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
@@ -43,3 +44,50 @@ func (h *UserHandler) GetReview(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+func (h *UserHandler) SetSeniority(c *gin.Context) {
+	var in dtos.SetSeniorityRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := h.svc.SetSeniority(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *UserHandler) SetTags(c *gin.Context) {
+	h.updateTags(c, h.svc.SetTags)
+}
+
+func (h *UserHandler) AddTags(c *gin.Context) {
+	h.updateTags(c, h.svc.AddTags)
+}
+
+func (h *UserHandler) RemoveTags(c *gin.Context) {
+	h.updateTags(c, h.svc.RemoveTags)
+}
+
+func (h *UserHandler) updateTags(
+	c *gin.Context,
+	update func(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error),
+) {
+	var in dtos.UserTagsRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		RenderError(c, errors.New(errors.CodeValidation, "invalid request"))
+		return
+	}
+
+	resp, err := update(c.Request.Context(), in)
+	if err != nil {
+		RenderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+const (
+	ScopePRRead        = "pr:read"
+	ScopePRWrite       = "pr:write"
+	ScopeTeamRead      = "team:read"
+	ScopeTeamWrite     = "team:write"
+	ScopeTeamAdmin     = "team:admin"
+	ScopeStatsRead     = "stats:read"
+	ScopeTokensAdmin   = "tokens:admin"
+	ScopeAuditRead     = "audit:read"
+	ScopeWebhooksAdmin = "webhooks:admin"
+)
+
+// Scopes lists every scope a token can be issued with.
+var Scopes = []string{
+	ScopePRRead, ScopePRWrite,
+	ScopeTeamRead, ScopeTeamWrite, ScopeTeamAdmin,
+	ScopeStatsRead, ScopeTokensAdmin, ScopeAuditRead, ScopeWebhooksAdmin,
+}
+
+// ScopeOrgsAdmin manages organizations. It is not in Scopes: only the
+// bootstrap token, which may act in any organization, holds it.
+const ScopeOrgsAdmin = "orgs:admin"
+
+// scopeImplies lists the narrower scopes granted along with a scope.
+var scopeImplies = map[string][]string{
+	ScopePRWrite:   {ScopePRRead},
+	ScopeTeamWrite: {ScopeTeamRead},
+	ScopeTeamAdmin: {ScopeTeamWrite, ScopeTeamRead},
+}
+
+const (
+	RoleViewer    = "viewer"
+	RoleDeveloper = "developer"
+	RoleLead      = "lead"
+	RoleAdmin     = "admin"
+)
+
+// RoleScopes maps the roles that identity-provider groups can be mapped to
+// onto the scopes they grant.
+var RoleScopes = map[string][]string{
+	RoleViewer:    {ScopePRRead, ScopeTeamRead, ScopeStatsRead},
+	RoleDeveloper: {ScopePRWrite, ScopeTeamRead, ScopeStatsRead},
+	RoleLead:      {ScopePRWrite, ScopeTeamAdmin, ScopeStatsRead},
+	RoleAdmin:     Scopes,
+}
+
+type APIToken struct {
+	ID             int64
+	UserID         int64
+	ExtUserID      string
+	OrganizationID int64
+	Name           string
+	Scopes         []string
+	CreatedAt      time.Time
+	RevokedAt      *time.Time
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	TokenID int64
+	UserID  int64
+	// ExtUserID is empty for the bootstrap token, which is not tied to a user.
+	ExtUserID      string
+	OrganizationID int64
+	Scopes         []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+		for _, implied := range scopeImplies[s] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
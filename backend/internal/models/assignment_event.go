@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+const (
+	AssignmentCreate       = "CREATE"
+	AssignmentReassign     = "REASSIGN"
+	AssignmentBulkReassign = "BULK_REASSIGN"
+)
+
+// AssignmentEvent records a reviewer assignment decision together with the
+// seed and the candidate snapshot it was made from.
+type AssignmentEvent struct {
+	ID            int64
+	PRID          int64
+	PullRequestID string
+	Kind          string
+	Seed          int64
+	Snapshot      []byte
+	Reviewers     []string
+	Explanations  []AssignmentExplanation
+	CreatedAt     time.Time
+}
+
+// Rules that choose the winner of a reviewer slot.
+const (
+	RuleRequiredGroup   = "REQUIRED_GROUP"
+	RuleCodeOwner       = "CODEOWNER"
+	RuleSeniorityPolicy = "SENIORITY_POLICY"
+	RuleRepositoryPool  = "REPOSITORY_POOL"
+	RuleOwnerTeams      = "OWNER_TEAMS"
+	RuleTeam            = "TEAM"
+	RuleRoundRobin      = "ROUND_ROBIN"
+)
+
+// Reasons a candidate was not eligible for a slot.
+const (
+	ExcludedInactive          = "INACTIVE"
+	ExcludedAuthor            = "AUTHOR"
+	ExcludedAlreadyAssigned   = "ALREADY_ASSIGNED"
+	ExcludedReplaced          = "REPLACED"
+	ExcludedDeactivated       = "DEACTIVATED"
+	ExcludedSeniorityMismatch = "SENIORITY_MISMATCH"
+	ExcludedOverCapacity      = "OVER_CAPACITY"
+)
+
+type ExcludedCandidate struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// AssignmentExplanation tells why a reviewer ended up in a slot: who was
+// considered, who was excluded and which rule picked the winner.
+type AssignmentExplanation struct {
+	ID             int64
+	EventID        int64
+	PRID           int64
+	Slot           int
+	ReviewerUserID string
+	Rule           string
+	Detail         string
+	Considered     []string
+	Excluded       []ExcludedCandidate
+	CreatedAt      time.Time
+}
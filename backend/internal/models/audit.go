@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+const (
+	AuditTeamCreate          = "team.create"
+	AuditTeamSetReviewPolicy = "team.set_review_policy"
+	AuditTeamSetRole         = "team.set_role"
+	AuditUserSetActive       = "user.set_active"
+	AuditUserSetSeniority    = "user.set_seniority"
+	AuditUserSetTags         = "user.set_tags"
+	AuditUserAddTags         = "user.add_tags"
+	AuditUserRemoveTags      = "user.remove_tags"
+	AuditPRCreate            = "pr.create"
+	AuditPRUpdate            = "pr.update"
+	AuditPRMerge             = "pr.merge"
+	AuditPRReassign          = "pr.reassign"
+	AuditBulkDeactivate      = "team.bulk_deactivate"
+	AuditBulkDeactivateUndo  = "team.bulk_deactivate_undo"
+
+	AuditRepositoryCreate        = "repository.create"
+	AuditRepositoryUpdate        = "repository.update"
+	AuditRepositoryDelete        = "repository.delete"
+	AuditRepositorySetCodeowners = "repository.set_codeowners"
+	AuditGroupCreate             = "group.create"
+	AuditGroupSetMembers         = "group.set_members"
+	AuditGroupDelete             = "group.delete"
+	AuditGroupAddRule            = "group.add_rule"
+	AuditGroupDeleteRule         = "group.delete_rule"
+	AuditTokenIssue              = "token.issue"
+	AuditTokenRevoke             = "token.revoke"
+	AuditWebhookAdd              = "webhook.add"
+	AuditWebhookDelete           = "webhook.delete"
+	AuditWebhookRedeliver        = "webhook.redeliver"
+	AuditIdentitySet             = "identity.set"
+	AuditIdentityDelete          = "identity.delete"
+)
+
+const (
+	AuditEntityTeam        = "team"
+	AuditEntityUser        = "user"
+	AuditEntityPullRequest = "pull_request"
+	AuditEntityRepository  = "repository"
+	AuditEntityGroup       = "reviewer_group"
+	AuditEntityGroupRule   = "group_rule"
+	AuditEntityToken       = "api_token"
+	AuditEntityWebhook     = "webhook_subscription"
+	AuditEntityDelivery    = "webhook_delivery"
+	AuditEntityIdentity    = "identity"
+)
+
+const (
+	ActorBootstrap = "bootstrap"
+	ActorSystem    = "system"
+)
+
+// AuditEntry records one mutation. Before and After hold JSON snapshots of
+// the changed state and are nil when there is nothing to show.
+type AuditEntry struct {
+	ID         int64
+	Actor      string
+	TokenID    *int64
+	RequestID  string
+	Action     string
+	EntityType string
+	EntityID   string
+	Before     []byte
+	After      []byte
+	CreatedAt  time.Time
+}
+
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	Since      *time.Time
+	Until      *time.Time
+	// AfterID pages through the log in id order.
+	AfterID int64
+	Limit   int
+}
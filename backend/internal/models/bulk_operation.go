@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// BulkOperation records what a bulk deactivation changed so it can be undone.
+type BulkOperation struct {
+	ID           int64
+	TeamID       int64
+	CreatedAt    time.Time
+	UndoneAt     *time.Time
+	Users        []BulkUserFlip
+	Replacements []BulkReplacement
+}
+
+type BulkUserFlip struct {
+	UserID    int64
+	ExtUserID string
+	WasActive bool
+}
+
+// BulkReplacement is a reviewer swap made by a bulk operation. The Current*
+// fields describe the PR at the time the operation is loaded.
+type BulkReplacement struct {
+	PRID          int64
+	PullRequestID string
+	Slot          int
+	OldReviewerID int64
+	NewReviewerID int64
+	OldUserID     string
+	NewUserID     string
+
+	PRStatus          string
+	CurrentReviewerID *int64
+	OldReviewerOnPR   bool
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// ExternalIdentity maps an account of a code hosting system to a user.
+type ExternalIdentity struct {
+	Provider  string
+	Username  string
+	UserID    string
+	CreatedAt time.Time
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+const (
+	JobPending   = "PENDING"
+	JobRunning   = "RUNNING"
+	JobSucceeded = "SUCCEEDED"
+	JobFailed    = "FAILED"
+)
+
+const JobBulkDeactivate = "BULK_DEACTIVATE"
+
+// Job is a persisted background operation. Payload, Result and Items hold JSON.
+type Job struct {
+	ID             int64
+	OrganizationID int64
+	Kind           string
+	Status         string
+	Payload        []byte
+	Result         []byte
+	Error          string
+	Done           int
+	Total          int
+	Items          []byte
+	CreatedAt      time.Time
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+}
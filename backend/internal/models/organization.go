@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DefaultOrganizationID is the organization pre-existing data was moved to.
+// Calls that carry no organization are scoped to it.
+const (
+	DefaultOrganizationID   int64 = 1
+	DefaultOrganizationSlug       = "default"
+)
+
+type Organization struct {
+	ID        int64
+	Slug      string
+	Name      string
+	CreatedAt time.Time
+}
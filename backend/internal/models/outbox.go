@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event waiting to be published. Events sharing an
+// OrderingKey are published in ID order.
+type OutboxEvent struct {
+	ID             int64
+	OrganizationID int64
+	Event          string
+	OrderingKey    string
+	Payload        []byte
+	Attempts       int
+	CreatedAt      time.Time
+}
@@ -11,10 +11,25 @@ type PullRequest struct {
 	ID            int64      `db:"id"`
 	PullRequestID string     `db:"pr_id"` // Внешний идентификатор pull request
 	Title         string     `db:"title"`
+	Repository    string     `db:"repository"`
+	SourceBranch  string     `db:"source_branch"`
+	TargetBranch  string     `db:"target_branch"`
+	Description   string     `db:"description"`
+	URL           string     `db:"url"`
+	Labels        []string   `db:"labels"`
+	ChangedFiles  []string   `db:"changed_files"`
+	RequiredTags  []string   `db:"required_tags"`
 	AuthorUserID  int64      `db:"author_id"`
 	Status        string     `db:"status"`
 	CreatedAt     time.Time  `db:"created_at"`
 	UpdatedAt     *time.Time `db:"updated_at"`
 	DeletedAt     time.Time  `db:"deleted_at"`
+	Version       int64      `db:"version"`
 	Reviewers     []string
 }
+
+type ReviewAssignment struct {
+	ReviewerID      int64  `db:"reviewer_id"`
+	Slot            int    `db:"slot"`
+	RequiredGroupID *int64 `db:"required_group_id"`
+}
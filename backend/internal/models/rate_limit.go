@@ -0,0 +1,31 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket: up to Burst requests at once, refilled at Rate
+// requests per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// Take refills a bucket that held tokens at last and takes one token from it.
+// It returns the tokens left and, when the bucket was empty, how long until
+// the next token arrives.
+func (l RateLimit) Take(tokens float64, last, now time.Time) (float64, time.Duration, bool) {
+	elapsed := math.Max(0, now.Sub(last).Seconds())
+	tokens = math.Min(float64(l.Burst), tokens+elapsed*l.Rate)
+	if tokens >= 1 {
+		return tokens - 1, 0, true
+	}
+	return tokens, time.Duration((1 - tokens) / l.Rate * float64(time.Second)), false
+}
+
+// FullAfter is how long an empty bucket takes to refill completely; idle
+// buckets older than that can be forgotten.
+func (l RateLimit) FullAfter() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
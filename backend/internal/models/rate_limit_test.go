@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitTake(t *testing.T) {
+	limit := RateLimit{Rate: 2, Burst: 3}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		left    float64
+		wait    time.Duration
+		allowed bool
+	}{
+		{"full bucket", 3, 0, 2, 0, true},
+		{"last token", 1, 0, 0, 0, true},
+		{"empty bucket", 0, 0, 0, 500 * time.Millisecond, false},
+		{"half a token", 0, 250 * time.Millisecond, 0.5, 250 * time.Millisecond, false},
+		{"refilled", 0, time.Second, 1, 0, true},
+		{"refill capped at burst", 0, time.Hour, 2, 0, true},
+		{"clock going back", 0.5, -time.Second, 0.5, 250 * time.Millisecond, false},
+	}
+	for _, tc := range cases {
+		left, wait, allowed := limit.Take(tc.tokens, start, start.Add(tc.elapsed))
+		if left != tc.left || wait != tc.wait || allowed != tc.allowed {
+			t.Errorf("%s: Take = %v, %v, %v; want %v, %v, %v",
+				tc.name, left, wait, allowed, tc.left, tc.wait, tc.allowed)
+		}
+	}
+}
+
+func TestRateLimitFullAfter(t *testing.T) {
+	cases := map[RateLimit]time.Duration{
+		{Rate: 1, Burst: 1}:           time.Second,
+		{Rate: 2, Burst: 10}:          5 * time.Second,
+		{Rate: 60.0 / 3600, Burst: 5}: 5 * time.Minute,
+	}
+	for limit, want := range cases {
+		if got := limit.FullAfter(); got != want {
+			t.Errorf("%+v.FullAfter() = %v, want %v", limit, got, want)
+		}
+	}
+}
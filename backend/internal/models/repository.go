@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Repository struct {
+	ID         int64     `db:"id"`
+	Name       string    `db:"name"` // Полное имя репозитория, например org/service
+	URL        string    `db:"url"`
+	Codeowners string    `db:"codeowners"`
+	CreatedAt  time.Time `db:"created_at"`
+	Deleted    *time.Time
+	OwnerTeams []string
+	Reviewers  []string
+}
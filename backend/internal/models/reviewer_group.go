@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type ReviewerGroup struct {
+	ID          int64     `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	Deleted     *time.Time
+	Members     []string
+	Rules       []GroupRule
+}
+
+type GroupRule struct {
+	ID           int64  `db:"id"`
+	GroupID      int64  `db:"group_id"`
+	GroupName    string `db:"group_name"`
+	Label        string `db:"label"`
+	TitlePattern string `db:"title_pattern"`
+}
@@ -2,8 +2,16 @@ package models
 
 import "time"
 
+const (
+	PolicyNone           = "NONE"
+	PolicySeniorRequired = "SENIOR_REQUIRED"
+	PolicyMentorPair     = "MENTOR_PAIR"
+)
+
 type Team struct {
-	ID      int64
-	Name    string
-	Deleted *time.Time
+	ID           int64
+	Name         string
+	ReviewPolicy string
+	Version      int64
+	Deleted      *time.Time
 }
@@ -0,0 +1,15 @@
+package models
+
+const (
+	TeamRoleMember = "MEMBER"
+	TeamRoleLead   = "LEAD"
+	TeamRoleAdmin  = "ADMIN"
+)
+
+// TeamRoleRank orders team roles; each role includes the rights of the
+// lower ones.
+var TeamRoleRank = map[string]int{
+	TeamRoleMember: 1,
+	TeamRoleLead:   2,
+	TeamRoleAdmin:  3,
+}
@@ -2,11 +2,19 @@ package models
 
 import "time"
 
+const (
+	SeniorityJunior = "JUNIOR"
+	SeniorityMiddle = "MIDDLE"
+	SenioritySenior = "SENIOR"
+)
+
 type User struct {
-	ID       int64  `db:"id"`
-	UserID   string `db:"user_id"` // Внешний идентификатор пользователя
-	Name     string `db:"username"`
-	TeamID   int64  `db:"team_id"`
-	IsActive bool   `db:"is_active"`
-	Deleted  *time.Time
+	ID        int64    `db:"id"`
+	UserID    string   `db:"user_id"` // Внешний идентификатор пользователя
+	Name      string   `db:"username"`
+	TeamID    int64    `db:"team_id"`
+	IsActive  bool     `db:"is_active"`
+	Tags      []string `db:"tags"`
+	Seniority string   `db:"seniority"`
+	Deleted   *time.Time
 }
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+const (
+	EventPRCreated          = "pr.created"
+	EventPRUpdated          = "pr.updated"
+	EventPRMerged           = "pr.merged"
+	EventReviewerAssigned   = "reviewer.assigned"
+	EventReviewerReassigned = "reviewer.reassigned"
+	EventUserDeactivated    = "user.deactivated"
+	EventUserActivated      = "user.activated"
+)
+
+// WebhookEvents lists every event a subscription can receive.
+var WebhookEvents = []string{
+	EventPRCreated, EventPRUpdated, EventPRMerged,
+	EventReviewerAssigned, EventReviewerReassigned,
+	EventUserDeactivated, EventUserActivated,
+}
+
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryDead      = "DEAD"
+)
+
+type WebhookSubscription struct {
+	ID        int64
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is one event queued for one subscription. URL and Secret
+// are filled in only when the delivery is claimed for sending.
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	ResponseStatus *int
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+
+	URL    string
+	Secret string
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionID int64
+	Status         string
+	AfterID        int64
+	Limit          int
+}
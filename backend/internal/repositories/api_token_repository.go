@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token models.APIToken, hash string) (models.APIToken, error)
+	GetActiveByHash(ctx context.Context, hash string) (models.APIToken, error)
+	List(ctx context.Context) ([]models.APIToken, error)
+	Revoke(ctx context.Context, id int64) error
+}
+
+type pgAPITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPITokenRepository(pool *pgxpool.Pool) APITokenRepository {
+	return &pgAPITokenRepository{pool: pool}
+}
+
+const apiTokenColumns = `t.id, t.user_id, u.user_id, u.organization_id, t.name, t.scopes, t.created_at, t.revoked_at`
+
+func scanAPIToken(row pgx.Row) (models.APIToken, error) {
+	var t models.APIToken
+	err := row.Scan(&t.ID, &t.UserID, &t.ExtUserID, &t.OrganizationID, &t.Name, &t.Scopes, &t.CreatedAt, &t.RevokedAt)
+	return t, err
+}
+
+func (r *pgAPITokenRepository) Create(ctx context.Context, token models.APIToken, hash string) (models.APIToken, error) {
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO api_tokens (user_id, name, token_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, token.UserID, token.Name, hash, token.Scopes).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return models.APIToken{}, fmt.Errorf("create api token: %w", err)
+	}
+	return token, nil
+}
+
+// GetActiveByHash looks the token up in every organization: the token is what
+// tells which organization the caller belongs to.
+func (r *pgAPITokenRepository) GetActiveByHash(ctx context.Context, hash string) (models.APIToken, error) {
+	t, err := scanAPIToken(conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT `+apiTokenColumns+`
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND u.deleted_at IS NULL
+	`, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.APIToken{}, ErrNotFound
+		}
+		return models.APIToken{}, fmt.Errorf("get api token: %w", err)
+	}
+	return t, nil
+}
+
+func (r *pgAPITokenRepository) List(ctx context.Context) ([]models.APIToken, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT `+apiTokenColumns+`
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE u.organization_id = $1
+		ORDER BY t.id
+	`, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []models.APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *pgAPITokenRepository) Revoke(ctx context.Context, id int64) error {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE api_tokens t
+		SET revoked_at = NOW()
+		FROM users u
+		WHERE t.id = $1 AND t.revoked_at IS NULL AND u.id = t.user_id AND u.organization_id = $2
+	`, id, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("revoke api token: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type AssignmentEventRepository interface {
+	Create(ctx context.Context, event models.AssignmentEvent) (models.AssignmentEvent, error)
+	GetByID(ctx context.Context, id int64) (models.AssignmentEvent, error)
+	ListByPullRequestID(ctx context.Context, prID string) ([]models.AssignmentEvent, error)
+	ListExplanations(ctx context.Context, prInternalID int64) ([]models.AssignmentExplanation, error)
+}
+
+type pgAssignmentEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAssignmentEventRepository(pool *pgxpool.Pool) AssignmentEventRepository {
+	return &pgAssignmentEventRepository{pool: pool}
+}
+
+func (r *pgAssignmentEventRepository) Create(
+	ctx context.Context,
+	event models.AssignmentEvent) (models.AssignmentEvent, error) {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return models.AssignmentEvent{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+		INSERT INTO assignment_events (pr_id, kind, seed, snapshot, reviewers)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	if event.Reviewers == nil {
+		event.Reviewers = []string{}
+	}
+	err = tx.QueryRow(ctx, q, event.PRID, event.Kind, event.Seed, event.Snapshot, event.Reviewers).
+		Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return models.AssignmentEvent{}, fmt.Errorf("create assignment event: %w", err)
+	}
+
+	for i := range event.Explanations {
+		e := &event.Explanations[i]
+		e.EventID = event.ID
+		e.PRID = event.PRID
+		if err := insertExplanation(ctx, tx, e); err != nil {
+			return models.AssignmentEvent{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.AssignmentEvent{}, fmt.Errorf("commit: %w", err)
+	}
+	return event, nil
+}
+
+func (r *pgAssignmentEventRepository) GetByID(ctx context.Context, id int64) (models.AssignmentEvent, error) {
+	const q = `
+		SELECT e.id, e.pr_id, pr.pr_id, e.kind, e.seed, e.snapshot, e.reviewers, e.created_at
+		FROM assignment_events e
+		JOIN pull_requests pr ON pr.id = e.pr_id
+		WHERE e.id = $1 AND pr.organization_id = $2
+	`
+	var e models.AssignmentEvent
+	err := conn(ctx, r.pool).QueryRow(ctx, q, id, orgID(ctx)).
+		Scan(&e.ID, &e.PRID, &e.PullRequestID, &e.Kind, &e.Seed, &e.Snapshot, &e.Reviewers, &e.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.AssignmentEvent{}, ErrNotFound
+		}
+		return models.AssignmentEvent{}, fmt.Errorf("get assignment event: %w", err)
+	}
+	return e, nil
+}
+
+func (r *pgAssignmentEventRepository) ListByPullRequestID(
+	ctx context.Context,
+	prID string) ([]models.AssignmentEvent, error) {
+	const q = `
+		SELECT e.id, e.pr_id, pr.pr_id, e.kind, e.seed, e.snapshot, e.reviewers, e.created_at
+		FROM assignment_events e
+		JOIN pull_requests pr ON pr.id = e.pr_id
+		WHERE pr.pr_id = $1 AND pr.organization_id = $2 AND pr.deleted_at IS NULL
+		ORDER BY e.created_at, e.id
+	`
+	rows, err := conn(ctx, r.pool).Query(ctx, q, prID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list assignment events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AssignmentEvent{}
+	for rows.Next() {
+		var e models.AssignmentEvent
+		if err := rows.Scan(&e.ID, &e.PRID, &e.PullRequestID, &e.Kind, &e.Seed, &e.Snapshot, &e.Reviewers, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan assignment event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *pgAssignmentEventRepository) ListExplanations(
+	ctx context.Context,
+	prInternalID int64) ([]models.AssignmentExplanation, error) {
+	const q = `
+		SELECT e.id, e.event_id, e.pr_id, e.slot, e.reviewer_id, e.rule, e.detail, e.considered, e.excluded,
+		       e.created_at
+		FROM assignment_explanations e
+		JOIN pull_requests pr ON pr.id = e.pr_id
+		WHERE e.pr_id = $1 AND pr.organization_id = $2
+		ORDER BY e.created_at DESC, e.id DESC
+	`
+	rows, err := conn(ctx, r.pool).Query(ctx, q, prInternalID, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list assignment explanations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.AssignmentExplanation{}
+	for rows.Next() {
+		var e models.AssignmentExplanation
+		var excluded []byte
+		if err := rows.Scan(&e.ID, &e.EventID, &e.PRID, &e.Slot, &e.ReviewerUserID, &e.Rule, &e.Detail,
+			&e.Considered, &excluded, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan assignment explanation: %w", err)
+		}
+		if err := json.Unmarshal(excluded, &e.Excluded); err != nil {
+			return nil, fmt.Errorf("decode excluded candidates: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func insertExplanation(ctx context.Context, tx pgx.Tx, e *models.AssignmentExplanation) error {
+	if e.Considered == nil {
+		e.Considered = []string{}
+	}
+	if e.Excluded == nil {
+		e.Excluded = []models.ExcludedCandidate{}
+	}
+	excluded, err := json.Marshal(e.Excluded)
+	if err != nil {
+		return fmt.Errorf("encode excluded candidates: %w", err)
+	}
+
+	const q = `
+		INSERT INTO assignment_explanations
+			(event_id, pr_id, slot, reviewer_id, rule, detail, considered, excluded)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, q, e.EventID, e.PRID, e.Slot, e.ReviewerUserID, e.Rule, e.Detail, e.Considered, excluded).
+		Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create assignment explanation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type AuditRepository interface {
+	Insert(ctx context.Context, e models.AuditEntry) error
+	List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+type pgAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) AuditRepository {
+	return &pgAuditRepository{pool: pool}
+}
+
+func (r *pgAuditRepository) Insert(ctx context.Context, e models.AuditEntry) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO audit_log
+			(organization_id, actor, token_id, request_id, action, entity_type, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, orgID(ctx), e.Actor, e.TokenID, e.RequestID, e.Action, e.EntityType, e.EntityID, e.Before, e.After)
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+	return nil
+}
+
+func (r *pgAuditRepository) List(ctx context.Context, f models.AuditFilter) ([]models.AuditEntry, error) {
+	conds := []string{"organization_id = $1", "id > $2"}
+	args := []any{orgID(ctx), f.AfterID}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if f.RequestID != "" {
+		add("request_id = $%d", f.RequestID)
+	}
+	if f.Since != nil {
+		add("created_at >= $%d", *f.Since)
+	}
+	if f.Until != nil {
+		add("created_at < $%d", *f.Until)
+	}
+	args = append(args, f.Limit)
+
+	q := fmt.Sprintf(`
+		SELECT id, actor, token_id, request_id, action, entity_type, entity_id, before, after, created_at
+		FROM audit_log
+		WHERE %s
+		ORDER BY id
+		LIMIT $%d
+	`, strings.Join(conds, " AND "), len(args))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Actor, &e.TokenID, &e.RequestID, &e.Action, &e.EntityType, &e.EntityID,
+			&e.Before, &e.After, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
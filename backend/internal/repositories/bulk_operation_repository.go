@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type BulkOperationRepository interface {
+	Create(ctx context.Context, op models.BulkOperation) (models.BulkOperation, error)
+	GetByID(ctx context.Context, id int64) (models.BulkOperation, error)
+	MarkUndone(ctx context.Context, id int64) error
+}
+
+type pgBulkOperationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBulkOperationRepository(pool *pgxpool.Pool) BulkOperationRepository {
+	return &pgBulkOperationRepository{pool: pool}
+}
+
+func (r *pgBulkOperationRepository) Create(ctx context.Context, op models.BulkOperation) (models.BulkOperation, error) {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return models.BulkOperation{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bulk_operations (team_id)
+		VALUES ($1)
+		RETURNING id, created_at
+	`, op.TeamID).Scan(&op.ID, &op.CreatedAt)
+	if err != nil {
+		return models.BulkOperation{}, fmt.Errorf("create bulk operation: %w", err)
+	}
+
+	for _, u := range op.Users {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO bulk_operation_users (operation_id, user_id, was_active)
+			VALUES ($1, $2, $3)
+		`, op.ID, u.UserID, u.WasActive)
+		if err != nil {
+			return models.BulkOperation{}, fmt.Errorf("record user flip: %w", err)
+		}
+	}
+	for _, rp := range op.Replacements {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO bulk_operation_replacements (operation_id, pr_id, slot, old_reviewer_id, new_reviewer_id)
+			VALUES ($1, $2, $3, $4, $5)
+		`, op.ID, rp.PRID, rp.Slot, rp.OldReviewerID, rp.NewReviewerID)
+		if err != nil {
+			return models.BulkOperation{}, fmt.Errorf("record replacement: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.BulkOperation{}, fmt.Errorf("commit: %w", err)
+	}
+	return op, nil
+}
+
+// GetByID loads the operation with its user flips and replacements, each
+// replacement annotated with the current state of its PR slot.
+func (r *pgBulkOperationRepository) GetByID(ctx context.Context, id int64) (models.BulkOperation, error) {
+	op := models.BulkOperation{ID: id}
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT op.team_id, op.created_at, op.undone_at
+		FROM bulk_operations op
+		JOIN teams t ON t.id = op.team_id
+		WHERE op.id = $1 AND t.organization_id = $2
+	`, id, orgID(ctx)).Scan(&op.TeamID, &op.CreatedAt, &op.UndoneAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BulkOperation{}, ErrNotFound
+		}
+		return models.BulkOperation{}, fmt.Errorf("get bulk operation: %w", err)
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT bu.user_id, u.user_id, bu.was_active
+		FROM bulk_operation_users bu
+		JOIN users u ON u.id = bu.user_id
+		WHERE bu.operation_id = $1
+		ORDER BY u.user_id
+	`, id)
+	if err != nil {
+		return models.BulkOperation{}, fmt.Errorf("get user flips: %w", err)
+	}
+	for rows.Next() {
+		var f models.BulkUserFlip
+		if err := rows.Scan(&f.UserID, &f.ExtUserID, &f.WasActive); err != nil {
+			rows.Close()
+			return models.BulkOperation{}, fmt.Errorf("scan user flip: %w", err)
+		}
+		op.Users = append(op.Users, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return models.BulkOperation{}, err
+	}
+
+	rows, err = conn(ctx, r.pool).Query(ctx, `
+		SELECT br.pr_id, pr.pr_id, br.slot, br.old_reviewer_id, br.new_reviewer_id,
+		       ou.user_id, nu.user_id, pr.status::text, cur.reviewer_id,
+		       EXISTS (SELECT 1 FROM pr_reviews o WHERE o.pr_id = br.pr_id AND o.reviewer_id = br.old_reviewer_id)
+		FROM bulk_operation_replacements br
+		JOIN pull_requests pr ON pr.id = br.pr_id
+		JOIN users ou ON ou.id = br.old_reviewer_id
+		JOIN users nu ON nu.id = br.new_reviewer_id
+		LEFT JOIN pr_reviews cur ON cur.pr_id = br.pr_id AND cur.slot = br.slot
+		WHERE br.operation_id = $1
+		ORDER BY pr.pr_id, br.slot
+	`, id)
+	if err != nil {
+		return models.BulkOperation{}, fmt.Errorf("get replacements: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rp models.BulkReplacement
+		if err := rows.Scan(&rp.PRID, &rp.PullRequestID, &rp.Slot, &rp.OldReviewerID, &rp.NewReviewerID,
+			&rp.OldUserID, &rp.NewUserID, &rp.PRStatus, &rp.CurrentReviewerID, &rp.OldReviewerOnPR); err != nil {
+			return models.BulkOperation{}, fmt.Errorf("scan replacement: %w", err)
+		}
+		op.Replacements = append(op.Replacements, rp)
+	}
+	return op, rows.Err()
+}
+
+func (r *pgBulkOperationRepository) MarkUndone(ctx context.Context, id int64) error {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE bulk_operations op
+		SET undone_at = NOW()
+		FROM teams t
+		WHERE op.id = $1 AND op.undone_at IS NULL AND t.id = op.team_id AND t.organization_id = $2
+	`, id, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("mark bulk operation undone: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrAlreadyUndone
+	}
+	return nil
+}
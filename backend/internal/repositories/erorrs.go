@@ -3,10 +3,20 @@ package repositories
 import "errors"
 
 var (
-	ErrTeamExists   = errors.New("team exists")
-	ErrUserExists   = errors.New("user exists")
-	ErrPRExists     = errors.New("PR exists")
-	ErrUserInactive = errors.New("user is inactive")
+	ErrTeamExists    = errors.New("team exists")
+	ErrUserExists    = errors.New("user exists")
+	ErrPRExists      = errors.New("PR exists")
+	ErrRepoExists    = errors.New("repository exists")
+	ErrGroupExists   = errors.New("reviewer group exists")
+	ErrOrgExists     = errors.New("organization exists")
+	ErrUserInactive  = errors.New("user is inactive")
+	ErrAlreadyUndone = errors.New("operation already undone")
+
+	// ErrConflict reports a write that lost a race with a concurrent
+	// transaction; the whole operation can be retried.
+	ErrConflict = errors.New("concurrent modification")
+
+	ErrVersionMismatch = errors.New("version mismatch")
 
 	ErrNotFound = errors.New("not found")
 )
@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type GroupRepository interface {
+	Create(ctx context.Context, group models.ReviewerGroup) (models.ReviewerGroup, error)
+	GetByName(ctx context.Context, name string) (models.ReviewerGroup, error)
+	List(ctx context.Context) ([]models.ReviewerGroup, error)
+	SetMembers(ctx context.Context, name string, userIDs []string) error
+	Delete(ctx context.Context, name string) error
+	AddRule(ctx context.Context, groupName string, rule models.GroupRule) (models.GroupRule, error)
+	DeleteRule(ctx context.Context, ruleID int64) error
+	ListRules(ctx context.Context) ([]models.GroupRule, error)
+	GetMembers(ctx context.Context, groupID int64) ([]models.User, error)
+}
+
+type pgGroupRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGroupRepository(pool *pgxpool.Pool) GroupRepository {
+	return &pgGroupRepository{pool: pool}
+}
+
+func (r *pgGroupRepository) Create(ctx context.Context, group models.ReviewerGroup) (models.ReviewerGroup, error) {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return models.ReviewerGroup{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+		INSERT INTO reviewer_groups (organization_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, q, orgID(ctx), group.Name, group.Description).Scan(&group.ID, &group.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.ReviewerGroup{}, ErrGroupExists
+		}
+		return models.ReviewerGroup{}, fmt.Errorf("create reviewer group: %w", err)
+	}
+
+	if err := replaceGroupMembers(ctx, tx, group.ID, group.Members); err != nil {
+		return models.ReviewerGroup{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.ReviewerGroup{}, fmt.Errorf("commit: %w", err)
+	}
+	return group, nil
+}
+
+func (r *pgGroupRepository) GetByName(ctx context.Context, name string) (models.ReviewerGroup, error) {
+	const q = `
+		SELECT id, name, description, created_at
+		FROM reviewer_groups
+		WHERE name = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`
+	var g models.ReviewerGroup
+	err := conn(ctx, r.pool).QueryRow(ctx, q, name, orgID(ctx)).Scan(&g.ID, &g.Name, &g.Description, &g.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ReviewerGroup{}, ErrNotFound
+		}
+		return models.ReviewerGroup{}, fmt.Errorf("get reviewer group: %w", err)
+	}
+
+	if err := r.loadRelations(ctx, &g); err != nil {
+		return models.ReviewerGroup{}, err
+	}
+	return g, nil
+}
+
+func (r *pgGroupRepository) List(ctx context.Context) ([]models.ReviewerGroup, error) {
+	const q = `
+		SELECT id, name, description, created_at
+		FROM reviewer_groups
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		ORDER BY name
+	`
+	rows, err := conn(ctx, r.pool).Query(ctx, q, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list reviewer groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []models.ReviewerGroup
+	for rows.Next() {
+		var g models.ReviewerGroup
+		if err := rows.Scan(&g.ID, &g.Name, &g.Description, &g.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan reviewer group: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		if err := r.loadRelations(ctx, &groups[i]); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+func (r *pgGroupRepository) SetMembers(ctx context.Context, name string, userIDs []string) error {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var groupID int64
+	err = tx.QueryRow(ctx, `
+		SELECT id FROM reviewer_groups WHERE name = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, name, orgID(ctx)).Scan(&groupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get reviewer group: %w", err)
+	}
+
+	if err := replaceGroupMembers(ctx, tx, groupID, userIDs); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgGroupRepository) Delete(ctx context.Context, name string) error {
+	const q = `
+		UPDATE reviewer_groups
+		SET deleted_at = NOW()
+		WHERE name = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`
+	res, err := conn(ctx, r.pool).Exec(ctx, q, name, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete reviewer group: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgGroupRepository) AddRule(
+	ctx context.Context,
+	groupName string,
+	rule models.GroupRule) (models.GroupRule, error) {
+	const q = `
+		INSERT INTO reviewer_group_rules (group_id, label, title_pattern)
+		SELECT g.id, NULLIF($2, ''), NULLIF($3, '')
+		FROM reviewer_groups g
+		WHERE g.name = $1 AND g.organization_id = $4 AND g.deleted_at IS NULL
+		RETURNING id, group_id
+	`
+	err := conn(ctx, r.pool).QueryRow(ctx, q, groupName, rule.Label, rule.TitlePattern, orgID(ctx)).
+		Scan(&rule.ID, &rule.GroupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.GroupRule{}, ErrNotFound
+		}
+		return models.GroupRule{}, fmt.Errorf("add group rule: %w", err)
+	}
+	rule.GroupName = groupName
+	return rule, nil
+}
+
+func (r *pgGroupRepository) DeleteRule(ctx context.Context, ruleID int64) error {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM reviewer_group_rules gr
+		USING reviewer_groups g
+		WHERE gr.id = $1 AND g.id = gr.group_id AND g.organization_id = $2
+	`, ruleID, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete group rule: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgGroupRepository) ListRules(ctx context.Context) ([]models.GroupRule, error) {
+	return r.queryRules(ctx, `
+		SELECT gr.id, gr.group_id, g.name, COALESCE(gr.label, ''), COALESCE(gr.title_pattern, '')
+		FROM reviewer_group_rules gr
+		JOIN reviewer_groups g ON g.id = gr.group_id
+		WHERE g.organization_id = $1 AND g.deleted_at IS NULL
+		ORDER BY gr.id
+	`, orgID(ctx))
+}
+
+func (r *pgGroupRepository) GetMembers(ctx context.Context, groupID int64) ([]models.User, error) {
+	const q = `
+		SELECT u.id, u.user_id, u.name, u.is_active, u.team_id, u.tags, u.seniority
+		FROM reviewer_group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.id
+	`
+	rows, err := conn(ctx, r.pool).Query(ctx, q, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("get group members: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *pgGroupRepository) queryRules(ctx context.Context, q string, args ...any) ([]models.GroupRule, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list group rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.GroupRule{}
+	for rows.Next() {
+		var gr models.GroupRule
+		if err := rows.Scan(&gr.ID, &gr.GroupID, &gr.GroupName, &gr.Label, &gr.TitlePattern); err != nil {
+			return nil, fmt.Errorf("scan group rule: %w", err)
+		}
+		rules = append(rules, gr)
+	}
+	return rules, rows.Err()
+}
+
+func (r *pgGroupRepository) loadRelations(ctx context.Context, g *models.ReviewerGroup) error {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT u.user_id
+		FROM reviewer_group_members gm
+		JOIN users u ON u.id = gm.user_id
+		WHERE gm.group_id = $1
+		ORDER BY u.user_id
+	`, g.ID)
+	if err != nil {
+		return fmt.Errorf("get group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("scan group member: %w", err)
+		}
+		members = append(members, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rules, err := r.queryRules(ctx, `
+		SELECT gr.id, gr.group_id, g.name, COALESCE(gr.label, ''), COALESCE(gr.title_pattern, '')
+		FROM reviewer_group_rules gr
+		JOIN reviewer_groups g ON g.id = gr.group_id
+		WHERE gr.group_id = $1
+		ORDER BY gr.id
+	`, g.ID)
+	if err != nil {
+		return err
+	}
+
+	g.Members = members
+	g.Rules = rules
+	return nil
+}
+
+func replaceGroupMembers(ctx context.Context, tx pgx.Tx, groupID int64, userIDs []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM reviewer_group_members WHERE group_id = $1`, groupID); err != nil {
+		return fmt.Errorf("clear group members: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	res, err := tx.Exec(ctx, `
+		INSERT INTO reviewer_group_members (group_id, user_id)
+		SELECT $1, u.id
+		FROM users u
+		WHERE u.user_id = ANY($2) AND u.organization_id = $3 AND u.deleted_at IS NULL
+	`, groupID, userIDs, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("set group members: %w", err)
+	}
+	if int(res.RowsAffected()) != len(userIDs) {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type IdentityRepository interface {
+	Set(ctx context.Context, provider, username, userID string) (models.ExternalIdentity, error)
+	Delete(ctx context.Context, provider, username string) error
+	List(ctx context.Context, provider string) ([]models.ExternalIdentity, error)
+	Resolve(ctx context.Context, provider, username string) (string, error)
+}
+
+type pgIdentityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdentityRepository(pool *pgxpool.Pool) IdentityRepository {
+	return &pgIdentityRepository{pool: pool}
+}
+
+// Set maps username to the user, replacing any previous mapping. It returns
+// ErrNotFound when the user does not exist.
+func (r *pgIdentityRepository) Set(
+	ctx context.Context,
+	provider, username, userID string) (models.ExternalIdentity, error) {
+	id := models.ExternalIdentity{Provider: provider, Username: username, UserID: userID}
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO external_identities (organization_id, provider, username, user_id)
+		SELECT $1, $2, $3, u.id
+		FROM users u
+		WHERE u.user_id = $4 AND u.organization_id = $1 AND u.deleted_at IS NULL
+		ON CONFLICT (organization_id, provider, username)
+			DO UPDATE SET user_id = EXCLUDED.user_id, created_at = NOW()
+		RETURNING created_at
+	`, orgID(ctx), provider, username, userID).Scan(&id.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ExternalIdentity{}, ErrNotFound
+		}
+		return models.ExternalIdentity{}, fmt.Errorf("set external identity: %w", err)
+	}
+	return id, nil
+}
+
+func (r *pgIdentityRepository) Delete(ctx context.Context, provider, username string) error {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM external_identities
+		WHERE organization_id = $1 AND provider = $2 AND username = $3
+	`, orgID(ctx), provider, username)
+	if err != nil {
+		return fmt.Errorf("delete external identity: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns the mappings of provider, or of every provider when it is
+// empty.
+func (r *pgIdentityRepository) List(ctx context.Context, provider string) ([]models.ExternalIdentity, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT ei.provider, ei.username, u.user_id, ei.created_at
+		FROM external_identities ei
+		JOIN users u ON u.id = ei.user_id
+		WHERE ei.organization_id = $1 AND ($2 = '' OR ei.provider = $2)
+		ORDER BY ei.provider, ei.username
+	`, orgID(ctx), provider)
+	if err != nil {
+		return nil, fmt.Errorf("list external identities: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.ExternalIdentity
+	for rows.Next() {
+		var id models.ExternalIdentity
+		if err := rows.Scan(&id.Provider, &id.Username, &id.UserID, &id.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan external identity: %w", err)
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// Resolve returns the user_id mapped to username.
+func (r *pgIdentityRepository) Resolve(ctx context.Context, provider, username string) (string, error) {
+	var userID string
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT u.user_id
+		FROM external_identities ei
+		JOIN users u ON u.id = ei.user_id
+		WHERE ei.organization_id = $1 AND ei.provider = $2 AND ei.username = $3 AND u.deleted_at IS NULL
+	`, orgID(ctx), provider, username).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("resolve external identity: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IntegrationRepository interface {
+	RecordDelivery(ctx context.Context, provider, deliveryID, event string) (bool, error)
+}
+
+type pgIntegrationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIntegrationRepository(pool *pgxpool.Pool) IntegrationRepository {
+	return &pgIntegrationRepository{pool: pool}
+}
+
+// RecordDelivery remembers an inbound delivery and reports false when it was
+// already recorded. It writes through ctx, so a delivery whose processing
+// rolls back can be retried.
+func (r *pgIntegrationRepository) RecordDelivery(
+	ctx context.Context,
+	provider, deliveryID, event string) (bool, error) {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO integration_deliveries (provider, delivery_id, organization_id, event)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (provider, delivery_id) DO NOTHING
+	`, provider, deliveryID, orgID(ctx), event)
+	if err != nil {
+		return false, fmt.Errorf("record integration delivery: %w", err)
+	}
+	return res.RowsAffected() == 1, nil
+}
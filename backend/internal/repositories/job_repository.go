@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type JobRepository interface {
+	Create(ctx context.Context, kind string, payload []byte) (models.Job, error)
+	GetByID(ctx context.Context, id int64) (models.Job, error)
+	ClaimNext(ctx context.Context) (models.Job, error)
+	RequeueRunning(ctx context.Context) (int64, error)
+	SetTotal(ctx context.Context, id int64, total int) error
+	Advance(ctx context.Context, id int64) error
+	Finish(ctx context.Context, id int64, status string, result, items []byte, errMsg string) error
+}
+
+type pgJobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRepository(pool *pgxpool.Pool) JobRepository {
+	return &pgJobRepository{pool: pool}
+}
+
+const jobColumns = `
+	id, organization_id, kind, status, payload, result, error, progress_done, progress_total, items,
+	created_at, started_at, finished_at
+`
+
+func scanJob(row pgx.Row) (models.Job, error) {
+	var j models.Job
+	err := row.Scan(&j.ID, &j.OrganizationID, &j.Kind, &j.Status, &j.Payload, &j.Result, &j.Error, &j.Done,
+		&j.Total, &j.Items, &j.CreatedAt, &j.StartedAt, &j.FinishedAt)
+	return j, err
+}
+
+func (r *pgJobRepository) Create(ctx context.Context, kind string, payload []byte) (models.Job, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO jobs (organization_id, kind, payload)
+		VALUES ($1, $2, $3)
+		RETURNING `+jobColumns, orgID(ctx), kind, payload)
+	j, err := scanJob(row)
+	if err != nil {
+		return models.Job{}, fmt.Errorf("create job: %w", err)
+	}
+	return j, nil
+}
+
+func (r *pgJobRepository) GetByID(ctx context.Context, id int64) (models.Job, error) {
+	j, err := scanJob(conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT `+jobColumns+` FROM jobs WHERE id = $1 AND organization_id = $2
+	`, id, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Job{}, ErrNotFound
+		}
+		return models.Job{}, fmt.Errorf("get job: %w", err)
+	}
+	return j, nil
+}
+
+// ClaimNext marks the oldest pending job of any organization as running and
+// returns it. Several workers may claim concurrently; each job goes to exactly
+// one of them.
+func (r *pgJobRepository) ClaimNext(ctx context.Context) (models.Job, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE jobs
+		SET status = 'RUNNING', started_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'PENDING'
+			ORDER BY id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+jobColumns)
+	j, err := scanJob(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Job{}, ErrNotFound
+		}
+		return models.Job{}, fmt.Errorf("claim job: %w", err)
+	}
+	return j, nil
+}
+
+// RequeueRunning puts jobs interrupted by a restart back into the queue and
+// discards their partial progress.
+func (r *pgJobRepository) RequeueRunning(ctx context.Context) (int64, error) {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE jobs
+		SET status = 'PENDING', started_at = NULL, progress_done = 0, progress_total = 0, items = '[]'
+		WHERE status = 'RUNNING'
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("requeue running jobs: %w", err)
+	}
+	return res.RowsAffected(), nil
+}
+
+func (r *pgJobRepository) SetTotal(ctx context.Context, id int64, total int) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `UPDATE jobs SET progress_total = $2 WHERE id = $1`, id, total)
+	if err != nil {
+		return fmt.Errorf("set job total: %w", err)
+	}
+	return nil
+}
+
+func (r *pgJobRepository) Advance(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `UPDATE jobs SET progress_done = progress_done + 1 WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("advance job: %w", err)
+	}
+	return nil
+}
+
+// Finish stores the outcome of the job together with its items, a JSON array.
+func (r *pgJobRepository) Finish(
+	ctx context.Context,
+	id int64,
+	status string,
+	result, items []byte,
+	errMsg string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE jobs
+		SET status = $2, result = $3, items = $4, error = $5, finished_at = NOW()
+		WHERE id = $1
+	`, id, status, result, items, errMsg)
+	if err != nil {
+		return fmt.Errorf("finish job: %w", err)
+	}
+	return nil
+}
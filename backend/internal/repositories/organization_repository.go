@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+// OrganizationRepository is not tenant-scoped: it resolves and manages the
+// tenants themselves.
+type OrganizationRepository interface {
+	Create(ctx context.Context, org models.Organization) (models.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (models.Organization, error)
+	List(ctx context.Context) ([]models.Organization, error)
+}
+
+type pgOrganizationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOrganizationRepository(pool *pgxpool.Pool) OrganizationRepository {
+	return &pgOrganizationRepository{pool: pool}
+}
+
+func (r *pgOrganizationRepository) Create(ctx context.Context, org models.Organization) (models.Organization, error) {
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO organizations (slug, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`, org.Slug, org.Name).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.Organization{}, ErrOrgExists
+		}
+		return models.Organization{}, fmt.Errorf("create organization: %w", err)
+	}
+	return org, nil
+}
+
+func (r *pgOrganizationRepository) GetBySlug(ctx context.Context, slug string) (models.Organization, error) {
+	var org models.Organization
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT id, slug, name, created_at
+		FROM organizations
+		WHERE slug = $1
+	`, slug).Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Organization{}, ErrNotFound
+		}
+		return models.Organization{}, fmt.Errorf("get organization: %w", err)
+	}
+	return org, nil
+}
+
+func (r *pgOrganizationRepository) List(ctx context.Context) ([]models.Organization, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `SELECT id, slug, name, created_at FROM organizations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []models.Organization
+	for rows.Next() {
+		var org models.Organization
+		if err := rows.Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
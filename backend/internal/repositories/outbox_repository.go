@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type OutboxRepository interface {
+	Insert(ctx context.Context, event, orderingKey string, payload []byte) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, dead bool, retryIn time.Duration, errMsg string) error
+	PurgePublished(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+type pgOutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool) OutboxRepository {
+	return &pgOutboxRepository{pool: pool}
+}
+
+// Insert writes through ctx, so the event exists only if the surrounding
+// transaction commits.
+func (r *pgOutboxRepository) Insert(ctx context.Context, event, orderingKey string, payload []byte) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO outbox_events (organization_id, event, ordering_key, payload)
+		VALUES ($1, $2, $3, $4)
+	`, orgID(ctx), event, orderingKey, payload)
+	if err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// Claim takes up to limit due events of any organization and leases them for
+// lease: until then no relay claims them again, and they count as an attempt.
+// Only the oldest pending event of each ordering key can be claimed, so a
+// key's events are never published out of order, even by concurrent relays.
+// Claim commits on its own; events are published outside any transaction.
+func (r *pgOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id
+			FROM outbox_events o
+			WHERE published_at IS NULL AND dead_at IS NULL AND next_attempt_at <= NOW()
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox_events p
+					WHERE p.organization_id = o.organization_id AND p.ordering_key = o.ordering_key
+						AND p.published_at IS NULL AND p.dead_at IS NULL AND p.id < o.id
+				)
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, organization_id, event, ordering_key, payload, attempts, created_at
+	`, limit, int64(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.OutboxEvent
+	for rows.Next() {
+		var e models.OutboxEvent
+		err := rows.Scan(&e.ID, &e.OrganizationID, &e.Event, &e.OrderingKey, &e.Payload, &e.Attempts, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+	// UPDATE ... RETURNING does not keep the order of the subquery.
+	slices.SortFunc(out, func(a, b models.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+	return out, nil
+}
+
+func (r *pgOutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE outbox_events
+		SET published_at = NOW(), last_error = ''
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return fmt.Errorf("mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed schedules the next attempt in retryIn, or moves the event to the
+// dead letter state when dead is set.
+func (r *pgOutboxRepository) MarkFailed(
+	ctx context.Context,
+	id int64,
+	dead bool,
+	retryIn time.Duration,
+	errMsg string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE outbox_events
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second', last_error = $3,
+			dead_at = CASE WHEN $4 THEN NOW() END
+		WHERE id = $1
+	`, id, int64(retryIn.Seconds()), errMsg, dead)
+	if err != nil {
+		return fmt.Errorf("mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+func (r *pgOutboxRepository) PurgePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM outbox_events
+		WHERE published_at < NOW() - $1 * INTERVAL '1 second'
+	`, int64(olderThan.Seconds()))
+	if err != nil {
+		return 0, fmt.Errorf("purge outbox events: %w", err)
+	}
+	return res.RowsAffected(), nil
+}
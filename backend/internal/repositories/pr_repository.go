@@ -8,6 +8,7 @@ import (
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
@@ -17,7 +18,8 @@ type PRRepository interface {
 	Create(ctx context.Context, pr models.PullRequest) (models.PullRequest, error)
 	GetByPullRequestID(ctx context.Context, prID string) (models.PullRequest, error)
 	UpdateStatus(ctx context.Context, prID string, status string, mergedAt *time.Time) error
-	AssignReviewers(ctx context.Context, prInternalID int64, reviewerInternalIDs []int64) error
+	UpdateMetadata(ctx context.Context, pr models.PullRequest) (models.PullRequest, error)
+	AssignReviewers(ctx context.Context, prInternalID int64, assignments []models.ReviewAssignment) error
 	GetReviewers(ctx context.Context, prID int64) ([]string, error)
 	RemoveReviewer(ctx context.Context, prID int64, userID int64) error
 	AddReviewer(ctx context.Context, prID int64, userID int64, slot int) error
@@ -25,6 +27,10 @@ type PRRepository interface {
 	GetOpenPRsWithReviewers(ctx context.Context, deactivatedInternalIDs []int64) ([]dtos.PRWithReviewers, error)
 	ReplaceReviewer(ctx context.Context, prID int64, oldReviewerID, newReviewerID int64, slot int) error
 	GetReviewerSlot(ctx context.Context, prID, reviewerID int64) (int, error)
+	GetAssignment(ctx context.Context, prID, reviewerID int64) (models.ReviewAssignment, error)
+	LockByPullRequestID(ctx context.Context, prID string) error
+	LockByIDs(ctx context.Context, ids []int64) error
+	GetVersion(ctx context.Context, prID int64) (int64, error)
 }
 
 type pgPRRepository struct {
@@ -37,13 +43,32 @@ func NewPRRepository(pool *pgxpool.Pool) PRRepository {
 
 func (r *pgPRRepository) Create(ctx context.Context, pr models.PullRequest) (models.PullRequest, error) {
 	const q = `
-		INSERT INTO pull_requests (pr_id, title, author_id, status, created_at)
-		VALUES ($1, $2, $3, $4::pr_status, $5)
-		RETURNING id, created_at, updated_at
+		INSERT INTO pull_requests (
+			pr_id, title, author_id, status, created_at,
+			repository, source_branch, target_branch, description, url, labels, changed_files,
+			required_tags, organization_id
+		)
+		VALUES ($1, $2, $3, $4::pr_status, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, created_at, updated_at, version
 	`
-	err := r.pool.QueryRow(ctx, q, pr.PullRequestID, pr.Title, pr.AuthorUserID, pr.Status, time.Now()).
-		Scan(&pr.ID, &pr.CreatedAt, &pr.UpdatedAt)
+	if pr.Labels == nil {
+		pr.Labels = []string{}
+	}
+	if pr.ChangedFiles == nil {
+		pr.ChangedFiles = []string{}
+	}
+	if pr.RequiredTags == nil {
+		pr.RequiredTags = []string{}
+	}
+	err := conn(ctx, r.pool).QueryRow(ctx, q, pr.PullRequestID, pr.Title, pr.AuthorUserID, pr.Status, time.Now(),
+		pr.Repository, pr.SourceBranch, pr.TargetBranch, pr.Description, pr.URL, pr.Labels, pr.ChangedFiles,
+		pr.RequiredTags, orgID(ctx)).
+		Scan(&pr.ID, &pr.CreatedAt, &pr.UpdatedAt, &pr.Version)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.PullRequest{}, ErrPRExists
+		}
 		return models.PullRequest{}, fmt.Errorf("create pr: %w", err)
 	}
 	return pr, nil
@@ -51,14 +76,18 @@ func (r *pgPRRepository) Create(ctx context.Context, pr models.PullRequest) (mod
 
 func (r *pgPRRepository) GetByPullRequestID(ctx context.Context, prID string) (models.PullRequest, error) {
 	const q = `
-		SELECT id, pr_id, title, author_id, status::text, created_at, updated_at
+		SELECT id, pr_id, title, author_id, status::text, created_at, updated_at,
+		       repository, source_branch, target_branch, description, url, labels, changed_files,
+		       required_tags, version
 		FROM pull_requests
-		WHERE pr_id = $1 AND deleted_at IS NULL
+		WHERE pr_id = $1 AND organization_id = $2 AND deleted_at IS NULL
 	`
 
 	var pr models.PullRequest
-	err := r.pool.QueryRow(ctx, q, prID).Scan(
+	err := conn(ctx, r.pool).QueryRow(ctx, q, prID, orgID(ctx)).Scan(
 		&pr.ID, &pr.PullRequestID, &pr.Title, &pr.AuthorUserID, &pr.Status, &pr.CreatedAt, &pr.UpdatedAt,
+		&pr.Repository, &pr.SourceBranch, &pr.TargetBranch, &pr.Description, &pr.URL, &pr.Labels,
+		&pr.ChangedFiles, &pr.RequiredTags, &pr.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -80,9 +109,9 @@ func (r *pgPRRepository) UpdateStatus(ctx context.Context, prID string, status s
 	const q = `
 		UPDATE pull_requests
 		SET status = $2::pr_status, updated_at = $3
-		WHERE pr_id = $1 AND deleted_at IS NULL
+		WHERE pr_id = $1 AND organization_id = $4 AND deleted_at IS NULL
 	`
-	res, err := r.pool.Exec(ctx, q, prID, status, updatedAt)
+	res, err := conn(ctx, r.pool).Exec(ctx, q, prID, status, updatedAt, orgID(ctx))
 	if err != nil {
 		return fmt.Errorf("update pr status: %w", err)
 	}
@@ -92,8 +121,33 @@ func (r *pgPRRepository) UpdateStatus(ctx context.Context, prID string, status s
 	return nil
 }
 
-func (r *pgPRRepository) AssignReviewers(ctx context.Context, prInternalID int64, reviewerInternalIDs []int64) error {
-	tx, err := r.pool.Begin(ctx)
+func (r *pgPRRepository) UpdateMetadata(ctx context.Context, pr models.PullRequest) (models.PullRequest, error) {
+	const q = `
+		UPDATE pull_requests
+		SET title = $2, repository = $3, source_branch = $4, target_branch = $5,
+		    description = $6, url = $7, labels = $8
+		WHERE pr_id = $1 AND organization_id = $9 AND deleted_at IS NULL
+		RETURNING updated_at, version
+	`
+	if pr.Labels == nil {
+		pr.Labels = []string{}
+	}
+	err := conn(ctx, r.pool).QueryRow(ctx, q, pr.PullRequestID, pr.Title, pr.Repository, pr.SourceBranch,
+		pr.TargetBranch, pr.Description, pr.URL, pr.Labels, orgID(ctx)).Scan(&pr.UpdatedAt, &pr.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PullRequest{}, ErrNotFound
+		}
+		return models.PullRequest{}, fmt.Errorf("update pr metadata: %w", err)
+	}
+	return pr, nil
+}
+
+func (r *pgPRRepository) AssignReviewers(
+	ctx context.Context,
+	prInternalID int64,
+	assignments []models.ReviewAssignment) error {
+	tx, err := beginTx(ctx, r.pool)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
@@ -102,12 +156,15 @@ func (r *pgPRRepository) AssignReviewers(ctx context.Context, prInternalID int64
 	}()
 
 	const qInsert = `
-	  INSERT INTO pr_reviews (pr_id, reviewer_id, slot)
-	  VALUES ($1, $2, $3)
+	  INSERT INTO pr_reviews (pr_id, reviewer_id, slot, required_group_id)
+	  VALUES ($1, $2, $3, $4)
 	 `
-	for i, rid := range reviewerInternalIDs {
-		slot := i + 1
-		_, err := tx.Exec(ctx, qInsert, prInternalID, rid, slot)
+	for i, a := range assignments {
+		slot := a.Slot
+		if slot == 0 {
+			slot = i + 1
+		}
+		_, err := tx.Exec(ctx, qInsert, prInternalID, a.ReviewerID, slot, a.RequiredGroupID)
 		if err != nil {
 			return fmt.Errorf("assign reviewer: %w", err)
 		}
@@ -124,7 +181,7 @@ func (r *pgPRRepository) GetReviewers(ctx context.Context, prID int64) ([]string
 		WHERE pr.pr_id = $1
 		ORDER BY pr.slot
 	`
-	rows, err := r.pool.Query(ctx, q, prID)
+	rows, err := conn(ctx, r.pool).Query(ctx, q, prID)
 	if err != nil {
 		return nil, fmt.Errorf("get reviewers: %w", err)
 	}
@@ -146,7 +203,7 @@ func (r *pgPRRepository) RemoveReviewer(ctx context.Context, prID int64, userID
 		DELETE FROM pr_reviews
 		WHERE pr_id = $1 AND reviewer_id = $2
 	`
-	res, err := r.pool.Exec(ctx, q, prID, userID)
+	res, err := conn(ctx, r.pool).Exec(ctx, q, prID, userID)
 	if err != nil {
 		return fmt.Errorf("remove reviewer: %w", err)
 	}
@@ -161,7 +218,7 @@ func (r *pgPRRepository) AddReviewer(ctx context.Context, prID int64, userID int
 		INSERT INTO pr_reviews (pr_id, reviewer_id, slot)
 		VALUES ($1, $2, $3)
 	`
-	_, err := r.pool.Exec(ctx, q, prID, userID, slot)
+	_, err := conn(ctx, r.pool).Exec(ctx, q, prID, userID, slot)
 	if err != nil {
 		return fmt.Errorf("add reviewer: %w", err)
 	}
@@ -173,52 +230,58 @@ func (r *pgPRRepository) ExistsByPullRequestID(ctx context.Context, prID string)
         SELECT EXISTS(
             SELECT 1 
             FROM pull_requests 
-            WHERE pr_id = $1 AND deleted_at IS NULL
+            WHERE pr_id = $1 AND organization_id = $2 AND deleted_at IS NULL
         )
     `
 	var exists bool
-	err := r.pool.QueryRow(ctx, q, prID).Scan(&exists)
+	err := conn(ctx, r.pool).QueryRow(ctx, q, prID, orgID(ctx)).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("check pr exists: %w", err)
 	}
 	return exists, nil
 }
 
+// GetOpenPRsWithReviewers returns open PRs reviewed by any of the given users,
+// each with its full reviewer list, ordered by id.
 func (r *pgPRRepository) GetOpenPRsWithReviewers(ctx context.Context,
 	deactivatedInternalIDs []int64) ([]dtos.PRWithReviewers, error) {
 	const q = `
         SELECT DISTINCT
             pr.id, pr.pr_id, pr.title, pr.author_id, pr.status::text,
-            prr.reviewer_id, prr.slot,
+            prr.reviewer_id, prr.slot, prr.required_group_id,
             u.user_id, u.name, u.team_id, u.is_active
         FROM pull_requests pr
         JOIN pr_reviews prr ON pr.id = prr.pr_id
         JOIN users u ON prr.reviewer_id = u.id
         WHERE pr.status = 'OPEN'
+          AND pr.organization_id = $2
           AND pr.deleted_at IS NULL
-          AND prr.reviewer_id = ANY($1)
+          AND pr.id IN (SELECT pr_id FROM pr_reviews WHERE reviewer_id = ANY($1))
         ORDER BY pr.id, prr.slot
     `
-	rows, err := r.pool.Query(ctx, q, deactivatedInternalIDs)
+	rows, err := conn(ctx, r.pool).Query(ctx, q, deactivatedInternalIDs, orgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get open PRs with reviewers: %w", err)
 	}
 	defer rows.Close()
 
 	prMap := make(map[int64]*dtos.PRWithReviewers)
+	var order []int64
 	for rows.Next() {
 		var prID, authorID, reviewerID, teamID int64
 		var prExtID, title, status, userID, name string
 		var slot int
+		var groupID *int64
 		var isActive bool
 
 		if err := rows.Scan(&prID, &prExtID, &title, &authorID,
-			&status, &reviewerID, &slot, &userID, &name, &teamID,
+			&status, &reviewerID, &slot, &groupID, &userID, &name, &teamID,
 			&isActive); err != nil {
 			return nil, fmt.Errorf("scan pr with reviewer: %w", err)
 		}
 
 		if _, exists := prMap[prID]; !exists {
+			order = append(order, prID)
 			prMap[prID] = &dtos.PRWithReviewers{
 				PR: models.PullRequest{
 					ID:            prID,
@@ -227,9 +290,13 @@ func (r *pgPRRepository) GetOpenPRsWithReviewers(ctx context.Context,
 					AuthorUserID:  authorID,
 					Status:        status,
 				},
-				Reviewers: []models.User{},
+				Reviewers:      []models.User{},
+				RequiredGroups: map[int64]int64{},
 			}
 		}
+		if groupID != nil {
+			prMap[prID].RequiredGroups[reviewerID] = *groupID
+		}
 
 		prMap[prID].Reviewers = append(prMap[prID].Reviewers, models.User{
 			ID:       reviewerID,
@@ -240,9 +307,9 @@ func (r *pgPRRepository) GetOpenPRsWithReviewers(ctx context.Context,
 		})
 	}
 
-	result := make([]dtos.PRWithReviewers, 0, len(prMap))
-	for _, prwr := range prMap {
-		result = append(result, *prwr)
+	result := make([]dtos.PRWithReviewers, 0, len(order))
+	for _, id := range order {
+		result = append(result, *prMap[id])
 	}
 	return result, rows.Err()
 }
@@ -258,20 +325,68 @@ func (r *pgPRRepository) ReplaceReviewer(
         SET reviewer_id = $1, assigned_at = NOW()
         WHERE pr_id = $2 AND reviewer_id = $3 AND slot = $4
     `
-	res, err := r.pool.Exec(ctx, q, newReviewerID, prID, oldReviewerID, slot)
+	// A savepoint keeps a failed replacement from aborting the caller's
+	// transaction, so bulk restaffing can carry on with the next slot.
+	tx, err := beginTx(ctx, r.pool)
 	if err != nil {
 		return fmt.Errorf("replace reviewer: %w", err)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	res, err := tx.Exec(ctx, q, newReviewerID, prID, oldReviewerID, slot)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrConflict
+		}
+		return conflict(fmt.Errorf("replace reviewer: %w", err))
+	}
 	if res.RowsAffected() == 0 {
 		return ErrNotFound
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("replace reviewer: %w", err)
+	}
+	return nil
+}
+
+// LockByPullRequestID takes the row lock of a PR until the transaction carried
+// by ctx ends. Reviewer changes to a PR are serialized through this lock.
+func (r *pgPRRepository) LockByPullRequestID(ctx context.Context, prID string) error {
+	const q = `
+		SELECT id FROM pull_requests
+		WHERE pr_id = $1 AND organization_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	var id int64
+	if err := conn(ctx, r.pool).QueryRow(ctx, q, prID, orgID(ctx)).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return conflict(fmt.Errorf("lock pr: %w", err))
+	}
+	return nil
+}
+
+// LockByIDs locks several PRs in id order, so concurrent callers locking
+// overlapping sets cannot deadlock.
+func (r *pgPRRepository) LockByIDs(ctx context.Context, ids []int64) error {
+	const q = `SELECT id FROM pull_requests WHERE id = ANY($1) AND organization_id = $2 ORDER BY id FOR UPDATE`
+	rows, err := conn(ctx, r.pool).Query(ctx, q, ids, orgID(ctx))
+	if err != nil {
+		return conflict(fmt.Errorf("lock prs: %w", err))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return conflict(fmt.Errorf("lock prs: %w", err))
+	}
 	return nil
 }
 
 func (r *pgPRRepository) GetReviewerSlot(ctx context.Context, prID, reviewerID int64) (int, error) {
 	const q = `SELECT slot FROM pr_reviews WHERE pr_id = $1 AND reviewer_id = $2`
 	var slot int
-	err := r.pool.QueryRow(ctx, q, prID, reviewerID).Scan(&slot)
+	err := conn(ctx, r.pool).QueryRow(ctx, q, prID, reviewerID).Scan(&slot)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, ErrNotFound
@@ -280,3 +395,34 @@ func (r *pgPRRepository) GetReviewerSlot(ctx context.Context, prID, reviewerID i
 	}
 	return slot, nil
 }
+
+func (r *pgPRRepository) GetAssignment(ctx context.Context, prID, reviewerID int64) (models.ReviewAssignment, error) {
+	const q = `
+		SELECT reviewer_id, slot, required_group_id
+		FROM pr_reviews
+		WHERE pr_id = $1 AND reviewer_id = $2
+	`
+	var a models.ReviewAssignment
+	err := conn(ctx, r.pool).QueryRow(ctx, q, prID, reviewerID).Scan(&a.ReviewerID, &a.Slot, &a.RequiredGroupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ReviewAssignment{}, ErrNotFound
+		}
+		return models.ReviewAssignment{}, fmt.Errorf("get assignment: %w", err)
+	}
+	return a, nil
+}
+
+func (r *pgPRRepository) GetVersion(ctx context.Context, prID int64) (int64, error) {
+	var version int64
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT version FROM pull_requests WHERE id = $1 AND organization_id = $2
+	`, prID, orgID(ctx)).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("get pr version: %w", err)
+	}
+	return version, nil
+}
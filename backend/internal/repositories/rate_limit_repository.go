@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+// staleBucketAge is how long an untouched bucket is kept. It must exceed the
+// refill time of every configured limit.
+const staleBucketAge = 24 * time.Hour
+
+// RateLimitRepository keeps token buckets in Postgres so that several
+// instances share them. It is not tenant-scoped: keys are global.
+type RateLimitRepository struct {
+	pool      *pgxpool.Pool
+	lastSweep atomic.Int64
+}
+
+func NewRateLimitRepository(pool *pgxpool.Pool) *RateLimitRepository {
+	return &RateLimitRepository{pool: pool}
+}
+
+// Take takes a token from the bucket under key, creating a full one if there
+// is none. Concurrent calls for the same key are serialized by its row lock;
+// the database clock is used so instances need not agree on time.
+func (r *RateLimitRepository) Take(
+	ctx context.Context,
+	key string,
+	limit models.RateLimit) (bool, time.Duration, error) {
+	r.sweep(ctx)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var tokens float64
+	var last, now time.Time
+	err = tx.QueryRow(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+		RETURNING tokens, updated_at, NOW()
+	`, key, float64(limit.Burst)).Scan(&tokens, &last, &now)
+	if err != nil {
+		return false, 0, fmt.Errorf("load rate limit bucket: %w", err)
+	}
+
+	tokens, wait, ok := limit.Take(tokens, last, now)
+	_, err = tx.Exec(ctx, `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`,
+		key, tokens, now)
+	if err != nil {
+		return false, 0, fmt.Errorf("save rate limit bucket: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, fmt.Errorf("commit: %w", err)
+	}
+	return ok, wait, nil
+}
+
+// sweep drops stale buckets at most once a minute per instance.
+func (r *RateLimitRepository) sweep(ctx context.Context) {
+	now := time.Now().Unix()
+	last := r.lastSweep.Load()
+	if now-last < 60 || !r.lastSweep.CompareAndSwap(last, now) {
+		return
+	}
+	_, _ = r.pool.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - $1 * INTERVAL '1 second'`,
+		int64(staleBucketAge.Seconds()))
+}
@@ -0,0 +1,316 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type RepoRepository interface {
+	Create(ctx context.Context, repo models.Repository) (models.Repository, error)
+	GetByName(ctx context.Context, name string) (models.Repository, error)
+	List(ctx context.Context) ([]models.Repository, error)
+	Update(ctx context.Context, repo models.Repository) (models.Repository, error)
+	Delete(ctx context.Context, name string) error
+	SetCodeowners(ctx context.Context, name string, content string) error
+	GetReviewerPool(ctx context.Context, repoID int64) ([]models.User, error)
+	GetOwnerTeamMembers(ctx context.Context, repoID int64) ([]models.User, error)
+}
+
+type pgRepoRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepoRepository(pool *pgxpool.Pool) RepoRepository {
+	return &pgRepoRepository{pool: pool}
+}
+
+func (r *pgRepoRepository) Create(ctx context.Context, repo models.Repository) (models.Repository, error) {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return models.Repository{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+		INSERT INTO repositories (organization_id, name, url)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(ctx, q, orgID(ctx), repo.Name, repo.URL).Scan(&repo.ID, &repo.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.Repository{}, ErrRepoExists
+		}
+		return models.Repository{}, fmt.Errorf("create repository: %w", err)
+	}
+
+	if err := replaceOwnerTeams(ctx, tx, repo.ID, repo.OwnerTeams); err != nil {
+		return models.Repository{}, err
+	}
+	if err := replaceReviewerPool(ctx, tx, repo.ID, repo.Reviewers); err != nil {
+		return models.Repository{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Repository{}, fmt.Errorf("commit: %w", err)
+	}
+	return repo, nil
+}
+
+func (r *pgRepoRepository) GetByName(ctx context.Context, name string) (models.Repository, error) {
+	const q = `
+		SELECT id, name, url, codeowners, created_at
+		FROM repositories
+		WHERE name = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`
+	var repo models.Repository
+	err := conn(ctx, r.pool).QueryRow(ctx, q, name, orgID(ctx)).Scan(&repo.ID, &repo.Name, &repo.URL, &repo.Codeowners, &repo.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Repository{}, ErrNotFound
+		}
+		return models.Repository{}, fmt.Errorf("get repository: %w", err)
+	}
+
+	if err := r.loadRelations(ctx, &repo); err != nil {
+		return models.Repository{}, err
+	}
+	return repo, nil
+}
+
+func (r *pgRepoRepository) List(ctx context.Context) ([]models.Repository, error) {
+	const q = `
+		SELECT id, name, url, created_at
+		FROM repositories
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		ORDER BY name
+	`
+	rows, err := conn(ctx, r.pool).Query(ctx, q, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list repositories: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []models.Repository
+	for rows.Next() {
+		var repo models.Repository
+		if err := rows.Scan(&repo.ID, &repo.Name, &repo.URL, &repo.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan repository: %w", err)
+		}
+		repos = append(repos, repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range repos {
+		if err := r.loadRelations(ctx, &repos[i]); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+func (r *pgRepoRepository) Update(ctx context.Context, repo models.Repository) (models.Repository, error) {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return models.Repository{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const q = `
+		UPDATE repositories
+		SET url = $2
+		WHERE name = $1 AND organization_id = $3 AND deleted_at IS NULL
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRow(ctx, q, repo.Name, repo.URL, orgID(ctx)).Scan(&repo.ID, &repo.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Repository{}, ErrNotFound
+		}
+		return models.Repository{}, fmt.Errorf("update repository: %w", err)
+	}
+
+	if err := replaceOwnerTeams(ctx, tx, repo.ID, repo.OwnerTeams); err != nil {
+		return models.Repository{}, err
+	}
+	if err := replaceReviewerPool(ctx, tx, repo.ID, repo.Reviewers); err != nil {
+		return models.Repository{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Repository{}, fmt.Errorf("commit: %w", err)
+	}
+	return repo, nil
+}
+
+func (r *pgRepoRepository) Delete(ctx context.Context, name string) error {
+	const q = `
+		UPDATE repositories
+		SET deleted_at = NOW()
+		WHERE name = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`
+	res, err := conn(ctx, r.pool).Exec(ctx, q, name, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete repository: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgRepoRepository) SetCodeowners(ctx context.Context, name string, content string) error {
+	const q = `
+		UPDATE repositories
+		SET codeowners = $2
+		WHERE name = $1 AND organization_id = $3 AND deleted_at IS NULL
+	`
+	res, err := conn(ctx, r.pool).Exec(ctx, q, name, content, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("set codeowners: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *pgRepoRepository) GetReviewerPool(ctx context.Context, repoID int64) ([]models.User, error) {
+	const q = `
+		SELECT u.id, u.user_id, u.name, u.is_active, u.team_id, u.tags, u.seniority
+		FROM repository_reviewers rr
+		JOIN users u ON u.id = rr.user_id
+		WHERE rr.repository_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.id
+	`
+	return r.queryUsers(ctx, q, repoID)
+}
+
+func (r *pgRepoRepository) GetOwnerTeamMembers(ctx context.Context, repoID int64) ([]models.User, error) {
+	const q = `
+		SELECT u.id, u.user_id, u.name, u.is_active, u.team_id, u.tags, u.seniority
+		FROM repository_teams rt
+		JOIN users u ON u.team_id = rt.team_id
+		WHERE rt.repository_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.id
+	`
+	return r.queryUsers(ctx, q, repoID)
+}
+
+func (r *pgRepoRepository) queryUsers(ctx context.Context, q string, args ...any) ([]models.User, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *pgRepoRepository) loadRelations(ctx context.Context, repo *models.Repository) error {
+	teams, err := r.queryStrings(ctx, `
+		SELECT t.name
+		FROM repository_teams rt
+		JOIN teams t ON t.id = rt.team_id
+		WHERE rt.repository_id = $1
+		ORDER BY t.name
+	`, repo.ID)
+	if err != nil {
+		return fmt.Errorf("get owner teams: %w", err)
+	}
+
+	reviewers, err := r.queryStrings(ctx, `
+		SELECT u.user_id
+		FROM repository_reviewers rr
+		JOIN users u ON u.id = rr.user_id
+		WHERE rr.repository_id = $1
+		ORDER BY u.user_id
+	`, repo.ID)
+	if err != nil {
+		return fmt.Errorf("get reviewer pool: %w", err)
+	}
+
+	repo.OwnerTeams = teams
+	repo.Reviewers = reviewers
+	return nil
+}
+
+func (r *pgRepoRepository) queryStrings(ctx context.Context, q string, args ...any) ([]string, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func replaceOwnerTeams(ctx context.Context, tx pgx.Tx, repoID int64, teamNames []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM repository_teams WHERE repository_id = $1`, repoID); err != nil {
+		return fmt.Errorf("clear owner teams: %w", err)
+	}
+	if len(teamNames) == 0 {
+		return nil
+	}
+
+	res, err := tx.Exec(ctx, `
+		INSERT INTO repository_teams (repository_id, team_id)
+		SELECT $1, t.id
+		FROM teams t
+		WHERE t.name = ANY($2) AND t.organization_id = $3 AND t.deleted_at IS NULL
+	`, repoID, teamNames, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("set owner teams: %w", err)
+	}
+	if int(res.RowsAffected()) != len(teamNames) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func replaceReviewerPool(ctx context.Context, tx pgx.Tx, repoID int64, userIDs []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM repository_reviewers WHERE repository_id = $1`, repoID); err != nil {
+		return fmt.Errorf("clear reviewer pool: %w", err)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	res, err := tx.Exec(ctx, `
+		INSERT INTO repository_reviewers (repository_id, user_id)
+		SELECT $1, u.id
+		FROM users u
+		WHERE u.user_id = ANY($2) AND u.organization_id = $3 AND u.deleted_at IS NULL
+	`, repoID, userIDs, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("set reviewer pool: %w", err)
+	}
+	if int(res.RowsAffected()) != len(userIDs) {
+		return ErrNotFound
+	}
+	return nil
+}
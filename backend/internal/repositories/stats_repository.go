@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
@@ -27,11 +28,11 @@ func (r *StatsRepository) GetReviewerStats(ctx context.Context) ([]dtos.Reviewer
         FROM users u
         LEFT JOIN pr_reviews prr ON u.id = prr.reviewer_id
         LEFT JOIN pull_requests pr ON prr.pr_id = pr.id AND pr.deleted_at IS NULL
-        WHERE u.deleted_at IS NULL
+        WHERE u.organization_id = $1 AND u.deleted_at IS NULL
         GROUP BY u.id, u.user_id, u.name
         ORDER BY assigned_count DESC
     `
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, orgID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -57,11 +58,11 @@ func (r *StatsRepository) GetPRStats(ctx context.Context) ([]dtos.PRStats, error
             pr.status::text
         FROM pull_requests pr
         LEFT JOIN pr_reviews prr ON pr.id = prr.pr_id
-        WHERE pr.deleted_at IS NULL
+        WHERE pr.organization_id = $1 AND pr.deleted_at IS NULL
         GROUP BY pr.id, pr.pr_id, pr.title, pr.status
         ORDER BY reviewers_count DESC
     `
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, orgID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +85,41 @@ func (r *StatsRepository) GetTotalAssignments(ctx context.Context) (int, error)
         SELECT COUNT(*) 
         FROM pr_reviews prr
         JOIN pull_requests pr ON prr.pr_id = pr.id
-        WHERE pr.deleted_at IS NULL
+        WHERE pr.organization_id = $1 AND pr.deleted_at IS NULL
     `
-	err := r.pool.QueryRow(ctx, query).Scan(&total)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, orgID(ctx)).Scan(&total)
 	return total, err
 }
+
+func (r *StatsRepository) GetPairStats(ctx context.Context, since time.Time) ([]dtos.PairStats, error) {
+	query := `
+        SELECT
+            a.user_id,
+            u.user_id,
+            COUNT(*) as pair_count
+        FROM pr_reviews prr
+        JOIN pull_requests pr ON prr.pr_id = pr.id
+        JOIN users a ON pr.author_id = a.id
+        JOIN users u ON prr.reviewer_id = u.id
+        WHERE pr.organization_id = $2
+          AND pr.deleted_at IS NULL
+          AND prr.assigned_at >= $1
+        GROUP BY a.user_id, u.user_id
+        ORDER BY pair_count DESC, a.user_id, u.user_id
+    `
+	rows, err := conn(ctx, r.pool).Query(ctx, query, since, orgID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []dtos.PairStats{}
+	for rows.Next() {
+		var s dtos.PairStats
+		if err := rows.Scan(&s.AuthorUserID, &s.ReviewerUserID, &s.Count); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -15,6 +15,8 @@ type TeamRepository interface {
 	CreateTeamWithMembers(ctx context.Context, teamName string, members []models.User) error
 	GetTeamWithMembers(ctx context.Context, teamName string) (models.Team, []models.User, error)
 	ExistsTeamWithMembers(ctx context.Context, teamName string, members []models.User) (bool, error)
+	GetByID(ctx context.Context, teamID int64) (models.Team, error)
+	SetReviewPolicy(ctx context.Context, teamName string, policy string, expectedVersion *int64) error
 }
 
 type PgTeamRepository struct {
@@ -26,14 +28,17 @@ func NewPgTeamRepository(pool *pgxpool.Pool) *PgTeamRepository {
 }
 
 func (r *PgTeamRepository) CreateTeamWithMembers(ctx context.Context, teamName string, members []models.User) error {
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := beginTx(ctx, r.pool)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
+	org := orgID(ctx)
 	var teamID int64
-	if err = tx.QueryRow(ctx, `INSERT INTO teams(name) VALUES ($1) RETURNING id`, teamName).Scan(&teamID); err != nil {
+	err = tx.QueryRow(ctx, `INSERT INTO teams(organization_id, name) VALUES ($1, $2) RETURNING id`, org, teamName).
+		Scan(&teamID)
+	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			return ErrTeamExists
@@ -43,10 +48,14 @@ func (r *PgTeamRepository) CreateTeamWithMembers(ctx context.Context, teamName s
 
 	batch := &pgx.Batch{}
 	for _, m := range members {
+		seniority := m.Seniority
+		if seniority == "" {
+			seniority = models.SeniorityMiddle
+		}
 		batch.Queue(`
-            INSERT INTO users(user_id, name, team_id, is_active)
-            VALUES ($1, $2, $3, $4)
-        `, m.UserID, m.Name, teamID, m.IsActive)
+            INSERT INTO users(organization_id, user_id, name, team_id, is_active, seniority)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, org, m.UserID, m.Name, teamID, m.IsActive, seniority)
 	}
 
 	br := tx.SendBatch(ctx, batch)
@@ -73,16 +82,19 @@ func (r *PgTeamRepository) CreateTeamWithMembers(ctx context.Context, teamName s
 func (r *PgTeamRepository) GetTeamWithMembers(ctx context.Context,
 	teamName string) (models.Team, []models.User, error) {
 	var t models.Team
-	if err := r.pool.QueryRow(ctx, `SELECT id, name FROM teams WHERE name=$1`, teamName).
-		Scan(&t.ID, &t.Name); err != nil {
+	if err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT id, name, review_policy, version
+		FROM teams
+		WHERE name = $1 AND organization_id = $2
+	`, teamName, orgID(ctx)).Scan(&t.ID, &t.Name, &t.ReviewPolicy, &t.Version); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.Team{}, nil, ErrNotFound
 		}
 		return models.Team{}, nil, err
 	}
 
-	rows, err := r.pool.Query(ctx, `
-		SELECT user_id, name, team_id, is_active
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT user_id, name, team_id, is_active, seniority
 		FROM users
 		WHERE team_id = $1
 		ORDER BY user_id
@@ -95,7 +107,7 @@ func (r *PgTeamRepository) GetTeamWithMembers(ctx context.Context,
 	var users []models.User
 	for rows.Next() {
 		var u models.User
-		if err = rows.Scan(&u.UserID, &u.Name, &u.TeamID, &u.IsActive); err != nil {
+		if err = rows.Scan(&u.UserID, &u.Name, &u.TeamID, &u.IsActive, &u.Seniority); err != nil {
 			return models.Team{}, nil, err
 		}
 		users = append(users, u)
@@ -110,9 +122,9 @@ func (r *PgTeamRepository) GetTeamWithMembers(ctx context.Context,
 func (r *PgTeamRepository) ExistsTeamWithMembers(ctx context.Context,
 	teamName string, members []models.User) (bool, error) {
 	var dummy int
-	if err := r.pool.QueryRow(ctx,
-		`SELECT 1 FROM teams WHERE name = $1`,
-		teamName,
+	if err := conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT 1 FROM teams WHERE name = $1 AND organization_id = $2`,
+		teamName, orgID(ctx),
 	).Scan(&dummy); err != nil && err != pgx.ErrNoRows {
 		return false, err
 	} else if err == nil {
@@ -124,9 +136,9 @@ func (r *PgTeamRepository) ExistsTeamWithMembers(ctx context.Context,
 		userIDs = append(userIDs, m.UserID)
 	}
 
-	if err := r.pool.QueryRow(ctx,
-		`SELECT 1 FROM users WHERE user_id = ANY($1)`,
-		userIDs,
+	if err := conn(ctx, r.pool).QueryRow(ctx,
+		`SELECT 1 FROM users WHERE user_id = ANY($1) AND organization_id = $2`,
+		userIDs, orgID(ctx),
 	).Scan(&dummy); err != nil && err != pgx.ErrNoRows {
 		return false, err
 	} else if err == nil {
@@ -135,3 +147,51 @@ func (r *PgTeamRepository) ExistsTeamWithMembers(ctx context.Context,
 
 	return false, nil
 }
+
+func (r *PgTeamRepository) GetByID(ctx context.Context, teamID int64) (models.Team, error) {
+	var t models.Team
+	if err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT id, name, review_policy, version
+		FROM teams
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, teamID, orgID(ctx)).Scan(&t.ID, &t.Name, &t.ReviewPolicy, &t.Version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Team{}, ErrNotFound
+		}
+		return models.Team{}, err
+	}
+	return t, nil
+}
+
+// SetReviewPolicy updates the policy. When expectedVersion is set and the
+// team has moved on since, nothing is written and ErrVersionMismatch is
+// returned.
+func (r *PgTeamRepository) SetReviewPolicy(
+	ctx context.Context,
+	teamName string,
+	policy string,
+	expectedVersion *int64) error {
+	res, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE teams
+		SET review_policy = $2
+		WHERE name = $1 AND organization_id = $4 AND deleted_at IS NULL AND ($3::bigint IS NULL OR version = $3)
+	`, teamName, policy, expectedVersion, orgID(ctx))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() > 0 {
+		return nil
+	}
+
+	var dummy int
+	err = conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT 1 FROM teams WHERE name = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, teamName, orgID(ctx)).Scan(&dummy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return ErrVersionMismatch
+}
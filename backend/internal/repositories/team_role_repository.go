@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TeamRoleRepository interface {
+	GetRole(ctx context.Context, teamID, userID int64) (string, error)
+	SetRole(ctx context.Context, teamID, userID int64, role string) error
+}
+
+type pgTeamRoleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTeamRoleRepository(pool *pgxpool.Pool) TeamRoleRepository {
+	return &pgTeamRoleRepository{pool: pool}
+}
+
+// GetRole returns the explicit role of the user in the team, MEMBER for a
+// team member without one, and an empty string otherwise.
+func (r *pgTeamRoleRepository) GetRole(ctx context.Context, teamID, userID int64) (string, error) {
+	var role string
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT COALESCE(
+			(SELECT r.role
+			 FROM team_roles r
+			 JOIN teams t ON t.id = r.team_id
+			 WHERE r.team_id = $1 AND r.user_id = $2 AND t.organization_id = $3),
+			(SELECT 'MEMBER' FROM users WHERE id = $2 AND team_id = $1 AND organization_id = $3 AND deleted_at IS NULL),
+			''
+		)
+	`, teamID, userID, orgID(ctx)).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("get team role: %w", err)
+	}
+	return role, nil
+}
+
+func (r *pgTeamRoleRepository) SetRole(ctx context.Context, teamID, userID int64, role string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO team_roles (team_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`, teamID, userID, role)
+	if err != nil {
+		return fmt.Errorf("set team role: %w", err)
+	}
+	return nil
+}
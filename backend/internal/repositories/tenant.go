@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+)
+
+type orgKey struct{}
+
+// WithOrganization scopes every repository call made with ctx to one
+// organization. Queries filter on it and inserts are stamped with it.
+func WithOrganization(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// orgID returns the organization ctx is scoped to. A tenant-scoped call
+// without one is a bug in the caller: guessing an organization would read or
+// write another tenant's rows, so orgID panics instead.
+//
+// Child rows (pr_reviews, repository_teams, bulk_operation_users and the
+// like) are reached through a parent row that was already looked up in the
+// organization and are not filtered again.
+func orgID(ctx context.Context) int64 {
+	if id, ok := ctx.Value(orgKey{}).(int64); ok && id != 0 {
+		return id
+	}
+	panic("repositories: tenant-scoped call without an organization in the context")
+}
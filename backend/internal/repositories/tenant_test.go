@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOrgID(t *testing.T) {
+	if got := orgID(WithOrganization(context.Background(), 7)); got != 7 {
+		t.Fatalf("orgID = %d, want 7", got)
+	}
+	for name, ctx := range map[string]context.Context{
+		"unscoped": context.Background(),
+		"zero":     WithOrganization(context.Background(), 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("orgID did not panic")
+				}
+			}()
+			orgID(ctx)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type txKey struct{}
+
+// errDryRun forces a dry-run transaction to roll back.
+var errDryRun = errors.New("dry run")
+
+// querier is satisfied by both the pool and a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// conn returns the transaction carried by ctx, or the pool when there is none.
+func conn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+// beginTx starts a transaction, or a savepoint inside the transaction carried
+// by ctx, so repository methods compose with TxManager.
+func beginTx(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return pool.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// conflict maps lock and serialization failures to ErrConflict and leaves
+// other errors untouched.
+func conflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", "40P01", "55P03":
+			return fmt.Errorf("%w: %s", ErrConflict, pgErr.Message)
+		}
+	}
+	return err
+}
+
+// TxManager runs several repository calls in one transaction. Repositories
+// pick the transaction up from the context passed to fn.
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{pool: pool}
+}
+
+// WithinTx commits when fn succeeds and rolls back otherwise.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := beginTx(ctx, m.pool)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return conflict(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return conflict(fmt.Errorf("commit: %w", err))
+	}
+	return nil
+}
+
+// DryRun runs fn in a transaction that is always rolled back.
+func (m *TxManager) DryRun(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := m.WithinTx(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
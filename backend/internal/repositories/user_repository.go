@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -14,13 +15,22 @@ import (
 
 type UserRepository interface {
 	SetIsActive(ctx context.Context, userID string, active bool) (models.User, string, error)
+	SetSeniority(ctx context.Context, userID string, seniority string) (models.User, string, error)
+	SetTags(ctx context.Context, userID string, tags []string) (models.User, string, error)
+	AddTags(ctx context.Context, userID string, tags []string) (models.User, string, error)
+	RemoveTags(ctx context.Context, userID string, tags []string) (models.User, string, error)
+	GetOpenReviewLoad(ctx context.Context, userIDs []int64) (map[int64]int, error)
+	GetPairHistory(ctx context.Context, authorID int64, reviewerIDs []int64, since time.Time) (map[int64]int, error)
 	GetWithTeam(ctx context.Context, userID string) (models.User, string, error)
 	GetReviewPullRequests(ctx context.Context, userID string) ([]dtos.ReviewPullRequest, error)
 	GetByUserID(ctx context.Context, userID string) (models.User, error)
 	GetTeamMembers(ctx context.Context, teamID int64) ([]models.User, error)
+	GetByUserIDs(ctx context.Context, userIDs []string) ([]models.User, error)
+	GetMembersByTeamNames(ctx context.Context, teamNames []string) ([]models.User, map[string][]string, error)
 	GetReviewerSlot(ctx context.Context, prInternalID int64, reviewerInternalID int64) (int, error)
 	GetByInternalID(ctx context.Context, internalID int64) (models.User, error)
-	BulkDeactivate(ctx context.Context, teamID int64, userIDs []string) ([]int64, error)
+	BulkDeactivate(ctx context.Context, teamID int64, userIDs []string) ([]models.BulkUserFlip, error)
+	RestoreActive(ctx context.Context, flips []models.BulkUserFlip) error
 	GetActiveForReassignment(ctx context.Context, teamID int64, excludeUserIDs []int64) ([]models.User, error)
 }
 
@@ -33,13 +43,13 @@ func NewPgUserRepository(pool *pgxpool.Pool) *PgUserRepository {
 }
 
 func (r *PgUserRepository) SetIsActive(ctx context.Context, userID string, active bool) (models.User, string, error) {
-	row := r.pool.QueryRow(ctx, `
+	row := conn(ctx, r.pool).QueryRow(ctx, `
 		UPDATE users u
 		SET is_active = $2
 		FROM teams t
-		WHERE u.team_id = t.id AND u.user_id = $1
-		RETURNING u.id, u.user_id, u.name, u.team_id, u.is_active, t.name
-	`, userID, active)
+		WHERE u.team_id = t.id AND u.user_id = $1 AND u.organization_id = $3
+		RETURNING u.id, u.user_id, u.name, u.team_id, u.is_active, u.tags, u.seniority, t.name
+	`, userID, active, orgID(ctx))
 
 	var u models.User
 	var teamName string
@@ -49,6 +59,8 @@ func (r *PgUserRepository) SetIsActive(ctx context.Context, userID string, activ
 		&u.Name,
 		&u.TeamID,
 		&u.IsActive,
+		&u.Tags,
+		&u.Seniority,
 		&teamName,
 	)
 	if err != nil {
@@ -61,13 +73,141 @@ func (r *PgUserRepository) SetIsActive(ctx context.Context, userID string, activ
 	return u, teamName, nil
 }
 
+func (r *PgUserRepository) SetSeniority(
+	ctx context.Context,
+	userID string,
+	seniority string) (models.User, string, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE users u
+		SET seniority = $2
+		FROM teams t
+		WHERE u.team_id = t.id AND u.user_id = $1 AND u.organization_id = $3 AND u.deleted_at IS NULL
+		RETURNING u.id, u.user_id, u.name, u.team_id, u.is_active, u.tags, u.seniority, t.name
+	`, userID, seniority, orgID(ctx))
+
+	var u models.User
+	var teamName string
+	if err := row.Scan(&u.ID, &u.UserID, &u.Name, &u.TeamID, &u.IsActive, &u.Tags, &u.Seniority, &teamName); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, "", ErrNotFound
+		}
+		return models.User{}, "", fmt.Errorf("set seniority: %w", err)
+	}
+	return u, teamName, nil
+}
+
+func (r *PgUserRepository) SetTags(ctx context.Context, userID string, tags []string) (models.User, string, error) {
+	return r.updateTags(ctx, `$2::text[]`, userID, tags)
+}
+
+func (r *PgUserRepository) AddTags(ctx context.Context, userID string, tags []string) (models.User, string, error) {
+	return r.updateTags(ctx, `ARRAY(
+			SELECT DISTINCT t FROM unnest(u.tags || $2::text[]) AS t ORDER BY t
+		)`, userID, tags)
+}
+
+func (r *PgUserRepository) RemoveTags(ctx context.Context, userID string, tags []string) (models.User, string, error) {
+	return r.updateTags(ctx, `ARRAY(
+			SELECT t FROM unnest(u.tags) AS t WHERE t <> ALL($2::text[]) ORDER BY t
+		)`, userID, tags)
+}
+
+func (r *PgUserRepository) updateTags(
+	ctx context.Context,
+	expr string,
+	userID string,
+	tags []string) (models.User, string, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE users u
+		SET tags = `+expr+`
+		FROM teams t
+		WHERE u.team_id = t.id AND u.user_id = $1 AND u.organization_id = $3 AND u.deleted_at IS NULL
+		RETURNING u.id, u.user_id, u.name, u.team_id, u.is_active, u.tags, u.seniority, t.name
+	`, userID, tags, orgID(ctx))
+
+	var u models.User
+	var teamName string
+	if err := row.Scan(&u.ID, &u.UserID, &u.Name, &u.TeamID, &u.IsActive, &u.Tags, &u.Seniority, &teamName); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.User{}, "", ErrNotFound
+		}
+		return models.User{}, "", fmt.Errorf("update tags: %w", err)
+	}
+	return u, teamName, nil
+}
+
+func (r *PgUserRepository) GetOpenReviewLoad(ctx context.Context, userIDs []int64) (map[int64]int, error) {
+	const q = `
+        SELECT prr.reviewer_id, COUNT(*)
+        FROM pr_reviews prr
+        JOIN pull_requests pr ON pr.id = prr.pr_id
+        WHERE pr.status = 'OPEN'
+          AND pr.organization_id = $2
+          AND pr.deleted_at IS NULL
+          AND prr.reviewer_id = ANY($1)
+        GROUP BY prr.reviewer_id
+    `
+	rows, err := conn(ctx, r.pool).Query(ctx, q, userIDs, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get open review load: %w", err)
+	}
+	defer rows.Close()
+
+	load := make(map[int64]int, len(userIDs))
+	for rows.Next() {
+		var id int64
+		var cnt int
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, fmt.Errorf("scan review load: %w", err)
+		}
+		load[id] = cnt
+	}
+	return load, rows.Err()
+}
+
+// GetPairHistory counts how many PRs of the author each reviewer was assigned
+// to since the given moment.
+func (r *PgUserRepository) GetPairHistory(
+	ctx context.Context,
+	authorID int64,
+	reviewerIDs []int64,
+	since time.Time) (map[int64]int, error) {
+	const q = `
+        SELECT prr.reviewer_id, COUNT(*)
+        FROM pr_reviews prr
+        JOIN pull_requests pr ON pr.id = prr.pr_id
+        WHERE pr.author_id = $1
+          AND pr.organization_id = $4
+          AND pr.deleted_at IS NULL
+          AND prr.reviewer_id = ANY($2)
+          AND prr.assigned_at >= $3
+        GROUP BY prr.reviewer_id
+    `
+	rows, err := conn(ctx, r.pool).Query(ctx, q, authorID, reviewerIDs, since, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get pair history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[int64]int, len(reviewerIDs))
+	for rows.Next() {
+		var id int64
+		var cnt int
+		if err := rows.Scan(&id, &cnt); err != nil {
+			return nil, fmt.Errorf("scan pair history: %w", err)
+		}
+		history[id] = cnt
+	}
+	return history, rows.Err()
+}
+
 func (r *PgUserRepository) GetWithTeam(ctx context.Context, userID string) (models.User, string, error) {
-	row := r.pool.QueryRow(ctx, `
+	row := conn(ctx, r.pool).QueryRow(ctx, `
 		SELECT u.id, u.user_id, u.name, u.team_id, u.is_active, t.name
 		FROM users u
 		JOIN teams t ON t.id = u.team_id
-		WHERE u.user_id = $1
-	`, userID)
+		WHERE u.user_id = $1 AND u.organization_id = $2
+	`, userID, orgID(ctx))
 
 	var u models.User
 	var teamName string
@@ -81,15 +221,15 @@ func (r *PgUserRepository) GetWithTeam(ctx context.Context, userID string) (mode
 }
 
 func (r *PgUserRepository) GetReviewPullRequests(ctx context.Context, userID string) ([]dtos.ReviewPullRequest, error) {
-	rows, err := r.pool.Query(ctx, `
+	rows, err := conn(ctx, r.pool).Query(ctx, `
 		SELECT pr.pr_id, pr.title, author.user_id, pr.status::text
 		FROM pr_reviews rr
 		JOIN pull_requests pr ON pr.id = rr.pr_id
 		JOIN users reviewer ON reviewer.id = rr.reviewer_id
 		LEFT JOIN users author ON author.id = pr.author_id
-		WHERE reviewer.user_id = $1
+		WHERE reviewer.user_id = $1 AND reviewer.organization_id = $2
 		ORDER BY pr.id
-	`, userID)
+	`, userID, orgID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -116,13 +256,13 @@ func (r *PgUserRepository) GetReviewPullRequests(ctx context.Context, userID str
 
 func (r *PgUserRepository) GetByUserID(ctx context.Context, userID string) (models.User, error) {
 	const q = `
-     SELECT u.id, u.user_id, u.name, u.is_active, u.team_id
+     SELECT u.id, u.user_id, u.name, u.is_active, u.team_id, u.tags, u.seniority
 	 FROM users u
-     WHERE u.user_id = $1
+     WHERE u.user_id = $1 AND u.organization_id = $2
 	`
 
 	var u models.User
-	err := r.pool.QueryRow(ctx, q, userID).Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID)
+	err := conn(ctx, r.pool).QueryRow(ctx, q, userID, orgID(ctx)).Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -135,11 +275,12 @@ func (r *PgUserRepository) GetByUserID(ctx context.Context, userID string) (mode
 
 func (r *PgUserRepository) GetTeamMembers(ctx context.Context, teamID int64) ([]models.User, error) {
 	const q = `
-     SELECT id, user_id, name, is_active, team_id
+     SELECT id, user_id, name, is_active, team_id, tags, seniority
      FROM users
-     WHERE team_id = $1
+     WHERE team_id = $1 AND organization_id = $2
+     ORDER BY id
     `
-	rows, err := r.pool.Query(ctx, q, teamID)
+	rows, err := conn(ctx, r.pool).Query(ctx, q, teamID, orgID(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +289,7 @@ func (r *PgUserRepository) GetTeamMembers(ctx context.Context, teamID int64) ([]
 	var memebers []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID); err != nil {
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority); err != nil {
 			return nil, err
 		}
 		memebers = append(memebers, u)
@@ -156,6 +297,62 @@ func (r *PgUserRepository) GetTeamMembers(ctx context.Context, teamID int64) ([]
 	return memebers, rows.Err()
 }
 
+func (r *PgUserRepository) GetByUserIDs(ctx context.Context, userIDs []string) ([]models.User, error) {
+	const q = `
+     SELECT id, user_id, name, is_active, team_id, tags, seniority
+     FROM users
+     WHERE user_id = ANY($1) AND organization_id = $2 AND deleted_at IS NULL
+     ORDER BY id
+    `
+	rows, err := conn(ctx, r.pool).Query(ctx, q, userIDs, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("get users by ids: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// GetMembersByTeamNames returns members of the named teams and, for every team,
+// the user_id values of its members.
+func (r *PgUserRepository) GetMembersByTeamNames(
+	ctx context.Context,
+	teamNames []string) ([]models.User, map[string][]string, error) {
+	const q = `
+     SELECT u.id, u.user_id, u.name, u.is_active, u.team_id, u.tags, u.seniority, t.name
+     FROM users u
+     JOIN teams t ON t.id = u.team_id
+     WHERE t.name = ANY($1) AND t.organization_id = $2 AND t.deleted_at IS NULL AND u.deleted_at IS NULL
+     ORDER BY u.id
+    `
+	rows, err := conn(ctx, r.pool).Query(ctx, q, teamNames, orgID(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get members by team names: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	byTeam := make(map[string][]string)
+	for rows.Next() {
+		var u models.User
+		var teamName string
+		if err := rows.Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority, &teamName); err != nil {
+			return nil, nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+		byTeam[teamName] = append(byTeam[teamName], u.UserID)
+	}
+	return users, byTeam, rows.Err()
+}
+
 func (r *PgUserRepository) GetReviewerSlot(
 	ctx context.Context,
 	prInternalID int64,
@@ -166,7 +363,7 @@ func (r *PgUserRepository) GetReviewerSlot(
 	  WHERE pr_id = $1 AND reviewer_id = $2
 	 `
 	var slot int
-	err := r.pool.QueryRow(ctx, q, prInternalID, reviewerInternalID).Scan(&slot)
+	err := conn(ctx, r.pool).QueryRow(ctx, q, prInternalID, reviewerInternalID).Scan(&slot)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, ErrNotFound
@@ -178,12 +375,12 @@ func (r *PgUserRepository) GetReviewerSlot(
 
 func (r *PgUserRepository) GetByInternalID(ctx context.Context, internalID int64) (models.User, error) {
 	const q = `
-        SELECT id, user_id, name, is_active, team_id
+        SELECT id, user_id, name, is_active, team_id, tags, seniority
         FROM users
-        WHERE id = $1 AND deleted_at IS NULL
+        WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
     `
 	var u models.User
-	err := r.pool.QueryRow(ctx, q, internalID).Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID)
+	err := conn(ctx, r.pool).QueryRow(ctx, q, internalID, orgID(ctx)).Scan(&u.ID, &u.UserID, &u.Name, &u.IsActive, &u.TeamID, &u.Tags, &u.Seniority)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return models.User{}, ErrNotFound
@@ -193,30 +390,50 @@ func (r *PgUserRepository) GetByInternalID(ctx context.Context, internalID int64
 	return u, nil
 }
 
-func (r *PgUserRepository) BulkDeactivate(ctx context.Context, teamID int64, userIDs []string) ([]int64, error) {
+// BulkDeactivate deactivates the team members and reports the is_active flag
+// each of them had before.
+func (r *PgUserRepository) BulkDeactivate(
+	ctx context.Context,
+	teamID int64,
+	userIDs []string) ([]models.BulkUserFlip, error) {
 	const q = `
-        UPDATE users
+        UPDATE users u
         SET is_active = FALSE
-        WHERE team_id = $1
-          AND user_id = ANY($2)
-          AND deleted_at IS NULL
-        RETURNING id
+        FROM users prev
+        WHERE prev.id = u.id
+          AND u.team_id = $1
+          AND u.user_id = ANY($2)
+          AND u.organization_id = $3
+          AND u.deleted_at IS NULL
+        RETURNING u.id, u.user_id, prev.is_active
     `
-	rows, err := r.pool.Query(ctx, q, teamID, userIDs)
+	rows, err := conn(ctx, r.pool).Query(ctx, q, teamID, userIDs, orgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("bulk deactivate: %w", err)
 	}
 	defer rows.Close()
 
-	var ids []int64
+	var flips []models.BulkUserFlip
 	for rows.Next() {
-		var id int64
-		if err := rows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("scan deactivated id: %w", err)
+		var f models.BulkUserFlip
+		if err := rows.Scan(&f.UserID, &f.ExtUserID, &f.WasActive); err != nil {
+			return nil, fmt.Errorf("scan deactivated user: %w", err)
+		}
+		flips = append(flips, f)
+	}
+	return flips, rows.Err()
+}
+
+func (r *PgUserRepository) RestoreActive(ctx context.Context, flips []models.BulkUserFlip) error {
+	for _, f := range flips {
+		_, err := conn(ctx, r.pool).Exec(ctx, `
+			UPDATE users SET is_active = $2 WHERE id = $1 AND organization_id = $3
+		`, f.UserID, f.WasActive, orgID(ctx))
+		if err != nil {
+			return fmt.Errorf("restore is_active: %w", err)
 		}
-		ids = append(ids, id)
 	}
-	return ids, rows.Err()
+	return nil
 }
 
 func (r *PgUserRepository) GetActiveForReassignment(
@@ -227,12 +444,13 @@ func (r *PgUserRepository) GetActiveForReassignment(
         SELECT id, user_id, name, team_id, is_active
         FROM users
         WHERE team_id = $1
+          AND organization_id = $3
           AND is_active = TRUE
           AND deleted_at IS NULL
           AND id != ALL($2)
-        ORDER BY random()
+        ORDER BY id
     `
-	rows, err := r.pool.Query(ctx, q, teamID, excludeUserIDs)
+	rows, err := conn(ctx, r.pool).Query(ctx, q, teamID, excludeUserIDs, orgID(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("get active for reassignment: %w", err)
 	}
@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	Enqueue(ctx context.Context, event string, payload []byte) error
+	ListDeliveries(ctx context.Context, f models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error)
+	ClaimDelivery(ctx context.Context, lease time.Duration) (models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int) error
+	MarkFailed(ctx context.Context, id int64, dead bool, retryIn time.Duration, responseStatus *int, errMsg string) error
+	Redeliver(ctx context.Context, id int64) (models.WebhookDelivery, error)
+}
+
+type pgWebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) WebhookRepository {
+	return &pgWebhookRepository{pool: pool}
+}
+
+const deliveryColumns = `
+	d.id, d.subscription_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_error,
+	d.response_status, d.created_at, d.delivered_at
+`
+
+func scanDelivery(row pgx.Row, extra ...any) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	dest := append([]any{&d.ID, &d.SubscriptionID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.LastError, &d.ResponseStatus, &d.CreatedAt, &d.DeliveredAt}, extra...)
+	err := row.Scan(dest...)
+	return d, err
+}
+
+func (r *pgWebhookRepository) CreateSubscription(
+	ctx context.Context,
+	sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (organization_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, orgID(ctx), sub.URL, sub.Secret, sub.Events).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("create webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (r *pgWebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT id, url, events, created_at
+		FROM webhook_subscriptions
+		WHERE organization_id = $1 AND deleted_at IS NULL
+		ORDER BY id
+	`, orgID(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		var s models.WebhookSubscription
+		if err := rows.Scan(&s.ID, &s.URL, &s.Events, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan webhook subscription: %w", err)
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// DeleteSubscription removes the subscription and gives up on its pending
+// deliveries.
+func (r *pgWebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	tx, err := beginTx(ctx, r.pool)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	res, err := tx.Exec(ctx, `
+		UPDATE webhook_subscriptions
+		SET deleted_at = NOW()
+		WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL
+	`, id, orgID(ctx))
+	if err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'DEAD', last_error = 'subscription deleted'
+		WHERE subscription_id = $1 AND status = 'PENDING'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("drop pending deliveries: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Enqueue queues the event for every subscription of the organization that
+// listens to it. It writes through ctx, so the deliveries are only sent if
+// the surrounding transaction commits.
+func (r *pgWebhookRepository) Enqueue(ctx context.Context, event string, payload []byte) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event, payload)
+		SELECT id, $2, $3
+		FROM webhook_subscriptions
+		WHERE organization_id = $1 AND $2 = ANY(events) AND deleted_at IS NULL
+	`, orgID(ctx), event, payload)
+	if err != nil {
+		return fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *pgWebhookRepository) ListDeliveries(
+	ctx context.Context,
+	f models.WebhookDeliveryFilter) ([]models.WebhookDelivery, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE s.organization_id = $1 AND d.id > $2
+			AND ($3 = 0 OR d.subscription_id = $3)
+			AND ($4 = '' OR d.status = $4)
+		ORDER BY d.id
+		LIMIT $5
+	`, orgID(ctx), f.AfterID, f.SubscriptionID, f.Status, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// ClaimDelivery takes the oldest due delivery of any organization, counts the
+// attempt and hides the delivery for lease, so an attempt cut short by a
+// restart is retried once the lease runs out.
+func (r *pgWebhookRepository) ClaimDelivery(ctx context.Context, lease time.Duration) (models.WebhookDelivery, error) {
+	var url, secret string
+	d, err := scanDelivery(conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = NOW() + $1 * INTERVAL '1 second'
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id = (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+deliveryColumns+`, s.url, s.secret
+	`, int64(lease.Seconds())), &url, &secret)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDelivery{}, ErrNotFound
+		}
+		return models.WebhookDelivery{}, fmt.Errorf("claim webhook delivery: %w", err)
+	}
+	d.URL, d.Secret = url, secret
+	return d, nil
+}
+
+func (r *pgWebhookRepository) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'DELIVERED', delivered_at = NOW(), response_status = $2, last_error = ''
+		WHERE id = $1
+	`, id, responseStatus)
+	if err != nil {
+		return fmt.Errorf("mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed schedules the next attempt in retryIn, or gives up on the
+// delivery when dead is set.
+func (r *pgWebhookRepository) MarkFailed(
+	ctx context.Context,
+	id int64,
+	dead bool,
+	retryIn time.Duration,
+	responseStatus *int,
+	errMsg string) error {
+	status := models.DeliveryPending
+	if dead {
+		status = models.DeliveryDead
+	}
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, next_attempt_at = NOW() + $3 * INTERVAL '1 second', response_status = $4, last_error = $5
+		WHERE id = $1
+	`, id, status, int64(retryIn.Seconds()), responseStatus, errMsg)
+	if err != nil {
+		return fmt.Errorf("mark webhook failed: %w", err)
+	}
+	return nil
+}
+
+// Redeliver queues the delivery again with a fresh set of attempts, whatever
+// its current status.
+func (r *pgWebhookRepository) Redeliver(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	d, err := scanDelivery(conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE webhook_deliveries d
+		SET status = 'PENDING', attempts = 0, next_attempt_at = NOW(), last_error = '', delivered_at = NULL
+		FROM webhook_subscriptions s
+		WHERE d.id = $1 AND s.id = d.subscription_id AND s.organization_id = $2 AND s.deleted_at IS NULL
+		RETURNING `+deliveryColumns, id, orgID(ctx)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WebhookDelivery{}, ErrNotFound
+		}
+		return models.WebhookDelivery{}, fmt.Errorf("redeliver webhook: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+)
+
+// AccessControl checks the caller's role in a team. Calls without a
+// principal (background jobs) and the bootstrap token, which has no user,
+// are not restricted.
+type AccessControl struct {
+	roles repositories.TeamRoleRepository
+}
+
+func NewAccessControl(roles repositories.TeamRoleRepository) *AccessControl {
+	return &AccessControl{roles: roles}
+}
+
+// RequireTeamRole fails with FORBIDDEN unless the caller holds at least
+// minRole in the team.
+func (a *AccessControl) RequireTeamRole(ctx context.Context, teamID int64, minRole string) error {
+	p, ok := PrincipalFrom(ctx)
+	if !ok || p.UserID == 0 {
+		return nil
+	}
+	role, err := a.roles.GetRole(ctx, teamID, p.UserID)
+	if err != nil {
+		return err
+	}
+	if models.TeamRoleRank[role] < models.TeamRoleRank[minRole] {
+		return derr.New(derr.CodeForbidden, "requires "+minRole+" role in the team")
+	}
+	return nil
+}
+
+// RequireSelfOrTeamRole lets userID act on their own behalf and otherwise
+// falls back to RequireTeamRole.
+func (a *AccessControl) RequireSelfOrTeamRole(ctx context.Context, userID, teamID int64, minRole string) error {
+	if p, ok := PrincipalFrom(ctx); ok && p.UserID != 0 && p.UserID == userID {
+		return nil
+	}
+	return a.RequireTeamRole(ctx, teamID, minRole)
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditService struct {
+	repo repositories.AuditRepository
+}
+
+func NewAuditService(repo repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record writes an audit entry through ctx, so it commits or rolls back
+// together with the mutation it describes. The actor and request ID are
+// taken from ctx.
+func (s *AuditService) Record(ctx context.Context, action, entityType, entityID string, before, after any) error {
+	e := models.AuditEntry{
+		Actor:      models.ActorSystem,
+		RequestID:  RequestIDFrom(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	if p, ok := PrincipalFrom(ctx); ok {
+		e.Actor = p.ExtUserID
+		if e.Actor == "" {
+			e.Actor = models.ActorBootstrap
+		}
+		if p.TokenID != 0 {
+			e.TokenID = &p.TokenID
+		}
+	}
+
+	var err error
+	if e.Before, err = marshalAuditState(before); err != nil {
+		return err
+	}
+	if e.After, err = marshalAuditState(after); err != nil {
+		return err
+	}
+	return s.repo.Insert(ctx, e)
+}
+
+func (s *AuditService) List(ctx context.Context, q dtos.AuditQuery) (dtos.AuditListResponse, error) {
+	f, err := auditFilter(q)
+	if err != nil {
+		return dtos.AuditListResponse{}, err
+	}
+	entries, err := s.repo.List(ctx, f)
+	if err != nil {
+		return dtos.AuditListResponse{}, err
+	}
+
+	resp := dtos.AuditListResponse{Entries: make([]dtos.AuditEntryDTO, 0, len(entries))}
+	for _, e := range entries {
+		resp.Entries = append(resp.Entries, mapAuditEntryToDTO(e))
+	}
+	if len(entries) == f.Limit {
+		resp.NextAfterID = entries[len(entries)-1].ID
+	}
+	return resp, nil
+}
+
+// Export streams every entry matching q to w as JSON lines or CSV. The limit
+// of q is ignored.
+func (s *AuditService) Export(ctx context.Context, q dtos.AuditQuery, w io.Writer) error {
+	q.Limit = maxAuditLimit
+	f, err := auditFilter(q)
+	if err != nil {
+		return err
+	}
+
+	var write func(models.AuditEntry) error
+	var flush func() error
+	switch q.Format {
+	case "", "jsonl":
+		enc := json.NewEncoder(w)
+		write = func(e models.AuditEntry) error { return enc.Encode(mapAuditEntryToDTO(e)) }
+		flush = func() error { return nil }
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"id", "created_at", "actor", "token_id", "request_id", "action",
+			"entity_type", "entity_id", "before", "after"}); err != nil {
+			return err
+		}
+		write = func(e models.AuditEntry) error {
+			tokenID := ""
+			if e.TokenID != nil {
+				tokenID = strconv.FormatInt(*e.TokenID, 10)
+			}
+			return cw.Write([]string{strconv.FormatInt(e.ID, 10), e.CreatedAt.Format(time.RFC3339), e.Actor, tokenID,
+				e.RequestID, e.Action, e.EntityType, e.EntityID, string(e.Before), string(e.After)})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return derr.New(derr.CodeValidation, "format must be jsonl or csv")
+	}
+
+	for {
+		entries, err := s.repo.List(ctx, f)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := write(e); err != nil {
+				return err
+			}
+		}
+		if len(entries) < f.Limit {
+			return flush()
+		}
+		f.AfterID = entries[len(entries)-1].ID
+	}
+}
+
+func auditFilter(q dtos.AuditQuery) (models.AuditFilter, error) {
+	limit := q.Limit
+	switch {
+	case limit == 0:
+		limit = defaultAuditLimit
+	case limit < 0 || limit > maxAuditLimit:
+		return models.AuditFilter{}, derr.New(derr.CodeValidation, "limit must be between 1 and 1000")
+	}
+	if q.AfterID < 0 {
+		return models.AuditFilter{}, derr.New(derr.CodeValidation, "after_id must not be negative")
+	}
+	return models.AuditFilter{
+		Actor:      q.Actor,
+		Action:     q.Action,
+		EntityType: q.EntityType,
+		EntityID:   q.EntityID,
+		RequestID:  q.RequestID,
+		Since:      q.Since,
+		Until:      q.Until,
+		AfterID:    q.AfterID,
+		Limit:      limit,
+	}, nil
+}
+
+func marshalAuditState(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func mapAuditEntryToDTO(e models.AuditEntry) dtos.AuditEntryDTO {
+	return dtos.AuditEntryDTO{
+		ID:         e.ID,
+		Actor:      e.Actor,
+		TokenID:    e.TokenID,
+		RequestID:  e.RequestID,
+		Action:     e.Action,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		Before:     e.Before,
+		After:      e.After,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID attaches the ID of the HTTP request to ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
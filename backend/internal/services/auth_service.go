@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+const tokenPrefix = "prs_"
+
+var bootstrapScopes = append(slices.Clone(models.Scopes), models.ScopeOrgsAdmin)
+
+type AuthService interface {
+	Authenticate(ctx context.Context, secret, org string) (models.Principal, error)
+	IssueToken(ctx context.Context, req dtos.IssueTokenRequest) (dtos.IssueTokenResponse, error)
+	RevokeToken(ctx context.Context, req dtos.RevokeTokenRequest) error
+	ListTokens(ctx context.Context) (dtos.APITokenListResponse, error)
+}
+
+type authService struct {
+	repo      repositories.APITokenRepository
+	userRepo  repositories.UserRepository
+	orgRepo   repositories.OrganizationRepository
+	txManager *repositories.TxManager
+	audit     *AuditService
+	validator validators.AuthValidator
+	// bootstrapSecret authenticates with every scope and no user, so the
+	// first tokens can be issued. Empty disables it.
+	bootstrapSecret string
+	// oidc validates bearer JWTs; nil when OIDC is not configured.
+	oidc *OIDCVerifier
+}
+
+func NewAuthService(
+	repo repositories.APITokenRepository,
+	userRepo repositories.UserRepository,
+	orgRepo repositories.OrganizationRepository,
+	txManager *repositories.TxManager,
+	audit *AuditService,
+	validator validators.AuthValidator,
+	bootstrapSecret string,
+	oidc *OIDCVerifier) AuthService {
+	return &authService{
+		repo:            repo,
+		userRepo:        userRepo,
+		orgRepo:         orgRepo,
+		txManager:       txManager,
+		audit:           audit,
+		validator:       validator,
+		bootstrapSecret: bootstrapSecret,
+		oidc:            oidc,
+	}
+}
+
+// Authenticate resolves the caller and the organization it acts in. API tokens
+// and JWTs are bound to one organization, which org (the slug from the
+// X-Organization header) may only confirm. The bootstrap token acts in org, or
+// in the default organization when org is empty.
+func (s *authService) Authenticate(ctx context.Context, secret, org string) (models.Principal, error) {
+	if secret == "" {
+		return models.Principal{}, derr.New(derr.CodeUnauthorized, "missing bearer token")
+	}
+	if s.bootstrapSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.bootstrapSecret)) == 1 {
+		orgID := models.DefaultOrganizationID
+		if org != "" {
+			o, err := s.organization(ctx, org)
+			if err != nil {
+				return models.Principal{}, err
+			}
+			orgID = o.ID
+		}
+		return models.Principal{OrganizationID: orgID, Scopes: bootstrapScopes}, nil
+	}
+
+	var p models.Principal
+	var err error
+	if s.oidc != nil && LooksLikeJWT(secret) {
+		p, err = s.authenticateJWT(ctx, secret)
+	} else {
+		p, err = s.authenticateToken(ctx, secret)
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+	if org != "" {
+		o, err := s.organization(ctx, org)
+		if err != nil {
+			return models.Principal{}, err
+		}
+		if o.ID != p.OrganizationID {
+			return models.Principal{}, derr.New(derr.CodeForbidden, "token belongs to another organization")
+		}
+	}
+	return p, nil
+}
+
+func (s *authService) authenticateToken(ctx context.Context, secret string) (models.Principal, error) {
+	token, err := s.repo.GetActiveByHash(ctx, hashToken(secret))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return models.Principal{}, derr.New(derr.CodeUnauthorized, "invalid or revoked token")
+		}
+		return models.Principal{}, err
+	}
+	return models.Principal{
+		TokenID:        token.ID,
+		UserID:         token.UserID,
+		ExtUserID:      token.ExtUserID,
+		OrganizationID: token.OrganizationID,
+		Scopes:         token.Scopes,
+	}, nil
+}
+
+func (s *authService) authenticateJWT(ctx context.Context, raw string) (models.Principal, error) {
+	identity, err := s.oidc.Verify(ctx, raw)
+	if err != nil {
+		if errors.Is(err, errInvalidJWT) {
+			return models.Principal{}, derr.New(derr.CodeUnauthorized, err.Error())
+		}
+		return models.Principal{}, err
+	}
+
+	org, err := s.organization(ctx, identity.Organization)
+	if err != nil {
+		return models.Principal{}, err
+	}
+	user, err := s.userRepo.GetByUserID(repositories.WithOrganization(ctx, org.ID), identity.UserID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return models.Principal{}, derr.New(derr.CodeUnauthorized, "unknown user "+identity.UserID)
+		}
+		return models.Principal{}, err
+	}
+	return models.Principal{
+		UserID:         user.ID,
+		ExtUserID:      user.UserID,
+		OrganizationID: org.ID,
+		Scopes:         identity.Scopes,
+	}, nil
+}
+
+func (s *authService) organization(ctx context.Context, slug string) (models.Organization, error) {
+	org, err := s.orgRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return models.Organization{}, derr.New(derr.CodeUnauthorized, "unknown organization "+slug)
+		}
+		return models.Organization{}, err
+	}
+	return org, nil
+}
+
+func (s *authService) IssueToken(ctx context.Context, req dtos.IssueTokenRequest) (dtos.IssueTokenResponse, error) {
+	if err := s.validator.ValidateIssue(ctx, req); err != nil {
+		return dtos.IssueTokenResponse{}, err
+	}
+
+	user, err := s.userRepo.GetByUserID(ctx, strings.TrimSpace(req.UserID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return dtos.IssueTokenResponse{}, derr.New(derr.CodeNotFound, "user not found")
+		}
+		return dtos.IssueTokenResponse{}, err
+	}
+
+	secret, err := newTokenSecret()
+	if err != nil {
+		return dtos.IssueTokenResponse{}, err
+	}
+	var out dtos.APITokenDTO
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		token, err := s.repo.Create(ctx, models.APIToken{
+			UserID:    user.ID,
+			ExtUserID: user.UserID,
+			Name:      strings.TrimSpace(req.Name),
+			Scopes:    req.Scopes,
+		}, hashToken(secret))
+		if err != nil {
+			return err
+		}
+		out = mapAPITokenToDTO(token)
+		return s.audit.Record(ctx, models.AuditTokenIssue, models.AuditEntityToken,
+			strconv.FormatInt(out.TokenID, 10), nil, out)
+	})
+	if err != nil {
+		return dtos.IssueTokenResponse{}, err
+	}
+	return dtos.IssueTokenResponse{Token: out, Secret: secret}, nil
+}
+
+func (s *authService) RevokeToken(ctx context.Context, req dtos.RevokeTokenRequest) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		tokens, err := s.repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		var before any
+		if i := slices.IndexFunc(tokens, func(t models.APIToken) bool { return t.ID == req.TokenID }); i >= 0 {
+			before = mapAPITokenToDTO(tokens[i])
+		}
+		if err := s.repo.Revoke(ctx, req.TokenID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "token not found or already revoked")
+			}
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditTokenRevoke, models.AuditEntityToken,
+			strconv.FormatInt(req.TokenID, 10), before, nil)
+	})
+}
+
+func (s *authService) ListTokens(ctx context.Context) (dtos.APITokenListResponse, error) {
+	tokens, err := s.repo.List(ctx)
+	if err != nil {
+		return dtos.APITokenListResponse{}, err
+	}
+	out := make([]dtos.APITokenDTO, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, mapAPITokenToDTO(t))
+	}
+	return dtos.APITokenListResponse{Tokens: out}, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches the authenticated caller to ctx and scopes
+// repository calls to the caller's organization.
+func WithPrincipal(ctx context.Context, p models.Principal) context.Context {
+	if p.OrganizationID != 0 {
+		ctx = repositories.WithOrganization(ctx, p.OrganizationID)
+	}
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the caller attached by WithPrincipal.
+func PrincipalFrom(ctx context.Context) (models.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(models.Principal)
+	return p, ok
+}
+
+func newTokenSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return tokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func mapAPITokenToDTO(t models.APIToken) dtos.APITokenDTO {
+	return dtos.APITokenDTO{
+		TokenID:   t.ID,
+		UserID:    t.ExtUserID,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		CreatedAt: t.CreatedAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
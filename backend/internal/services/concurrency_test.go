@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/testdb"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+type testServices struct {
+	prs   PRService
+	teams TeamService
+	users UserService
+	repos RepositoryService
+	audit *AuditService
+}
+
+// newTestServices wires the domain services the way main does, without event
+// sinks and background workers.
+func newTestServices(pool *pgxpool.Pool) testServices {
+	txManager := repositories.NewTxManager(pool)
+	roleRepo := repositories.NewTeamRoleRepository(pool)
+	access := NewAccessControl(roleRepo)
+	audit := NewAuditService(repositories.NewAuditRepository(pool))
+	outbox := NewOutbox(repositories.NewOutboxRepository(pool), nil, time.Second)
+
+	userRepo := repositories.NewPgUserRepository(pool)
+	repoRepo := repositories.NewRepoRepository(pool)
+	groupRepo := repositories.NewGroupRepository(pool)
+	teamRepo := repositories.NewPgTeamRepository(pool)
+	prRepo := repositories.NewPRRepository(pool)
+	eventRepo := repositories.NewAssignmentEventRepository(pool)
+	jobs := NewJobService(repositories.NewJobRepository(pool), time.Second)
+
+	return testServices{
+		users: NewUserService(userRepo, txManager, access, audit, outbox, validators.NewUserValidator()),
+		repos: NewRepositoryService(repoRepo, txManager, audit, validators.NewRepositoryValidator()),
+		audit: audit,
+		prs: NewPRService(prRepo, userRepo, repoRepo, groupRepo, teamRepo, eventRepo, txManager, access, audit,
+			outbox, validators.NewPRValidator(prRepo, userRepo, repoRepo), 0, 0, RandomSeeds()),
+		teams: NewTeamService(teamRepo, userRepo, prRepo, groupRepo, eventRepo,
+			repositories.NewBulkOperationRepository(pool), roleRepo, txManager, jobs, access, audit, outbox,
+			validators.NewTeamValidator(teamRepo), 0, RandomSeeds()),
+	}
+}
+
+// retryConflicts runs fn until it stops failing with CodeConflict.
+func retryConflicts(fn func() error) error {
+	for {
+		err := fn()
+		if de, ok := derr.IsDomain(err); !ok || de.Code != derr.CodeConflict {
+			return err
+		}
+	}
+}
+
+// TestReassignAndBulkDeactivateConcurrently races single reassignments with
+// a bulk deactivation of the same team. Both go through the PR row locks, so
+// every PR must end up with two distinct reviewers in two distinct slots.
+func TestReassignAndBulkDeactivateConcurrently(t *testing.T) {
+	pool := testdb.New(t)
+	ctx := repositories.WithOrganization(context.Background(), models.DefaultOrganizationID)
+	svc := newTestServices(pool)
+
+	const members, prs = 12, 30
+	team := dtos.AddTeamRequest{TeamName: "backend"}
+	for i := 1; i <= members; i++ {
+		id := fmt.Sprintf("u%d", i)
+		team.Members = append(team.Members, dtos.TeamMemberDTO{UserID: id, Username: id, IsActive: true})
+	}
+	if _, err := svc.teams.AddTeam(ctx, team); err != nil {
+		t.Fatalf("add team: %v", err)
+	}
+
+	reviewers := make(map[string][]string, prs)
+	for i := 1; i <= prs; i++ {
+		resp, err := svc.prs.Create(ctx, dtos.CreatePRRequest{
+			PullRequestID: fmt.Sprintf("pr-%d", i),
+			Title:         "change",
+			Author:        fmt.Sprintf("u%d", i%members+1),
+		})
+		if err != nil {
+			t.Fatalf("create pr: %v", err)
+		}
+		reviewers[resp.PR.PullRequestID] = resp.PR.AssignedReviewers
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, prs*2+1)
+	start := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		errs <- retryConflicts(func() error {
+			_, err := svc.teams.BulkDeactivate(ctx, dtos.BulkDeactivateRequest{
+				TeamName: "backend",
+				UserIDs:  []string{"u1", "u2", "u3", "u4"},
+			})
+			return err
+		})
+	}()
+	for prID, ids := range reviewers {
+		for _, old := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				err := retryConflicts(func() error {
+					_, err := svc.prs.Reassign(ctx, dtos.ReassignRequest{PullRequestID: prID, OldUserID: old})
+					return err
+				})
+				// The bulk operation may have replaced the reviewer first.
+				if de, ok := derr.IsDomain(err); ok && de.Code == derr.CodePRMerged {
+					err = nil
+				}
+				errs <- err
+			}()
+		}
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call: %v", err)
+		}
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT pr.pr_id, COUNT(*), COUNT(DISTINCT prr.reviewer_id), COUNT(DISTINCT prr.slot),
+			COUNT(*) FILTER (WHERE prr.reviewer_id = pr.author_id)
+		FROM pull_requests pr
+		LEFT JOIN pr_reviews prr ON prr.pr_id = pr.id
+		GROUP BY pr.pr_id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var seen int
+	for rows.Next() {
+		var prID string
+		var total, distinctReviewers, distinctSlots, byAuthor int
+		if err := rows.Scan(&prID, &total, &distinctReviewers, &distinctSlots, &byAuthor); err != nil {
+			t.Fatal(err)
+		}
+		seen++
+		if total != reviewersPerPR || distinctReviewers != total || distinctSlots != total || byAuthor != 0 {
+			t.Errorf("%s: %d reviewers, %d distinct, %d slots, %d by author",
+				prID, total, distinctReviewers, distinctSlots, byAuthor)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if seen != prs {
+		t.Fatalf("checked %d PRs, want %d", seen, prs)
+	}
+}
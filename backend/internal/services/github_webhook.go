@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"strconv"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+// githubPullRequestEvent is the part of the pull_request webhook payload the
+// service uses.
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		ID      int64  `json:"id"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		Draft   bool   `json:"draft"`
+		Merged  bool   `json:"merged"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"pull_request"`
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// GitHub handles a pull_request webhook. PRs are identified as gh-<id> by the
+// GitHub PR id, and the PR author's login is mapped to a user_id through the
+// identity table; PRs by unmapped accounts are ignored. Draft PRs are created
+// once they are marked ready for review. edited and synchronize copy the PR
+// metadata, as GitLab's update does. Closing without merge is ignored, since
+// the service has no closed state.
+func (s *integrationService) GitHub(ctx context.Context, in dtos.InboundWebhook) (dtos.IntegrationResponse, error) {
+	if s.cfg.GitHubSecret == "" || !hmac.Equal([]byte(in.Signature), []byte(SignWebhook(s.cfg.GitHubSecret, in.Body))) {
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeUnauthorized, "invalid signature")
+	}
+	if in.Event != "pull_request" {
+		return ignored("event " + in.Event + " is not handled"), nil
+	}
+
+	var ev githubPullRequestEvent
+	if err := json.Unmarshal(in.Body, &ev); err != nil || ev.PullRequest.ID == 0 {
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeValidation, "invalid pull_request payload")
+	}
+	prID := "gh-" + strconv.FormatInt(ev.PullRequest.ID, 10)
+
+	return s.process(ctx, models.ProviderGitHub, in, func(ctx context.Context) (dtos.IntegrationResponse, error) {
+		switch ev.Action {
+		case "opened", "reopened", "ready_for_review":
+			if ev.PullRequest.Draft {
+				return ignored("draft pull request"), nil
+			}
+			login := ev.PullRequest.User.Login
+			author, ok, err := s.resolveUser(ctx, models.ProviderGitHub, login)
+			if err != nil {
+				return dtos.IntegrationResponse{}, err
+			}
+			if !ok {
+				return unmapped(models.ProviderGitHub, login), nil
+			}
+			return s.open(ctx, githubCreateRequest(prID, author, ev), ev.Repository.FullName, ev.Repository.Name)
+		case "edited", "synchronize":
+			if ev.PullRequest.Draft {
+				return ignored("draft pull request"), nil
+			}
+			return s.update(ctx, githubCreateRequest(prID, "", ev))
+		case "closed":
+			if !ev.PullRequest.Merged {
+				return ignored("closed without merge"), nil
+			}
+			return s.merge(ctx, prID)
+		default:
+			return ignored("action " + ev.Action + " is not handled"), nil
+		}
+	})
+}
+
+func githubCreateRequest(prID, author string, ev githubPullRequestEvent) dtos.CreatePRRequest {
+	pr := ev.PullRequest
+	labels := make([]string, 0, len(pr.Labels))
+	for _, l := range pr.Labels {
+		labels = append(labels, l.Name)
+	}
+	return dtos.CreatePRRequest{
+		PullRequestID: prID,
+		Title:         pr.Title,
+		Author:        author,
+		SourceBranch:  pr.Head.Ref,
+		TargetBranch:  pr.Base.Ref,
+		Description:   pr.Body,
+		URL:           pr.HTMLURL,
+		Labels:        labels,
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/testdb"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+const testGitHubSecret = "github-secret"
+
+func newTestIntegrations(pool *pgxpool.Pool, prs PRService) IntegrationService {
+	return NewIntegrationService(prs, repositories.NewIntegrationRepository(pool),
+		repositories.NewRepoRepository(pool), repositories.NewOrganizationRepository(pool),
+		repositories.NewIdentityRepository(pool), repositories.NewTxManager(pool),
+		NewAuditService(repositories.NewAuditRepository(pool)), validators.NewIntegrationValidator(),
+		IntegrationConfig{
+			Organization: models.DefaultOrganizationSlug,
+			GitHubSecret: testGitHubSecret,
+			GitLabToken:  testGitLabToken,
+		})
+}
+
+// githubDelivery loads a recorded pull_request payload from testdata/github
+// and signs it the way GitHub does.
+func githubDelivery(t *testing.T, fixture, deliveryID string) dtos.InboundWebhook {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "github", fixture+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dtos.InboundWebhook{
+		Event:      "pull_request",
+		DeliveryID: deliveryID,
+		Signature:  SignWebhook(testGitHubSecret, body),
+		Body:       body,
+	}
+}
+
+// TestReplayGitHubDeliveries replays recorded GitHub deliveries for two PRs,
+// one merged and one closed without merge, including edits, a redelivery and
+// a PR by an account without an identity mapping.
+func TestReplayGitHubDeliveries(t *testing.T) {
+	pool := testdb.New(t)
+	ctx := repositories.WithOrganization(context.Background(), models.DefaultOrganizationID)
+	svc := newTestServices(pool)
+	integrations := newTestIntegrations(pool, svc.prs)
+
+	team := dtos.AddTeamRequest{TeamName: "backend"}
+	for i := 1; i <= 4; i++ {
+		id := fmt.Sprintf("u%d", i)
+		team.Members = append(team.Members, dtos.TeamMemberDTO{UserID: id, Username: id, IsActive: true})
+	}
+	if _, err := svc.teams.AddTeam(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	for login, userID := range map[string]string{"octocat": "u1", "monalisa": "u2"} {
+		_, err := integrations.SetIdentity(ctx, dtos.SetIdentityRequest{
+			Provider: models.ProviderGitHub, Username: login, UserID: userID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		fixture  string
+		delivery string
+		want     dtos.IntegrationResponse
+	}{
+		{"opened", "d-1", dtos.IntegrationResponse{Result: IntegrationCreated, PullRequestID: "gh-1001"}},
+		// GitHub redelivers with the same X-GitHub-Delivery.
+		{"opened", "d-1", dtos.IntegrationResponse{Result: IntegrationDuplicate}},
+		{"edited", "d-7", dtos.IntegrationResponse{Result: IntegrationUpdated, PullRequestID: "gh-1001"}},
+		{"synchronize", "d-8", dtos.IntegrationResponse{Result: IntegrationUpdated, PullRequestID: "gh-1001"}},
+		{"opened_draft", "d-2", ignored("draft pull request")},
+		{"ready_for_review", "d-3", dtos.IntegrationResponse{Result: IntegrationCreated, PullRequestID: "gh-1002"}},
+		{"closed_merged", "d-4", dtos.IntegrationResponse{Result: IntegrationMerged, PullRequestID: "gh-1001"}},
+		{"closed_merged", "d-4", dtos.IntegrationResponse{Result: IntegrationDuplicate}},
+		{"edited", "d-9", ignored("pull request already merged")},
+		{"closed_unmerged", "d-5", ignored("closed without merge")},
+		{"opened_unmapped", "d-6", ignored("unmapped github account ghost")},
+	}
+	for i, step := range steps {
+		got, err := integrations.GitHub(context.Background(), githubDelivery(t, step.fixture, step.delivery))
+		if err != nil {
+			t.Fatalf("step %d (%s, %s): %v", i, step.fixture, step.delivery, err)
+		}
+		if got != step.want {
+			t.Fatalf("step %d (%s, %s) = %+v, want %+v", i, step.fixture, step.delivery, got, step.want)
+		}
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT pr.pr_id, pr.title, pr.status, pr.author_id = u.id, COUNT(DISTINCT e.id)
+		FROM pull_requests pr
+		JOIN users u ON u.user_id = $1
+		LEFT JOIN assignment_events e ON e.pr_id = pr.id
+		GROUP BY pr.pr_id, pr.title, pr.status, pr.author_id, u.id
+		ORDER BY pr.pr_id
+	`, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type prState struct {
+		title      string
+		status     string
+		byU1       bool
+		assignings int
+	}
+	got := map[string]prState{}
+	for rows.Next() {
+		var prID string
+		var st prState
+		if err := rows.Scan(&prID, &st.title, &st.status, &st.byU1, &st.assignings); err != nil {
+			t.Fatal(err)
+		}
+		got[prID] = st
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]prState{
+		"gh-1001": {title: "Show reviewer load in stats", status: "MERGED", byU1: true, assignings: 1},
+		"gh-1002": {title: "Retry webhook deliveries with backoff", status: "OPEN", byU1: false, assignings: 1},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("pull requests = %v, want %v", got, want)
+	}
+}
+
+func TestGitHubRejectsBadSignature(t *testing.T) {
+	s := &integrationService{cfg: IntegrationConfig{GitHubSecret: testGitHubSecret}}
+	in := githubDelivery(t, "opened", "d-1")
+	in.Body = append([]byte(" "), in.Body...)
+	if _, err := s.GitHub(context.Background(), in); err == nil {
+		t.Fatal("tampered body accepted")
+	}
+}
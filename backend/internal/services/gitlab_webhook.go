@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"strconv"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+const gitlabMergeRequestHook = "Merge Request Hook"
+
+// gitlabMergeRequestEvent is the part of the Merge Request Hook payload the
+// service uses.
+type gitlabMergeRequestEvent struct {
+	User struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		Name              string `json:"name"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		ID             int64  `json:"id"`
+		Title          string `json:"title"`
+		Description    string `json:"description"`
+		SourceBranch   string `json:"source_branch"`
+		TargetBranch   string `json:"target_branch"`
+		URL            string `json:"url"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+	Changes struct {
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
+}
+
+func (e gitlabMergeRequestEvent) draft() bool {
+	return e.ObjectAttributes.Draft || e.ObjectAttributes.WorkInProgress
+}
+
+func (e gitlabMergeRequestEvent) labels() []string {
+	out := make([]string, 0, len(e.Labels))
+	for _, l := range e.Labels {
+		out = append(out, l.Title)
+	}
+	return out
+}
+
+// GitLab handles a Merge Request Hook. MRs are identified as gl-<id> by the
+// GitLab MR id, and the user who opens an MR becomes its author, mapped to a
+// user_id through the identity table. Drafts are created once they are
+// marked ready; closing without merge is ignored.
+func (s *integrationService) GitLab(ctx context.Context, in dtos.InboundWebhook) (dtos.IntegrationResponse, error) {
+	if s.cfg.GitLabToken == "" || subtle.ConstantTimeCompare([]byte(in.Signature), []byte(s.cfg.GitLabToken)) != 1 {
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeUnauthorized, "invalid token")
+	}
+	if in.Event != gitlabMergeRequestHook {
+		return ignored("event " + in.Event + " is not handled"), nil
+	}
+
+	var ev gitlabMergeRequestEvent
+	if err := json.Unmarshal(in.Body, &ev); err != nil || ev.ObjectAttributes.ID == 0 {
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeValidation, "invalid merge request payload")
+	}
+	prID := "gl-" + strconv.FormatInt(ev.ObjectAttributes.ID, 10)
+
+	return s.process(ctx, models.ProviderGitLab, in, func(ctx context.Context) (dtos.IntegrationResponse, error) {
+		switch ev.ObjectAttributes.Action {
+		case "open", "reopen":
+			if ev.draft() {
+				return ignored("draft merge request"), nil
+			}
+			return s.openGitLab(ctx, prID, ev)
+		case "update":
+			if d := ev.Changes.Draft; d != nil && d.Previous && !d.Current {
+				return s.openGitLab(ctx, prID, ev)
+			}
+			if ev.draft() {
+				return ignored("draft merge request"), nil
+			}
+			return s.update(ctx, gitlabCreateRequest(prID, "", ev))
+		case "merge":
+			return s.merge(ctx, prID)
+		case "close":
+			return ignored("closed without merge"), nil
+		default:
+			return ignored("action " + ev.ObjectAttributes.Action + " is not handled"), nil
+		}
+	})
+}
+
+func (s *integrationService) openGitLab(
+	ctx context.Context,
+	prID string,
+	ev gitlabMergeRequestEvent) (dtos.IntegrationResponse, error) {
+	author, ok, err := s.resolveUser(ctx, models.ProviderGitLab, ev.User.Username)
+	if err != nil {
+		return dtos.IntegrationResponse{}, err
+	}
+	if !ok {
+		return unmapped(models.ProviderGitLab, ev.User.Username), nil
+	}
+	return s.open(ctx, gitlabCreateRequest(prID, author, ev), ev.Project.PathWithNamespace, ev.Project.Name)
+}
+
+func gitlabCreateRequest(prID, author string, ev gitlabMergeRequestEvent) dtos.CreatePRRequest {
+	mr := ev.ObjectAttributes
+	return dtos.CreatePRRequest{
+		PullRequestID: prID,
+		Title:         mr.Title,
+		Author:        author,
+		SourceBranch:  mr.SourceBranch,
+		TargetBranch:  mr.TargetBranch,
+		Description:   mr.Description,
+		URL:           mr.URL,
+		Labels:        ev.labels(),
+	}
+}
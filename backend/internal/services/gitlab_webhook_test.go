@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/testdb"
+)
+
+const testGitLabToken = "gitlab-token"
+
+// gitlabDelivery loads a recorded Merge Request Hook payload from
+// testdata/gitlab with the token GitLab sends.
+func gitlabDelivery(t *testing.T, fixture, deliveryID string) dtos.InboundWebhook {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "gitlab", fixture+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dtos.InboundWebhook{
+		Event:      gitlabMergeRequestHook,
+		DeliveryID: deliveryID,
+		Signature:  testGitLabToken,
+		Body:       body,
+	}
+}
+
+// TestReplayGitLabDeliveries replays recorded GitLab deliveries for one MR
+// that is edited, closed, reopened and merged, a draft that is later marked
+// ready, and an MR the service never saw.
+func TestReplayGitLabDeliveries(t *testing.T) {
+	pool := testdb.New(t)
+	ctx := repositories.WithOrganization(context.Background(), models.DefaultOrganizationID)
+	svc := newTestServices(pool)
+	integrations := newTestIntegrations(pool, svc.prs)
+
+	team := dtos.AddTeamRequest{TeamName: "backend"}
+	for i := 1; i <= 4; i++ {
+		id := fmt.Sprintf("u%d", i)
+		team.Members = append(team.Members, dtos.TeamMemberDTO{UserID: id, Username: id, IsActive: true})
+	}
+	if _, err := svc.teams.AddTeam(ctx, team); err != nil {
+		t.Fatal(err)
+	}
+	for username, userID := range map[string]string{"alice": "u1", "bob": "u2"} {
+		_, err := integrations.SetIdentity(ctx, dtos.SetIdentityRequest{
+			Provider: models.ProviderGitLab, Username: username, UserID: userID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		fixture  string
+		delivery string
+		want     dtos.IntegrationResponse
+	}{
+		{"open", "g-1", dtos.IntegrationResponse{Result: IntegrationCreated, PullRequestID: "gl-2001"}},
+		// GitLab redelivers with the same X-Gitlab-Event-UUID.
+		{"open", "g-1", dtos.IntegrationResponse{Result: IntegrationDuplicate}},
+		{"update", "g-2", dtos.IntegrationResponse{Result: IntegrationUpdated, PullRequestID: "gl-2001"}},
+		{"close", "g-3", ignored("closed without merge")},
+		{"reopen", "g-4", dtos.IntegrationResponse{Result: IntegrationExists, PullRequestID: "gl-2001"}},
+		{"open_draft", "g-5", ignored("draft merge request")},
+		{"update_draft", "g-6", ignored("draft merge request")},
+		{"update_ready", "g-7", dtos.IntegrationResponse{Result: IntegrationCreated, PullRequestID: "gl-2002"}},
+		{"update_unknown", "g-8", ignored("unknown pull request")},
+		{"merge", "g-9", dtos.IntegrationResponse{Result: IntegrationMerged, PullRequestID: "gl-2001"}},
+		{"merge", "g-9", dtos.IntegrationResponse{Result: IntegrationDuplicate}},
+		{"update", "g-10", ignored("pull request already merged")},
+	}
+	for i, step := range steps {
+		got, err := integrations.GitLab(context.Background(), gitlabDelivery(t, step.fixture, step.delivery))
+		if err != nil {
+			t.Fatalf("step %d (%s, %s): %v", i, step.fixture, step.delivery, err)
+		}
+		if got != step.want {
+			t.Fatalf("step %d (%s, %s) = %+v, want %+v", i, step.fixture, step.delivery, got, step.want)
+		}
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT pr.pr_id, pr.title, pr.status, u.user_id
+		FROM pull_requests pr
+		JOIN users u ON u.id = pr.author_id
+		ORDER BY pr.pr_id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type prState struct {
+		title  string
+		status string
+		author string
+	}
+	got := map[string]prState{}
+	for rows.Next() {
+		var prID string
+		var st prState
+		if err := rows.Scan(&prID, &st.title, &st.status, &st.author); err != nil {
+			t.Fatal(err)
+		}
+		got[prID] = st
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]prState{
+		"gl-2001": {title: "Cache team lookups per organization", status: "MERGED", author: "u1"},
+		"gl-2002": {title: "Export audit log as CSV and JSON", status: "OPEN", author: "u2"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("pull requests = %v, want %v", got, want)
+	}
+}
+
+func TestGitLabRejectsBadToken(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		configured string
+		sent       string
+	}{
+		{"wrong token", testGitLabToken, "guess"},
+		{"missing token", testGitLabToken, ""},
+		{"not configured", "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &integrationService{cfg: IntegrationConfig{GitLabToken: tc.configured}}
+			in := gitlabDelivery(t, "open", "g-1")
+			in.Signature = tc.sent
+			_, err := s.GitLab(context.Background(), in)
+			wantCode(t, err, derr.CodeUnauthorized)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+type GroupService interface {
+	Add(ctx context.Context, in dtos.AddReviewerGroupRequest) (dtos.ReviewerGroupResponse, error)
+	Get(ctx context.Context, name string) (dtos.ReviewerGroupResponse, error)
+	List(ctx context.Context) (dtos.ReviewerGroupListResponse, error)
+	SetMembers(ctx context.Context, in dtos.SetGroupMembersRequest) (dtos.ReviewerGroupResponse, error)
+	Delete(ctx context.Context, in dtos.DeleteReviewerGroupRequest) error
+	AddRule(ctx context.Context, in dtos.AddGroupRuleRequest) (dtos.GroupRuleResponse, error)
+	DeleteRule(ctx context.Context, in dtos.DeleteGroupRuleRequest) error
+}
+
+type groupService struct {
+	repo      repositories.GroupRepository
+	txManager *repositories.TxManager
+	audit     *AuditService
+	validator validators.GroupValidator
+}
+
+func NewGroupService(
+	repo repositories.GroupRepository,
+	txManager *repositories.TxManager,
+	audit *AuditService,
+	validator validators.GroupValidator) GroupService {
+	return &groupService{repo: repo, txManager: txManager, audit: audit, validator: validator}
+}
+
+func (s *groupService) Add(ctx context.Context, in dtos.AddReviewerGroupRequest) (dtos.ReviewerGroupResponse, error) {
+	if err := s.validator.ValidateAdd(ctx, in); err != nil {
+		return dtos.ReviewerGroupResponse{}, err
+	}
+
+	var out dtos.ReviewerGroupDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.repo.Create(ctx, models.ReviewerGroup{
+			Name:        strings.TrimSpace(in.Name),
+			Description: in.Description,
+			Members:     in.Members,
+		})
+		if err != nil {
+			return err
+		}
+		out = mapGroupToDTO(created)
+		return s.audit.Record(ctx, models.AuditGroupCreate, models.AuditEntityGroup, out.Name, nil, out)
+	})
+	if err != nil {
+		return dtos.ReviewerGroupResponse{}, mapGroupError(err)
+	}
+
+	return dtos.ReviewerGroupResponse{Group: out}, nil
+}
+
+func (s *groupService) Get(ctx context.Context, name string) (dtos.ReviewerGroupResponse, error) {
+	if err := s.validator.ValidateName(ctx, name); err != nil {
+		return dtos.ReviewerGroupResponse{}, err
+	}
+
+	g, err := s.repo.GetByName(ctx, strings.TrimSpace(name))
+	if err != nil {
+		return dtos.ReviewerGroupResponse{}, mapGroupError(err)
+	}
+
+	return dtos.ReviewerGroupResponse{Group: mapGroupToDTO(g)}, nil
+}
+
+func (s *groupService) List(ctx context.Context) (dtos.ReviewerGroupListResponse, error) {
+	groups, err := s.repo.List(ctx)
+	if err != nil {
+		return dtos.ReviewerGroupListResponse{}, derr.New(derr.CodeInternal, "internal error")
+	}
+
+	out := make([]dtos.ReviewerGroupDTO, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, mapGroupToDTO(g))
+	}
+	return dtos.ReviewerGroupListResponse{Groups: out}, nil
+}
+
+func (s *groupService) SetMembers(
+	ctx context.Context,
+	in dtos.SetGroupMembersRequest) (dtos.ReviewerGroupResponse, error) {
+	if err := s.validator.ValidateSetMembers(ctx, in); err != nil {
+		return dtos.ReviewerGroupResponse{}, err
+	}
+
+	name := strings.TrimSpace(in.Name)
+	var out dtos.ReviewerGroupDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.repo.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SetMembers(ctx, name, in.Members); err != nil {
+			return err
+		}
+		after, err := s.repo.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		out = mapGroupToDTO(after)
+		return s.audit.Record(ctx, models.AuditGroupSetMembers, models.AuditEntityGroup, name,
+			mapGroupToDTO(before), out)
+	})
+	if err != nil {
+		return dtos.ReviewerGroupResponse{}, mapGroupError(err)
+	}
+
+	return dtos.ReviewerGroupResponse{Group: out}, nil
+}
+
+func (s *groupService) Delete(ctx context.Context, in dtos.DeleteReviewerGroupRequest) error {
+	if err := s.validator.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		g, err := s.repo.GetByName(ctx, strings.TrimSpace(in.Name))
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, g.Name); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditGroupDelete, models.AuditEntityGroup, g.Name, mapGroupToDTO(g), nil)
+	})
+	if err != nil {
+		return mapGroupError(err)
+	}
+	return nil
+}
+
+func (s *groupService) AddRule(ctx context.Context, in dtos.AddGroupRuleRequest) (dtos.GroupRuleResponse, error) {
+	if err := s.validator.ValidateAddRule(ctx, in); err != nil {
+		return dtos.GroupRuleResponse{}, err
+	}
+
+	var out dtos.GroupRuleDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rule, err := s.repo.AddRule(ctx, strings.TrimSpace(in.GroupName), models.GroupRule{
+			Label:        strings.ToLower(strings.TrimSpace(in.Label)),
+			TitlePattern: in.TitlePattern,
+		})
+		if err != nil {
+			return err
+		}
+		out = mapGroupRuleToDTO(rule)
+		return s.audit.Record(ctx, models.AuditGroupAddRule, models.AuditEntityGroupRule,
+			strconv.FormatInt(out.RuleID, 10), nil, out)
+	})
+	if err != nil {
+		return dtos.GroupRuleResponse{}, mapGroupError(err)
+	}
+
+	return dtos.GroupRuleResponse{Rule: out}, nil
+}
+
+func (s *groupService) DeleteRule(ctx context.Context, in dtos.DeleteGroupRuleRequest) error {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		rules, err := s.repo.ListRules(ctx)
+		if err != nil {
+			return err
+		}
+		var before any
+		if i := slices.IndexFunc(rules, func(r models.GroupRule) bool { return r.ID == in.RuleID }); i >= 0 {
+			before = mapGroupRuleToDTO(rules[i])
+		}
+		if err := s.repo.DeleteRule(ctx, in.RuleID); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditGroupDeleteRule, models.AuditEntityGroupRule,
+			strconv.FormatInt(in.RuleID, 10), before, nil)
+	})
+	if err != nil {
+		return mapGroupError(err)
+	}
+	return nil
+}
+
+func mapGroupError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrGroupExists):
+		return derr.New(derr.CodeGroupExists, "reviewer group already exists")
+	case errors.Is(err, repositories.ErrNotFound):
+		return derr.New(derr.CodeNotFound, "resource not found")
+	default:
+		return derr.New(derr.CodeInternal, "internal error")
+	}
+}
+
+func mapGroupToDTO(g models.ReviewerGroup) dtos.ReviewerGroupDTO {
+	members := g.Members
+	if members == nil {
+		members = []string{}
+	}
+	rules := make([]dtos.GroupRuleDTO, 0, len(g.Rules))
+	for _, r := range g.Rules {
+		rules = append(rules, mapGroupRuleToDTO(r))
+	}
+	return dtos.ReviewerGroupDTO{
+		Name:        g.Name,
+		Description: g.Description,
+		Members:     members,
+		Rules:       rules,
+	}
+}
+
+func mapGroupRuleToDTO(r models.GroupRule) dtos.GroupRuleDTO {
+	return dtos.GroupRuleDTO{
+		RuleID:       r.ID,
+		GroupName:    r.GroupName,
+		Label:        r.Label,
+		TitlePattern: r.TitlePattern,
+	}
+}
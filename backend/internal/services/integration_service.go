@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"unicode/utf8"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+const (
+	IntegrationCreated   = "created"
+	IntegrationUpdated   = "updated"
+	IntegrationMerged    = "merged"
+	IntegrationExists    = "exists"
+	IntegrationDuplicate = "duplicate"
+	IntegrationIgnored   = "ignored"
+)
+
+// PR fields from external systems are cut to what CreatePRRequest accepts.
+const (
+	integrationMaxTitle       = 100
+	integrationMaxDescription = 10000
+	integrationMaxLabels      = 20
+	integrationMaxLabel       = 50
+)
+
+// IntegrationService turns webhooks of code hosting systems into PRService
+// calls.
+type IntegrationService interface {
+	GitHub(ctx context.Context, in dtos.InboundWebhook) (dtos.IntegrationResponse, error)
+	GitLab(ctx context.Context, in dtos.InboundWebhook) (dtos.IntegrationResponse, error)
+	SetIdentity(ctx context.Context, req dtos.SetIdentityRequest) (dtos.IdentityResponse, error)
+	DeleteIdentity(ctx context.Context, req dtos.DeleteIdentityRequest) error
+	ListIdentities(ctx context.Context, q dtos.IdentityQuery) (dtos.IdentityListResponse, error)
+}
+
+type IntegrationConfig struct {
+	// Organization is the slug of the organization inbound webhooks act in.
+	Organization string
+	// GitHubSecret verifies X-Hub-Signature-256; GitHub webhooks are refused
+	// while it is empty.
+	GitHubSecret string
+	// GitLabToken is compared with X-Gitlab-Token; GitLab webhooks are
+	// refused while it is empty.
+	GitLabToken string
+}
+
+type integrationService struct {
+	prs        PRService
+	repo       repositories.IntegrationRepository
+	repoRepo   repositories.RepoRepository
+	orgRepo    repositories.OrganizationRepository
+	identities repositories.IdentityRepository
+	txManager  *repositories.TxManager
+	audit      *AuditService
+	validator  validators.IntegrationValidator
+	cfg        IntegrationConfig
+}
+
+func NewIntegrationService(
+	prs PRService,
+	repo repositories.IntegrationRepository,
+	repoRepo repositories.RepoRepository,
+	orgRepo repositories.OrganizationRepository,
+	identities repositories.IdentityRepository,
+	txManager *repositories.TxManager,
+	audit *AuditService,
+	validator validators.IntegrationValidator,
+	cfg IntegrationConfig) IntegrationService {
+	return &integrationService{
+		prs:        prs,
+		repo:       repo,
+		repoRepo:   repoRepo,
+		orgRepo:    orgRepo,
+		identities: identities,
+		txManager:  txManager,
+		audit:      audit,
+		validator:  validator,
+		cfg:        cfg,
+	}
+}
+
+// process runs fn in the configured organization, at most once per delivery
+// ID. The delivery is recorded in the same transaction as the changes fn
+// makes, so a failed delivery is processed again when it is redelivered.
+func (s *integrationService) process(
+	ctx context.Context,
+	provider string,
+	in dtos.InboundWebhook,
+	fn func(ctx context.Context) (dtos.IntegrationResponse, error)) (dtos.IntegrationResponse, error) {
+	if in.DeliveryID == "" || len(in.DeliveryID) > 100 {
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeValidation, "invalid delivery id")
+	}
+	org, err := s.orgRepo.GetBySlug(ctx, s.cfg.Organization)
+	if err != nil {
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeInternal, "internal error")
+	}
+	ctx = repositories.WithOrganization(ctx, org.ID)
+
+	var resp dtos.IntegrationResponse
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		fresh, err := s.repo.RecordDelivery(ctx, provider, in.DeliveryID, in.Event)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			resp = dtos.IntegrationResponse{Result: IntegrationDuplicate}
+			return nil
+		}
+		resp, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		if _, ok := derr.IsDomain(err); ok {
+			return dtos.IntegrationResponse{}, err
+		}
+		if errors.Is(err, repositories.ErrConflict) {
+			return dtos.IntegrationResponse{}, conflictError()
+		}
+		return dtos.IntegrationResponse{}, derr.New(derr.CodeInternal, "internal error")
+	}
+	return resp, nil
+}
+
+// open creates the PR. The repository is set to the first of repoNames that
+// is registered in the service.
+func (s *integrationService) open(
+	ctx context.Context,
+	req dtos.CreatePRRequest,
+	repoNames ...string) (dtos.IntegrationResponse, error) {
+	req.Repository = s.knownRepository(ctx, repoNames...)
+	req.Title = truncate(req.Title, integrationMaxTitle)
+	req.Description = truncate(req.Description, integrationMaxDescription)
+	req.Labels = integrationLabels(req.Labels)
+
+	_, err := s.prs.Create(ctx, req)
+	if de, ok := derr.IsDomain(err); ok && de.Code == derr.CodePRExists {
+		return dtos.IntegrationResponse{Result: IntegrationExists, PullRequestID: req.PullRequestID}, nil
+	}
+	if err != nil {
+		return dtos.IntegrationResponse{}, err
+	}
+	return dtos.IntegrationResponse{Result: IntegrationCreated, PullRequestID: req.PullRequestID}, nil
+}
+
+// update copies the metadata in req onto the PR; the author cannot change.
+// PRs the service never saw and merged PRs are left alone.
+func (s *integrationService) update(ctx context.Context, req dtos.CreatePRRequest) (dtos.IntegrationResponse, error) {
+	title := truncate(req.Title, integrationMaxTitle)
+	description := truncate(req.Description, integrationMaxDescription)
+	labels := integrationLabels(req.Labels)
+	_, err := s.prs.Update(ctx, dtos.UpdatePRRequest{
+		PullRequestID: req.PullRequestID,
+		Title:         &title,
+		SourceBranch:  &req.SourceBranch,
+		TargetBranch:  &req.TargetBranch,
+		Description:   &description,
+		URL:           &req.URL,
+		Labels:        &labels,
+	})
+	if de, ok := derr.IsDomain(err); ok {
+		switch de.Code {
+		case derr.CodeNotFound:
+			return ignored("unknown pull request"), nil
+		case derr.CodePRMerged:
+			return ignored("pull request already merged"), nil
+		}
+	}
+	if err != nil {
+		return dtos.IntegrationResponse{}, err
+	}
+	return dtos.IntegrationResponse{Result: IntegrationUpdated, PullRequestID: req.PullRequestID}, nil
+}
+
+// merge marks the PR merged. PRs the service never saw are ignored.
+func (s *integrationService) merge(ctx context.Context, pullRequestID string) (dtos.IntegrationResponse, error) {
+	_, err := s.prs.Merge(ctx, dtos.MergePRRequest{PullRequestID: pullRequestID})
+	if de, ok := derr.IsDomain(err); ok && de.Code == derr.CodeNotFound {
+		return ignored("unknown pull request"), nil
+	}
+	if err != nil {
+		return dtos.IntegrationResponse{}, err
+	}
+	return dtos.IntegrationResponse{Result: IntegrationMerged, PullRequestID: pullRequestID}, nil
+}
+
+func (s *integrationService) knownRepository(ctx context.Context, names ...string) string {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, err := s.repoRepo.GetByName(ctx, name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+func (s *integrationService) SetIdentity(
+	ctx context.Context,
+	req dtos.SetIdentityRequest) (dtos.IdentityResponse, error) {
+	if err := s.validator.ValidateSetIdentity(ctx, req); err != nil {
+		return dtos.IdentityResponse{}, err
+	}
+	var out dtos.IdentityDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.identityState(ctx, req.Provider, req.Username)
+		if err != nil {
+			return err
+		}
+		id, err := s.identities.Set(ctx, req.Provider, req.Username, req.UserID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "user not found")
+			}
+			return err
+		}
+		out = mapIdentityToDTO(id)
+		return s.audit.Record(ctx, models.AuditIdentitySet, models.AuditEntityIdentity,
+			identityEntityID(req.Provider, req.Username), before, map[string]string{"user_id": out.UserID})
+	})
+	if err != nil {
+		return dtos.IdentityResponse{}, err
+	}
+	return dtos.IdentityResponse{Identity: out}, nil
+}
+
+func (s *integrationService) DeleteIdentity(ctx context.Context, req dtos.DeleteIdentityRequest) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.identityState(ctx, req.Provider, req.Username)
+		if err != nil {
+			return err
+		}
+		if err := s.identities.Delete(ctx, req.Provider, req.Username); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "identity not found")
+			}
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditIdentityDelete, models.AuditEntityIdentity,
+			identityEntityID(req.Provider, req.Username), before, nil)
+	})
+}
+
+// identityState is the audit snapshot of a mapping, nil when there is none.
+func (s *integrationService) identityState(ctx context.Context, provider, username string) (any, error) {
+	userID, err := s.identities.Resolve(ctx, provider, username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"user_id": userID}, nil
+}
+
+func identityEntityID(provider, username string) string {
+	return provider + ":" + username
+}
+
+func (s *integrationService) ListIdentities(
+	ctx context.Context,
+	q dtos.IdentityQuery) (dtos.IdentityListResponse, error) {
+	ids, err := s.identities.List(ctx, q.Provider)
+	if err != nil {
+		return dtos.IdentityListResponse{}, err
+	}
+	out := make([]dtos.IdentityDTO, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, mapIdentityToDTO(id))
+	}
+	return dtos.IdentityListResponse{Identities: out}, nil
+}
+
+// resolveUser maps an account of provider to a user_id through the identity
+// mappings. ok is false for unmapped accounts: a username is not trusted to
+// be a user_id, so their events are ignored.
+func (s *integrationService) resolveUser(
+	ctx context.Context,
+	provider, username string) (userID string, ok bool, err error) {
+	userID, err = s.identities.Resolve(ctx, provider, username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return userID, true, nil
+}
+
+func mapIdentityToDTO(id models.ExternalIdentity) dtos.IdentityDTO {
+	return dtos.IdentityDTO{
+		Provider:  id.Provider,
+		Username:  id.Username,
+		UserID:    id.UserID,
+		CreatedAt: id.CreatedAt,
+	}
+}
+
+func ignored(reason string) dtos.IntegrationResponse {
+	return dtos.IntegrationResponse{Result: IntegrationIgnored, Reason: reason}
+}
+
+func unmapped(provider, username string) dtos.IntegrationResponse {
+	return ignored("unmapped " + provider + " account " + username)
+}
+
+func integrationLabels(labels []string) []string {
+	out := make([]string, 0, min(len(labels), integrationMaxLabels))
+	for _, l := range labels {
+		if len(out) == integrationMaxLabels {
+			break
+		}
+		if l != "" && utf8.RuneCountInString(l) <= integrationMaxLabel {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
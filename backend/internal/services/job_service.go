@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+)
+
+// JobProgress receives the progress of a running job.
+type JobProgress interface {
+	SetTotal(total int)
+	Record(item any)
+}
+
+type nopProgress struct{}
+
+func (nopProgress) SetTotal(int) {}
+func (nopProgress) Record(any)   {}
+
+// JobFunc executes one job of a registered kind. The returned value is stored
+// as the job result.
+type JobFunc func(ctx context.Context, payload []byte, progress JobProgress) (any, error)
+
+type JobService interface {
+	Register(kind string, fn JobFunc)
+	Enqueue(ctx context.Context, kind string, payload any) (dtos.JobDTO, error)
+	Get(ctx context.Context, id int64) (dtos.JobDTO, error)
+	Start(ctx context.Context) error
+}
+
+type jobService struct {
+	repo         repositories.JobRepository
+	pollInterval time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]JobFunc
+	wake     chan struct{}
+}
+
+func NewJobService(repo repositories.JobRepository, pollInterval time.Duration) JobService {
+	return &jobService{
+		repo:         repo,
+		pollInterval: pollInterval,
+		handlers:     make(map[string]JobFunc),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func (s *jobService) Register(kind string, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = fn
+}
+
+func (s *jobService) Enqueue(ctx context.Context, kind string, payload any) (dtos.JobDTO, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return dtos.JobDTO{}, err
+	}
+	job, err := s.repo.Create(ctx, kind, raw)
+	if err != nil {
+		return dtos.JobDTO{}, err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return mapJobToDTO(job), nil
+}
+
+func (s *jobService) Get(ctx context.Context, id int64) (dtos.JobDTO, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return dtos.JobDTO{}, derr.New(derr.CodeNotFound, "job not found")
+		}
+		return dtos.JobDTO{}, err
+	}
+	return mapJobToDTO(job), nil
+}
+
+// Start requeues jobs interrupted by a previous shutdown and runs the worker
+// until ctx is cancelled.
+func (s *jobService) Start(ctx context.Context) error {
+	n, err := s.repo.RequeueRunning(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("requeued %d interrupted jobs", n)
+	}
+	go s.work(ctx)
+	return nil
+}
+
+func (s *jobService) work(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		job, err := s.repo.ClaimNext(ctx)
+		if err == nil {
+			s.run(ctx, job)
+			continue
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			log.Printf("claim job: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *jobService) run(ctx context.Context, job models.Job) {
+	ctx = repositories.WithOrganization(ctx, job.OrganizationID)
+	progress := &jobProgress{ctx: ctx, repo: s.repo, id: job.ID}
+	result, err := s.execute(ctx, job, progress)
+
+	// A failed job body is rolled back, so its items describe changes that
+	// were never applied and are dropped.
+	items := []byte("[]")
+	if err == nil {
+		items = progress.itemsJSON()
+	}
+
+	status, errMsg := models.JobSucceeded, ""
+	var raw []byte
+	if err != nil {
+		status, errMsg = models.JobFailed, err.Error()
+	} else if raw, err = json.Marshal(result); err != nil {
+		status, errMsg = models.JobFailed, err.Error()
+	}
+	if err := s.repo.Finish(ctx, job.ID, status, raw, items, errMsg); err != nil {
+		log.Printf("finish job %d: %v", job.ID, err)
+	}
+}
+
+func (s *jobService) execute(ctx context.Context, job models.Job, progress JobProgress) (result any, err error) {
+	s.mu.RLock()
+	fn, ok := s.handlers[job.Kind]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx, job.Payload, progress)
+}
+
+// jobProgress writes the counters through its own context, so they are seen
+// while the job body's transaction is still open. Items are only buffered and
+// stored when the job finishes.
+type jobProgress struct {
+	ctx   context.Context
+	repo  repositories.JobRepository
+	id    int64
+	items []json.RawMessage
+}
+
+func (p *jobProgress) SetTotal(total int) {
+	if err := p.repo.SetTotal(p.ctx, p.id, total); err != nil {
+		log.Printf("job %d: %v", p.id, err)
+	}
+}
+
+func (p *jobProgress) Record(item any) {
+	raw, err := json.Marshal(item)
+	if err == nil {
+		p.items = append(p.items, raw)
+		err = p.repo.Advance(p.ctx, p.id)
+	}
+	if err != nil {
+		log.Printf("job %d: %v", p.id, err)
+	}
+}
+
+func (p *jobProgress) itemsJSON() []byte {
+	if len(p.items) == 0 {
+		return []byte("[]")
+	}
+	raw, err := json.Marshal(p.items)
+	if err != nil {
+		return []byte("[]")
+	}
+	return raw
+}
+
+func mapJobToDTO(job models.Job) dtos.JobDTO {
+	items := job.Items
+	if len(items) == 0 {
+		items = []byte("[]")
+	}
+	return dtos.JobDTO{
+		JobID:  job.ID,
+		Kind:   job.Kind,
+		Status: job.Status,
+		Progress: dtos.JobProgressDTO{
+			Done:  job.Done,
+			Total: job.Total,
+		},
+		Items:      items,
+		Result:     job.Result,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
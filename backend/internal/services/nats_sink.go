@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+const (
+	natsDefaultPort = "4222"
+	natsTimeout     = 10 * time.Second
+	// natsMaxLine bounds the protocol lines read from the server; INFO is the
+	// longest of them.
+	natsMaxLine = 64 << 10
+)
+
+// NATSConfig configures NATSSink.
+type NATSConfig struct {
+	// URL is nats://host[:port] or tls://host[:port]. Credentials go in the
+	// user info: user:password, or a token alone.
+	URL           string
+	SubjectPrefix string
+	// CAFile is a PEM bundle to verify the server with instead of the system
+	// roots.
+	CAFile string
+}
+
+// NATSSink publishes every event to a NATS server on the subject
+// "<prefix>.<event>". It speaks the NATS text protocol over one connection,
+// upgraded to TLS for tls:// URLs or when the server requires it, and follows
+// each PUB with a PING, so a nil error means the server has processed the
+// message. The connection is opened on first use and again after any error.
+type NATSSink struct {
+	addr   string
+	user   *url.Userinfo
+	prefix string
+	tls    *tls.Config
+	// requireTLS is set for tls:// URLs; otherwise TLS is used only when the
+	// server asks for it.
+	requireTLS bool
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// natsMessage is the body of a published message.
+type natsMessage struct {
+	ID             int64           `json:"id"`
+	Event          string          `json:"event"`
+	OrganizationID int64           `json:"organization_id"`
+	OrderingKey    string          `json:"ordering_key"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// natsInfo holds the fields of the server's INFO the sink cares about.
+type natsInfo struct {
+	TLSRequired  bool `json:"tls_required"`
+	TLSAvailable bool `json:"tls_available"`
+}
+
+// NewNATSSink checks cfg without connecting, so the service starts while the
+// broker is down.
+func NewNATSSink(cfg NATSConfig) (*NATSSink, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return nil, fmt.Errorf("nats url must look like nats://host:port or tls://host:port, got %q", cfg.URL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+	prefix := strings.Trim(cfg.SubjectPrefix, ".")
+	if prefix == "" || strings.ContainsAny(prefix, " \t\r\n*>") {
+		return nil, fmt.Errorf("invalid nats subject prefix %q", prefix)
+	}
+
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read nats CA file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in nats CA file %s", cfg.CAFile)
+		}
+	}
+	return &NATSSink{
+		addr:       net.JoinHostPort(u.Hostname(), port),
+		user:       u.User,
+		prefix:     prefix,
+		tls:        tlsConfig,
+		requireTLS: u.Scheme == "tls",
+	}, nil
+}
+
+func (s *NATSSink) Name() string { return "nats" }
+
+func (s *NATSSink) Publish(ctx context.Context, ev models.OutboxEvent) error {
+	payload, err := json.Marshal(natsMessage{
+		ID:             ev.ID,
+		Event:          ev.Event,
+		OrganizationID: ev.OrganizationID,
+		OrderingKey:    ev.OrderingKey,
+		OccurredAt:     ev.CreatedAt.UTC(),
+		Data:           json.RawMessage(ev.Payload),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.publish(ctx, s.prefix+"."+ev.Event, payload); err != nil {
+		s.close()
+		return fmt.Errorf("nats publish %s: %w", ev.Event, err)
+	}
+	return nil
+}
+
+// publish sends one message and waits for the server to answer the PING
+// that follows it. s.mu must be held.
+func (s *NATSSink) publish(ctx context.Context, subject string, payload []byte) error {
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := s.conn.SetDeadline(natsDeadline(ctx)); err != nil {
+		return err
+	}
+
+	msg := make([]byte, 0, len(subject)+len(payload)+32)
+	msg = fmt.Appendf(msg, "PUB %s %d\r\n", subject, len(payload))
+	msg = append(msg, payload...)
+	msg = append(msg, "\r\nPING\r\n"...)
+	if _, err := s.conn.Write(msg); err != nil {
+		return err
+	}
+	return s.awaitPong()
+}
+
+// connect dials the server, reads its INFO, upgrades to TLS when either side
+// wants it and authenticates. The CONNECT is followed by a PING, so a rejected
+// login fails here rather than on the first PUB.
+func (s *NATSSink) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: natsTimeout}
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.conn, s.r = conn, bufio.NewReaderSize(conn, 4096)
+	if err := conn.SetDeadline(natsDeadline(ctx)); err != nil {
+		return err
+	}
+
+	line, err := s.readLine()
+	if err != nil {
+		return err
+	}
+	rawInfo, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(rawInfo), &info); err != nil {
+		return fmt.Errorf("parse INFO: %w", err)
+	}
+
+	useTLS := s.requireTLS || info.TLSRequired
+	if useTLS {
+		if !info.TLSRequired && !info.TLSAvailable {
+			return errors.New("server does not offer TLS")
+		}
+		tlsConn := tls.Client(conn, s.tls)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("tls handshake: %w", err)
+		}
+		s.conn, s.r = tlsConn, bufio.NewReaderSize(tlsConn, 4096)
+	}
+
+	opts := map[string]any{
+		"verbose":      false,
+		"pedantic":     false,
+		"tls_required": useTLS,
+		"name":         "pull-request-service",
+		"lang":         "go",
+		"protocol":     1,
+	}
+	if s.user != nil {
+		if pass, ok := s.user.Password(); ok {
+			opts["user"], opts["pass"] = s.user.Username(), pass
+		} else {
+			opts["auth_token"] = s.user.Username()
+		}
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	msg := append(append([]byte("CONNECT "), connect...), "\r\nPING\r\n"...)
+	if _, err := s.conn.Write(msg); err != nil {
+		return err
+	}
+	return s.awaitPong()
+}
+
+// awaitPong reads until the server answers our PING, answering its own PINGs
+// on the way. -ERR fails the call; the server closes the connection after it
+// anyway.
+func (s *NATSSink) awaitPong() error {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and INFO updates need no answer.
+	}
+}
+
+func (s *NATSSink) readLine() (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := s.r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, chunk...)
+		if len(line) > natsMaxLine {
+			return "", errors.New("protocol line too long")
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// close drops the connection after an error; the next publish reconnects.
+func (s *NATSSink) close() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn, s.r = nil, nil
+	}
+}
+
+func natsDeadline(ctx context.Context) time.Time {
+	if d, ok := ctx.Deadline(); ok {
+		return d
+	}
+	return time.Now().Add(natsTimeout)
+}
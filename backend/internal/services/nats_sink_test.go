@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type natsPub struct {
+	subject string
+	payload []byte
+}
+
+// fakeNATSConfig shapes the fake server's behaviour.
+type fakeNATSConfig struct {
+	// greeting replaces the INFO line when set.
+	greeting string
+	// tls makes the server require TLS after INFO.
+	tls *tls.Config
+	// token, when set, is the only auth_token accepted in CONNECT.
+	token string
+}
+
+// fakeNATS is a NATS server that understands just enough of the protocol for
+// NATSSink. While reject is set it answers every PUB with -ERR.
+type fakeNATS struct {
+	cfg      fakeNATSConfig
+	ln       net.Listener
+	conns    atomic.Int32
+	reject   atomic.Bool
+	connects chan map[string]any
+	pubs     chan natsPub
+}
+
+func newFakeNATS(t *testing.T, cfg fakeNATSConfig) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeNATS{cfg: cfg, ln: ln, connects: make(chan map[string]any, 10), pubs: make(chan natsPub, 10)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.conns.Add(1)
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeNATS) url() string { return "nats://" + f.ln.Addr().String() }
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	greeting := f.cfg.greeting
+	if greeting == "" {
+		greeting = fmt.Sprintf(`INFO {"server_id":"fake","max_payload":1048576,"tls_required":%t}`, f.cfg.tls != nil)
+	}
+	if _, err := io.WriteString(conn, greeting+"\r\n"); err != nil {
+		return
+	}
+	if f.cfg.tls != nil {
+		tlsConn := tls.Server(conn, f.cfg.tls)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch op {
+		case "CONNECT":
+			var opts map[string]any
+			_ = json.Unmarshal([]byte(args), &opts)
+			f.connects <- opts
+			if f.cfg.token != "" && opts["auth_token"] != f.cfg.token {
+				_, _ = io.WriteString(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case "PUB":
+			var subject string
+			var size int
+			if _, err := fmt.Sscanf(args, "%s %d", &subject, &size); err != nil {
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			if f.reject.Load() {
+				_, _ = io.WriteString(conn, "-ERR 'Permissions Violation for Publish'\r\n")
+				return
+			}
+			f.pubs <- natsPub{subject: subject, payload: payload[:size]}
+		case "PING":
+			// A server PING in between must be answered before the PONG arrives.
+			_, _ = io.WriteString(conn, "PING\r\n")
+			if reply, err := r.ReadString('\n'); err != nil || strings.TrimSpace(reply) != "PONG" {
+				return
+			}
+			_, _ = io.WriteString(conn, "PONG\r\n")
+		}
+	}
+}
+
+// natsTLS returns a server config with a fresh self-signed certificate for
+// 127.0.0.1 and the path of a PEM file holding that certificate.
+func natsTLS(t *testing.T) (*tls.Config, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake nats"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, caFile
+}
+
+func testOutboxEvent(id int64) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:             id,
+		OrganizationID: 2,
+		Event:          models.EventPRCreated,
+		OrderingKey:    prEventKey("pr-1"),
+		Payload:        []byte(`{"pull_request_id":"pr-1"}`),
+		CreatedAt:      time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestNATSSinkPublishes(t *testing.T) {
+	server := newFakeNATS(t, fakeNATSConfig{})
+	sink, err := NewNATSSink(NATSConfig{
+		URL:           strings.Replace(server.url(), "nats://", "nats://svc:secret@", 1),
+		SubjectPrefix: "prs.events.",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for id := int64(1); id <= 2; id++ {
+		if err := sink.Publish(ctx, testOutboxEvent(id)); err != nil {
+			t.Fatalf("publish %d: %v", id, err)
+		}
+	}
+
+	opts := <-server.connects
+	if opts["user"] != "svc" || opts["pass"] != "secret" || opts["verbose"] != false || opts["tls_required"] != false {
+		t.Fatalf("CONNECT options = %v", opts)
+	}
+	for id := int64(1); id <= 2; id++ {
+		pub := <-server.pubs
+		if pub.subject != "prs.events.pr.created" {
+			t.Fatalf("subject = %q", pub.subject)
+		}
+		var msg natsMessage
+		if err := json.Unmarshal(pub.payload, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.ID != id || msg.OrganizationID != 2 || msg.OrderingKey != "pr:pr-1" ||
+			string(msg.Data) != `{"pull_request_id":"pr-1"}` {
+			t.Fatalf("message = %+v", msg)
+		}
+	}
+	if n := server.conns.Load(); n != 1 {
+		t.Fatalf("connections = %d, want 1", n)
+	}
+}
+
+func TestNATSSinkReconnectsAfterError(t *testing.T) {
+	server := newFakeNATS(t, fakeNATSConfig{})
+	sink, err := NewNATSSink(NATSConfig{URL: server.url(), SubjectPrefix: "prs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	server.reject.Store(true)
+	if err := sink.Publish(ctx, testOutboxEvent(1)); err == nil || !strings.Contains(err.Error(), "Permissions") {
+		t.Fatalf("publish error = %v, want the server's -ERR", err)
+	}
+
+	server.reject.Store(false)
+	if err := sink.Publish(ctx, testOutboxEvent(1)); err != nil {
+		t.Fatalf("publish after error: %v", err)
+	}
+	if pub := <-server.pubs; pub.subject != "prs.pr.created" {
+		t.Fatalf("subject = %q", pub.subject)
+	}
+	if n := server.conns.Load(); n != 2 {
+		t.Fatalf("connections = %d, want 2", n)
+	}
+}
+
+func TestNATSSinkBrokerDown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	sink, err := NewNATSSink(NATSConfig{URL: "nats://" + addr, SubjectPrefix: "prs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Publish(context.Background(), testOutboxEvent(1)); err == nil {
+		t.Fatal("publish to a closed port succeeded")
+	}
+}
+
+func TestNATSSinkTLS(t *testing.T) {
+	serverTLS, caFile := natsTLS(t)
+	for _, scheme := range []string{"tls", "nats"} {
+		t.Run(scheme, func(t *testing.T) {
+			server := newFakeNATS(t, fakeNATSConfig{tls: serverTLS, token: "s3cret"})
+			sink, err := NewNATSSink(NATSConfig{
+				URL:           scheme + "://s3cret@" + server.ln.Addr().String(),
+				SubjectPrefix: "prs",
+				CAFile:        caFile,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := sink.Publish(ctx, testOutboxEvent(1)); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+			if opts := <-server.connects; opts["auth_token"] != "s3cret" || opts["tls_required"] != true {
+				t.Fatalf("CONNECT options = %v", opts)
+			}
+			if pub := <-server.pubs; pub.subject != "prs.pr.created" {
+				t.Fatalf("subject = %q", pub.subject)
+			}
+		})
+	}
+}
+
+func TestNATSSinkConnectErrors(t *testing.T) {
+	serverTLS, _ := natsTLS(t)
+	for _, tc := range []struct {
+		name   string
+		server fakeNATSConfig
+		url    func(addr string) string
+		want   string
+	}{
+		{
+			name:   "wrong token",
+			server: fakeNATSConfig{token: "s3cret"},
+			url:    func(addr string) string { return "nats://guess@" + addr },
+			want:   "Authorization Violation",
+		},
+		{
+			name: "tls not offered",
+			url:  func(addr string) string { return "tls://" + addr },
+			want: "server does not offer TLS",
+		},
+		{
+			name:   "untrusted certificate",
+			server: fakeNATSConfig{tls: serverTLS},
+			url:    func(addr string) string { return "nats://" + addr },
+			want:   "tls handshake",
+		},
+		{
+			name:   "not a nats server",
+			server: fakeNATSConfig{greeting: "SSH-2.0-OpenSSH_9.6"},
+			want:   "unexpected greeting",
+		},
+		{
+			name:   "malformed info",
+			server: fakeNATSConfig{greeting: "INFO {"},
+			want:   "parse INFO",
+		},
+		{
+			name:   "oversized line",
+			server: fakeNATSConfig{greeting: "INFO " + strings.Repeat("x", natsMaxLine+1)},
+			want:   "protocol line too long",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFakeNATS(t, tc.server)
+			url := server.url()
+			if tc.url != nil {
+				url = tc.url(server.ln.Addr().String())
+			}
+			sink, err := NewNATSSink(NATSConfig{URL: url, SubjectPrefix: "prs"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err = sink.Publish(ctx, testOutboxEvent(1))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("publish error = %v, want %q", err, tc.want)
+			}
+			if len(server.pubs) != 0 {
+				t.Fatal("message published despite the failed connect")
+			}
+		})
+	}
+}
+
+func TestNewNATSSinkRejectsBadConfig(t *testing.T) {
+	for _, cfg := range []NATSConfig{
+		{URL: "http://localhost:4222", SubjectPrefix: "prs"},
+		{URL: "nats://", SubjectPrefix: "prs"},
+		{URL: "nats://localhost", SubjectPrefix: ""},
+		{URL: "nats://localhost", SubjectPrefix: "prs.*"},
+		{URL: "tls://localhost", SubjectPrefix: "prs", CAFile: "/nonexistent/ca.pem"},
+	} {
+		if _, err := NewNATSSink(cfg); err == nil {
+			t.Errorf("NewNATSSink(%+v) succeeded", cfg)
+		}
+	}
+}
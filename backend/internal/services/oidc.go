@@ -0,0 +1,478 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+// OIDCConfig configures bearer JWTs issued by an OIDC provider.
+type OIDCConfig struct {
+	// JWKS is a path to a key set file or an http(s) URL serving one.
+	JWKS     string
+	Issuer   string
+	Audience string
+	// UserClaim names the claim holding our external user_id.
+	UserClaim   string
+	GroupsClaim string
+	// OrgClaim names the claim holding the organization slug. When empty,
+	// every token belongs to the default organization.
+	OrgClaim string
+	// GroupRoles maps identity-provider groups to roles from models.RoleScopes.
+	GroupRoles map[string]string
+	// RefreshInterval is how long a fetched key set is trusted.
+	RefreshInterval time.Duration
+}
+
+var errInvalidJWT = errors.New("invalid token")
+
+// clockSkew is tolerated on exp and nbf.
+const clockSkew = time.Minute
+
+// OIDCVerifier validates JWT signatures and standard claims against a JWKS.
+type OIDCVerifier struct {
+	cfg  OIDCConfig
+	keys *jwksCache
+	now  func() time.Time
+}
+
+func NewOIDCVerifier(cfg OIDCConfig) *OIDCVerifier {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "preferred_username"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = time.Hour
+	}
+	return &OIDCVerifier{
+		cfg:  cfg,
+		keys: &jwksCache{source: cfg.JWKS, ttl: cfg.RefreshInterval, client: &http.Client{Timeout: 10 * time.Second}},
+		now:  time.Now,
+	}
+}
+
+// LooksLikeJWT tells a compact JWS apart from an opaque API token.
+func LooksLikeJWT(secret string) bool {
+	return strings.Count(secret, ".") == 2
+}
+
+// JWTIdentity is what a verified token says about its bearer.
+type JWTIdentity struct {
+	UserID       string
+	Organization string
+	Scopes       []string
+}
+
+// Verify checks the token and returns the user_id, the organization and the
+// scopes granted by its groups.
+func (v *OIDCVerifier) Verify(ctx context.Context, raw string) (JWTIdentity, error) {
+	claims, err := v.verifySignature(ctx, raw)
+	if err != nil {
+		return JWTIdentity{}, err
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return JWTIdentity{}, err
+	}
+
+	userID, _ := claims[v.cfg.UserClaim].(string)
+	if userID == "" {
+		return JWTIdentity{}, fmt.Errorf("%w: missing %s claim", errInvalidJWT, v.cfg.UserClaim)
+	}
+	org := models.DefaultOrganizationSlug
+	if v.cfg.OrgClaim != "" {
+		if org, _ = claims[v.cfg.OrgClaim].(string); org == "" {
+			return JWTIdentity{}, fmt.Errorf("%w: missing %s claim", errInvalidJWT, v.cfg.OrgClaim)
+		}
+	}
+	return JWTIdentity{UserID: userID, Organization: org, Scopes: v.scopesFor(claims[v.cfg.GroupsClaim])}, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (v *OIDCVerifier) verifySignature(ctx context.Context, raw string) (map[string]any, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", errInvalidJWT)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", errInvalidJWT)
+	}
+
+	key, err := v.keys.get(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	switch header.Alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return nil, fmt.Errorf("%w: bad signature", errInvalidJWT)
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return nil, fmt.Errorf("%w: bad signature", errInvalidJWT)
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return nil, fmt.Errorf("%w: bad signature", errInvalidJWT)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported alg %q", errInvalidJWT, header.Alg)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *OIDCVerifier) checkClaims(claims map[string]any) error {
+	now := v.now()
+
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return fmt.Errorf("%w: missing exp", errInvalidJWT)
+	}
+	if now.After(time.Unix(exp, 0).Add(clockSkew)) {
+		return fmt.Errorf("%w: expired", errInvalidJWT)
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(clockSkew).Before(time.Unix(nbf, 0)) {
+		return fmt.Errorf("%w: not yet valid", errInvalidJWT)
+	}
+
+	if v.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.cfg.Issuer {
+			return fmt.Errorf("%w: unexpected issuer", errInvalidJWT)
+		}
+	}
+	if v.cfg.Audience != "" && !containsAudience(claims["aud"], v.cfg.Audience) {
+		return fmt.Errorf("%w: unexpected audience", errInvalidJWT)
+	}
+	return nil
+}
+
+func (v *OIDCVerifier) scopesFor(groupsClaim any) []string {
+	var groups []string
+	switch g := groupsClaim.(type) {
+	case string:
+		groups = []string{g}
+	case []any:
+		for _, item := range g {
+			if s, ok := item.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+
+	set := make(map[string]struct{})
+	for _, group := range groups {
+		for _, scope := range models.RoleScopes[v.cfg.GroupRoles[group]] {
+			set[scope] = struct{}{}
+		}
+	}
+	scopes := make([]string, 0, len(set))
+	for scope := range set {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// ParseGroupRoles parses "group=role;group=role" as used in configuration.
+func ParseGroupRoles(raw string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(raw, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid group mapping %q", pair)
+		}
+		if _, known := models.RoleScopes[role]; !known {
+			return nil, fmt.Errorf("unknown role %q", role)
+		}
+		out[group] = role
+	}
+	return out, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", errInvalidJWT)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: malformed segment", errInvalidJWT)
+	}
+	return nil
+}
+
+func numericClaim(claims map[string]any, name string) (int64, bool) {
+	n, ok := claims[name].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// containsAudience handles aud as either a string or an array of strings.
+func containsAudience(aud any, want string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == want
+	case []any:
+		for _, item := range a {
+			if s, ok := item.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jwksCache holds the provider keys by kid. The set is reloaded once it is
+// older than ttl, and an unknown kid triggers a reload at most once per
+// minRefetch, so rotated keys are picked up promptly. Loads run outside the
+// lock and concurrent callers share one load.
+type jwksCache struct {
+	source string
+	ttl    time.Duration
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	// failedAt and missedAt throttle reloads after a failed load and after an
+	// unknown kid.
+	failedAt time.Time
+	missedAt time.Time
+	loading  *jwksLoad
+}
+
+// jwksLoad is a load in progress; done is closed once keys or err is set.
+type jwksLoad struct {
+	done chan struct{}
+	keys map[string]crypto.PublicKey
+	err  error
+}
+
+const minRefetch = 30 * time.Second
+
+func (c *jwksCache) get(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	key, known := c.keys[kid]
+	stale := c.keys == nil || time.Since(c.fetchedAt) >= c.ttl
+	switch {
+	case known && !stale:
+		c.mu.Unlock()
+		return key, nil
+	case stale && c.keys != nil && time.Since(c.failedAt) < minRefetch:
+		// The provider failed recently; keep serving the old set meanwhile.
+		c.mu.Unlock()
+		return c.lookup(key, known, kid)
+	case !stale && time.Since(c.missedAt) < minRefetch:
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%w: unknown key %q", errInvalidJWT, kid)
+	case !stale:
+		c.missedAt = time.Now()
+	}
+	load := c.startLoad(ctx)
+	c.mu.Unlock()
+
+	select {
+	case <-load.done:
+	case <-ctx.Done():
+		if known {
+			return key, nil
+		}
+		return nil, ctx.Err()
+	}
+	if load.err != nil {
+		if known {
+			// Keep serving a known key while the provider is unreachable.
+			return key, nil
+		}
+		return nil, load.err
+	}
+	key, known = load.keys[kid]
+	return c.lookup(key, known, kid)
+}
+
+func (c *jwksCache) lookup(key crypto.PublicKey, known bool, kid string) (crypto.PublicKey, error) {
+	if !known {
+		return nil, fmt.Errorf("%w: unknown key %q", errInvalidJWT, kid)
+	}
+	return key, nil
+}
+
+// startLoad starts a load, or returns the one in progress. c.mu must be held.
+// The load is detached from the cancellation of ctx, since other callers may
+// be waiting for it.
+func (c *jwksCache) startLoad(ctx context.Context) *jwksLoad {
+	if c.loading != nil {
+		return c.loading
+	}
+	load := &jwksLoad{done: make(chan struct{})}
+	c.loading = load
+	go func() {
+		keys, err := c.load(context.WithoutCancel(ctx))
+
+		c.mu.Lock()
+		if err == nil {
+			c.keys, c.fetchedAt = keys, time.Now()
+		} else {
+			c.failedAt = time.Now()
+		}
+		c.loading = nil
+		c.mu.Unlock()
+
+		load.keys, load.err = keys, err
+		close(load.done)
+	}()
+	return load
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (c *jwksCache) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	raw, err := c.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load jwks: %w", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	// Providers publish keys of types we cannot verify with; those are
+	// skipped as long as at least one key is usable.
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	var skipped []string
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%q: %v", k.Kid, err))
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		if len(skipped) > 0 {
+			return nil, fmt.Errorf("parse jwks: no usable key: %s", strings.Join(skipped, "; "))
+		}
+		return nil, errors.New("parse jwks: no signing keys")
+	}
+	return keys, nil
+}
+
+func (c *jwksCache) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(c.source, "http://") && !strings.HasPrefix(c.source, "https://") {
+		return os.ReadFile(c.source)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 coordinates")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
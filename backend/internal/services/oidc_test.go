@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testKey struct {
+	kid  string
+	priv *rsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, priv: priv}
+}
+
+func (k testKey) jwk() map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": k.kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(k.priv.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.priv.E)).Bytes()),
+	}
+}
+
+func (k testKey) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	seg := func(v any) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	signed := seg(map[string]string{"alg": "RS256", "kid": k.kid}) + "." + seg(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.priv, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// jwksServer serves a key set that tests can replace, and counts fetches.
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []map[string]string
+	fetches atomic.Int32
+	// gate, when set, holds every fetch until it is closed.
+	gate chan struct{}
+}
+
+func newJWKSServer(t *testing.T, keys ...map[string]string) *jwksServer {
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		gate, set := s.gate, map[string]any{"keys": s.keys}
+		s.mu.Unlock()
+		if gate != nil {
+			<-gate
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func testClaims(exp time.Time) map[string]any {
+	return map[string]any{
+		"iss":                "https://idp.example",
+		"aud":                "pr-service",
+		"exp":                exp.Unix(),
+		"preferred_username": "u1",
+	}
+}
+
+func newTestVerifier(jwks string, now time.Time) *OIDCVerifier {
+	v := NewOIDCVerifier(OIDCConfig{JWKS: jwks, Issuer: "https://idp.example", Audience: "pr-service"})
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func TestOIDCVerifyExpiry(t *testing.T) {
+	key := newTestKey(t, "k1")
+	srv := newJWKSServer(t, key.jwk())
+	now := time.Now()
+	v := newTestVerifier(srv.URL, now)
+
+	cases := []struct {
+		name   string
+		claims map[string]any
+		ok     bool
+	}{
+		{"valid", testClaims(now.Add(time.Hour)), true},
+		{"within clock skew", testClaims(now.Add(-clockSkew / 2)), true},
+		{"expired", testClaims(now.Add(-2 * clockSkew)), false},
+		{"missing exp", func() map[string]any {
+			c := testClaims(now)
+			delete(c, "exp")
+			return c
+		}(), false},
+		{"not yet valid", func() map[string]any {
+			c := testClaims(now.Add(time.Hour))
+			c["nbf"] = now.Add(2 * clockSkew).Unix()
+			return c
+		}(), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := v.Verify(context.Background(), key.sign(t, tc.claims))
+			if tc.ok && (err != nil || id.UserID != "u1") {
+				t.Fatalf("Verify = %+v, %v; want u1", id, err)
+			}
+			if !tc.ok && !errors.Is(err, errInvalidJWT) {
+				t.Fatalf("Verify error = %v, want invalid token", err)
+			}
+		})
+	}
+}
+
+func TestOIDCVerifyAudience(t *testing.T) {
+	key := newTestKey(t, "k1")
+	srv := newJWKSServer(t, key.jwk())
+	now := time.Now()
+	v := newTestVerifier(srv.URL, now)
+
+	cases := []struct {
+		name string
+		aud  any
+		ok   bool
+	}{
+		{"string", "pr-service", true},
+		{"array", []string{"other", "pr-service"}, true},
+		{"other audience", "other", false},
+		{"other audiences", []string{"a", "b"}, false},
+		{"missing", nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := testClaims(now.Add(time.Hour))
+			claims["aud"] = tc.aud
+			_, err := v.Verify(context.Background(), key.sign(t, claims))
+			if tc.ok != (err == nil) {
+				t.Fatalf("Verify error = %v, want ok=%v", err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	old, rotated, unknown := newTestKey(t, "k1"), newTestKey(t, "k2"), newTestKey(t, "k3")
+	srv := newJWKSServer(t, old.jwk())
+	now := time.Now()
+	v := newTestVerifier(srv.URL, now)
+	ctx := context.Background()
+
+	if _, err := v.Verify(ctx, old.sign(t, testClaims(now.Add(time.Hour)))); err != nil {
+		t.Fatalf("old key: %v", err)
+	}
+
+	srv.setKeys(rotated.jwk())
+	if _, err := v.Verify(ctx, rotated.sign(t, testClaims(now.Add(time.Hour)))); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+	if n := srv.fetches.Load(); n != 2 {
+		t.Fatalf("fetches = %d, want 2", n)
+	}
+
+	// Another unknown kid right after a refetch does not reach the provider.
+	for _, key := range []testKey{unknown, old} {
+		if _, err := v.Verify(ctx, key.sign(t, testClaims(now.Add(time.Hour)))); !errors.Is(err, errInvalidJWT) {
+			t.Fatalf("kid %s: error = %v, want invalid token", key.kid, err)
+		}
+	}
+	if n := srv.fetches.Load(); n != 2 {
+		t.Fatalf("fetches = %d, want 2", n)
+	}
+}
+
+func TestJWKSSkipsUnsupportedKeys(t *testing.T) {
+	key := newTestKey(t, "k1")
+	okp := map[string]string{"kty": "OKP", "crv": "Ed25519", "kid": "ed",
+		"x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	p384 := map[string]string{"kty": "EC", "crv": "P-384", "kid": "p384", "x": "AA", "y": "AA"}
+	now := time.Now()
+
+	srv := newJWKSServer(t, okp, p384, key.jwk())
+	v := newTestVerifier(srv.URL, now)
+	if _, err := v.Verify(context.Background(), key.sign(t, testClaims(now.Add(time.Hour)))); err != nil {
+		t.Fatalf("Verify with unsupported neighbours: %v", err)
+	}
+
+	bad := newJWKSServer(t, okp, p384)
+	c := &jwksCache{source: bad.URL, ttl: time.Hour, client: http.DefaultClient}
+	if _, err := c.load(context.Background()); err == nil {
+		t.Fatal("load of a set without usable keys succeeded")
+	}
+}
+
+func TestJWKSCacheSharesLoad(t *testing.T) {
+	key := newTestKey(t, "k1")
+	srv := newJWKSServer(t, key.jwk())
+	gate := make(chan struct{})
+	srv.gate = gate
+	c := &jwksCache{source: srv.URL, ttl: time.Hour, client: http.DefaultClient}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.get(context.Background(), "k1")
+			errs <- err
+		}()
+	}
+
+	// While the load is held by the provider, the cache lock stays free.
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.fetches.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	locked := make(chan struct{})
+	go func() {
+		c.mu.Lock()
+		c.mu.Unlock() //nolint:staticcheck // only checks that the lock is free
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cache lock is held during the load")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := srv.fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+}
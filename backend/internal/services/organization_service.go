@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+type OrganizationService interface {
+	Add(ctx context.Context, req dtos.AddOrganizationRequest) (dtos.OrganizationResponse, error)
+	List(ctx context.Context) (dtos.OrganizationListResponse, error)
+}
+
+type organizationService struct {
+	repo      repositories.OrganizationRepository
+	validator validators.OrganizationValidator
+}
+
+func NewOrganizationService(
+	repo repositories.OrganizationRepository,
+	validator validators.OrganizationValidator) OrganizationService {
+	return &organizationService{repo: repo, validator: validator}
+}
+
+func (s *organizationService) Add(
+	ctx context.Context,
+	req dtos.AddOrganizationRequest) (dtos.OrganizationResponse, error) {
+	if err := s.validator.ValidateAdd(ctx, req); err != nil {
+		return dtos.OrganizationResponse{}, err
+	}
+
+	org, err := s.repo.Create(ctx, models.Organization{Slug: req.Slug, Name: strings.TrimSpace(req.Name)})
+	if err != nil {
+		if errors.Is(err, repositories.ErrOrgExists) {
+			return dtos.OrganizationResponse{}, derr.New(derr.CodeOrgExists, "organization already exists")
+		}
+		return dtos.OrganizationResponse{}, err
+	}
+	return dtos.OrganizationResponse{Organization: mapOrganizationToDTO(org)}, nil
+}
+
+func (s *organizationService) List(ctx context.Context) (dtos.OrganizationListResponse, error) {
+	orgs, err := s.repo.List(ctx)
+	if err != nil {
+		return dtos.OrganizationListResponse{}, err
+	}
+	out := make([]dtos.OrganizationDTO, 0, len(orgs))
+	for _, o := range orgs {
+		out = append(out, mapOrganizationToDTO(o))
+	}
+	return dtos.OrganizationListResponse{Organizations: out}, nil
+}
+
+func mapOrganizationToDTO(o models.Organization) dtos.OrganizationDTO {
+	return dtos.OrganizationDTO{Slug: o.Slug, Name: o.Name, CreatedAt: o.CreatedAt}
+}
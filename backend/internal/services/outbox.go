@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+	// After outboxMaxAttempts failures, about half an hour of retries, an
+	// event is dead and stops holding back the rest of its ordering key.
+	outboxMaxAttempts    = 10
+	outboxPublishTimeout = 15 * time.Second
+	// outboxLease must outlast publishing a whole batch, or another relay
+	// claims the same events again.
+	outboxLease = outboxBatchSize * outboxPublishTimeout
+	// Published events are kept for a while to help tracing integrations.
+	outboxRetention     = 7 * 24 * time.Hour
+	outboxPurgeInterval = time.Hour
+)
+
+// EventSink receives the events relayed from the outbox. Delivery is at least
+// once: when any sink fails, the event is later published to every sink again.
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, ev models.OutboxEvent) error
+}
+
+// Outbox records domain events in the caller's transaction and relays them to
+// the sinks once committed.
+type Outbox struct {
+	repo         repositories.OutboxRepository
+	sinks        []EventSink
+	pollInterval time.Duration
+}
+
+func NewOutbox(repo repositories.OutboxRepository, sinks []EventSink, pollInterval time.Duration) *Outbox {
+	return &Outbox{repo: repo, sinks: sinks, pollInterval: pollInterval}
+}
+
+// Publish records event through ctx. Events with the same orderingKey reach
+// the sinks in the order they were published.
+func (o *Outbox) Publish(ctx context.Context, event, orderingKey string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return o.repo.Insert(ctx, event, orderingKey, payload)
+}
+
+// Start runs the relay until ctx is cancelled.
+func (o *Outbox) Start(ctx context.Context) {
+	go o.relay(ctx)
+}
+
+func (o *Outbox) relay(ctx context.Context) {
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	var lastPurge time.Time
+	for {
+		n, err := o.relayBatch(ctx)
+		if err != nil {
+			log.Printf("relay outbox: %v", err)
+		}
+		if time.Since(lastPurge) >= outboxPurgeInterval {
+			lastPurge = time.Now()
+			if _, err := o.repo.PurgePublished(ctx, outboxRetention); err != nil {
+				log.Printf("relay outbox: %v", err)
+			}
+		}
+		if err == nil && n == outboxBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch publishes one batch of due events and returns its size. The
+// events are leased by Claim, so no transaction or row lock is held while the
+// sinks are called.
+func (o *Outbox) relayBatch(ctx context.Context) (int, error) {
+	events, err := o.repo.Claim(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, ev := range events {
+		if err := o.deliver(ctx, ev); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+// deliver publishes a claimed event and records the outcome.
+func (o *Outbox) deliver(ctx context.Context, ev models.OutboxEvent) error {
+	publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	err := o.publish(publishCtx, ev)
+	cancel()
+	if err == nil {
+		return o.repo.MarkPublished(ctx, ev.ID)
+	}
+	dead := ev.Attempts >= outboxMaxAttempts
+	if dead {
+		log.Printf("outbox event %d is dead after %d attempts: %v", ev.ID, ev.Attempts, err)
+	} else {
+		log.Printf("outbox event %d: %v", ev.ID, err)
+	}
+	return o.repo.MarkFailed(ctx, ev.ID, dead, outboxBackoff(ev.Attempts), err.Error())
+}
+
+func (o *Outbox) publish(ctx context.Context, ev models.OutboxEvent) error {
+	for _, sink := range o.sinks {
+		if err := sink.Publish(ctx, ev); err != nil {
+			return fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	d := outboxBaseBackoff
+	for i := 1; i < attempts && d < outboxMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, outboxMaxBackoff)
+}
+
+// LogSink writes every event to the service log.
+type LogSink struct{}
+
+func (LogSink) Name() string { return "log" }
+
+func (LogSink) Publish(_ context.Context, ev models.OutboxEvent) error {
+	log.Printf("event %d %s org=%d key=%s: %s", ev.ID, ev.Event, ev.OrganizationID, ev.OrderingKey, ev.Payload)
+	return nil
+}
+
+func prEventKey(pullRequestID string) string {
+	return "pr:" + pullRequestID
+}
+
+func userEventKey(userID string) string {
+	return "user:" + userID
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+)
+
+type fakeOutboxEvent struct {
+	models.OutboxEvent
+	published bool
+	dead      bool
+	leased    bool
+	lastError string
+}
+
+// fakeOutboxRepo claims like the database does: the oldest pending event of
+// each ordering key, skipping leased ones. Leases never expire.
+type fakeOutboxRepo struct {
+	repositories.OutboxRepository
+	events  []*fakeOutboxEvent
+	retries []time.Duration
+}
+
+func (r *fakeOutboxRepo) Claim(_ context.Context, limit int, _ time.Duration) ([]models.OutboxEvent, error) {
+	var out []models.OutboxEvent
+	blocked := map[string]bool{}
+	for _, ev := range r.events {
+		if ev.published || ev.dead {
+			continue
+		}
+		key := ev.OrderingKey
+		if !blocked[key] && !ev.leased && len(out) < limit {
+			ev.Attempts++
+			ev.leased = true
+			out = append(out, ev.OutboxEvent)
+		}
+		blocked[key] = true
+	}
+	return out, nil
+}
+
+func (r *fakeOutboxRepo) MarkPublished(_ context.Context, id int64) error {
+	ev := r.get(id)
+	ev.published, ev.leased = true, false
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(
+	_ context.Context,
+	id int64,
+	dead bool,
+	retryIn time.Duration,
+	errMsg string) error {
+	ev := r.get(id)
+	ev.dead, ev.leased, ev.lastError = dead, false, errMsg
+	if !dead {
+		r.retries = append(r.retries, retryIn)
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) get(id int64) *fakeOutboxEvent {
+	i := slices.IndexFunc(r.events, func(ev *fakeOutboxEvent) bool { return ev.ID == id })
+	return r.events[i]
+}
+
+// recordingSink fails every event listed in poison and records the rest.
+type recordingSink struct {
+	poison    map[int64]bool
+	published []int64
+}
+
+func (s *recordingSink) Name() string { return "test" }
+
+func (s *recordingSink) Publish(ctx context.Context, ev models.OutboxEvent) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("publish without a deadline")
+	}
+	if s.poison[ev.ID] {
+		return errors.New("broker rejected the event")
+	}
+	s.published = append(s.published, ev.ID)
+	return nil
+}
+
+func newFakeOutbox(events ...models.OutboxEvent) (*Outbox, *fakeOutboxRepo, *recordingSink) {
+	repo := &fakeOutboxRepo{}
+	for _, ev := range events {
+		repo.events = append(repo.events, &fakeOutboxEvent{OutboxEvent: ev})
+	}
+	sink := &recordingSink{poison: map[int64]bool{}}
+	return NewOutbox(repo, []EventSink{sink}, time.Second), repo, sink
+}
+
+func outboxEvent(id int64, key string) models.OutboxEvent {
+	return models.OutboxEvent{ID: id, Event: models.EventPRCreated, OrderingKey: key}
+}
+
+func TestOutboxRelayKeepsKeyOrder(t *testing.T) {
+	o, _, sink := newFakeOutbox(
+		outboxEvent(1, "pr:a"), outboxEvent(2, "pr:b"), outboxEvent(3, "pr:a"), outboxEvent(4, "pr:a"))
+
+	for range 3 {
+		if _, err := o.relayBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []int64{1, 2, 3, 4}; !slices.Equal(sink.published, want) {
+		t.Fatalf("published %v, want %v", sink.published, want)
+	}
+}
+
+func TestOutboxDeadLetter(t *testing.T) {
+	o, repo, sink := newFakeOutbox(outboxEvent(1, "pr:a"), outboxEvent(2, "pr:a"), outboxEvent(3, "pr:b"))
+	sink.poison[1] = true
+
+	// Retries are due right away in the fake, so every batch retries event 1.
+	for i := 0; i < outboxMaxAttempts; i++ {
+		if _, err := o.relayBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if i < outboxMaxAttempts-1 && slices.Contains(sink.published, 2) {
+			t.Fatalf("event 2 published while event 1 is still retried")
+		}
+	}
+	poison := repo.get(1)
+	if !poison.dead || poison.Attempts != outboxMaxAttempts || poison.lastError == "" {
+		t.Fatalf("event 1 dead=%v after %d attempts, error %q; want dead after %d",
+			poison.dead, poison.Attempts, poison.lastError, outboxMaxAttempts)
+	}
+	if len(repo.retries) != outboxMaxAttempts-1 || repo.retries[0] != outboxBaseBackoff ||
+		repo.retries[len(repo.retries)-1] != outboxMaxBackoff {
+		t.Fatalf("retries = %v", repo.retries)
+	}
+
+	// The dead event no longer holds back its key.
+	if _, err := o.relayBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{3, 2}; !slices.Equal(sink.published, want) {
+		t.Fatalf("published %v, want %v", sink.published, want)
+	}
+}
@@ -2,9 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	stdrr "errors"
-	"fmt"
 	"math/rand"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
@@ -16,21 +19,68 @@ import (
 
 type PRService interface {
 	Create(ctx context.Context, req dtos.CreatePRRequest) (dtos.PRResponse, error)
+	SuggestReviewers(ctx context.Context, req dtos.CreatePRRequest) (dtos.SuggestReviewersResponse, error)
+	Update(ctx context.Context, req dtos.UpdatePRRequest) (dtos.PRResponse, error)
 	Merge(ctx context.Context, req dtos.MergePRRequest) (dtos.PRResponse, error)
 	Reassign(ctx context.Context, req dtos.ReassignRequest) (dtos.ReassignResponse, error)
+	Explain(ctx context.Context, prID, userID string) (dtos.ExplainResponse, error)
+	ListAssignmentEvents(ctx context.Context, prID string) (dtos.AssignmentEventListResponse, error)
+	ReplayAssignment(ctx context.Context, req dtos.ReplayAssignmentRequest) (dtos.ReplayAssignmentResponse, error)
 }
 
 type prService struct {
 	prRepo    repositories.PRRepository
 	userRepo  repositories.UserRepository
+	repoRepo  repositories.RepoRepository
+	groupRepo repositories.GroupRepository
+	teamRepo  repositories.TeamRepository
+	eventRepo repositories.AssignmentEventRepository
+	txManager *repositories.TxManager
+	access    *AccessControl
+	audit     *AuditService
+	outbox    *Outbox
 	validator validators.PRValidator
+	seeds     SeedSource
+
+	// pairWindow is how far back author-reviewer history is taken into
+	// account when choosing reviewers; zero disables the penalty.
+	pairWindow time.Duration
+	// maxOpenReviews is how many open PRs a reviewer may review at once;
+	// zero disables the cap.
+	maxOpenReviews int
 }
 
 func NewPRService(
 	pr repositories.PRRepository,
 	user repositories.UserRepository,
-	val validators.PRValidator) PRService {
-	return &prService{prRepo: pr, userRepo: user, validator: val}
+	repo repositories.RepoRepository,
+	group repositories.GroupRepository,
+	team repositories.TeamRepository,
+	events repositories.AssignmentEventRepository,
+	txManager *repositories.TxManager,
+	access *AccessControl,
+	audit *AuditService,
+	outbox *Outbox,
+	val validators.PRValidator,
+	pairWindow time.Duration,
+	maxOpenReviews int,
+	seeds SeedSource) PRService {
+	return &prService{
+		prRepo:         pr,
+		userRepo:       user,
+		repoRepo:       repo,
+		groupRepo:      group,
+		teamRepo:       team,
+		eventRepo:      events,
+		txManager:      txManager,
+		access:         access,
+		audit:          audit,
+		outbox:         outbox,
+		validator:      val,
+		seeds:          seeds,
+		pairWindow:     pairWindow,
+		maxOpenReviews: maxOpenReviews,
+	}
 }
 
 func (s *prService) Create(ctx context.Context, req dtos.CreatePRRequest) (dtos.PRResponse, error) {
@@ -38,6 +88,44 @@ func (s *prService) Create(ctx context.Context, req dtos.CreatePRRequest) (dtos.
 		return dtos.PRResponse{}, err
 	}
 
+	var resp dtos.PRResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if resp, err = s.create(ctx, req); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, models.AuditPRCreate, models.AuditEntityPullRequest, resp.PR.PullRequestID,
+			nil, resp.PR); err != nil {
+			return errors.New(errors.CodeInternal, "internal error")
+		}
+		if err := s.emitCreated(ctx, resp.PR); err != nil {
+			return errors.New(errors.CodeInternal, "internal error")
+		}
+		return nil
+	})
+	if _, ok := errors.IsDomain(err); err != nil && !ok {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	return resp, err
+}
+
+// emitCreated announces the PR and each of its initial reviewers.
+func (s *prService) emitCreated(ctx context.Context, pr dtos.PullRequestDTO) error {
+	key := prEventKey(pr.PullRequestID)
+	if err := s.outbox.Publish(ctx, models.EventPRCreated, key, pr); err != nil {
+		return err
+	}
+	for _, r := range pr.AssignedReviewers {
+		err := s.outbox.Publish(ctx, models.EventReviewerAssigned, key,
+			dtos.ReviewerEventData{PullRequestID: pr.PullRequestID, ReviewerID: r})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *prService) create(ctx context.Context, req dtos.CreatePRRequest) (dtos.PRResponse, error) {
 	author, err := s.userRepo.GetByUserID(ctx, req.Author)
 	if stdrr.Is(err, repositories.ErrNotFound) {
 		return dtos.PRResponse{}, errors.New(errors.CodeNotFound, "resource not found")
@@ -48,47 +136,199 @@ func (s *prService) Create(ctx context.Context, req dtos.CreatePRRequest) (dtos.
 		Title:         req.Title,
 		AuthorUserID:  author.ID,
 		Status:        models.PROpen,
-	}
-	created, err := s.prRepo.Create(ctx, pr)
-	fmt.Println(created, err)
+		Repository:    req.Repository,
+		SourceBranch:  req.SourceBranch,
+		TargetBranch:  req.TargetBranch,
+		Description:   req.Description,
+		URL:           req.URL,
+		Labels:        req.Labels,
+		ChangedFiles:  req.ChangedFiles,
+		RequiredTags:  normalizeTags(req.RequiredTags),
+	}
+
+	seed := s.seeds()
+	picks, snap, err := s.pickReviewers(ctx, pr, author, seed)
 	if err != nil {
-		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+		return dtos.PRResponse{}, err
 	}
 
-	teamMembers, err := s.userRepo.GetTeamMembers(ctx, author.TeamID)
+	created, err := s.prRepo.Create(ctx, pr)
 	if err != nil {
+		if stdrr.Is(err, repositories.ErrPRExists) {
+			return dtos.PRResponse{}, errors.New(errors.CodePRExists, "PR id already exists")
+		}
 		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
-	reviewerUserIDs := s.selectReviewers(teamMembers, req.Author, 2)
-
-	if len(reviewerUserIDs) > 0 {
-		var reviewerInternalIDs []int64
-		for _, uid := range reviewerUserIDs {
-			u, err := s.userRepo.GetByUserID(ctx, uid)
+	var reviewers []models.User
+	var reviewerUserIDs []string
+	if len(picks) > 0 {
+		assignments := make([]models.ReviewAssignment, 0, len(picks))
+		for _, p := range picks {
+			u, err := s.userRepo.GetByUserID(ctx, p.userID)
 			if err != nil {
 				return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
 			}
-			reviewerInternalIDs = append(reviewerInternalIDs, u.ID)
+			reviewers = append(reviewers, u)
+			reviewerUserIDs = append(reviewerUserIDs, u.UserID)
+			assignments = append(assignments, models.ReviewAssignment{
+				ReviewerID:      u.ID,
+				RequiredGroupID: p.groupID,
+			})
 		}
 
-		if err := s.prRepo.AssignReviewers(ctx, created.ID, reviewerInternalIDs); err != nil {
+		if err := s.prRepo.AssignReviewers(ctx, created.ID, assignments); err != nil {
 			return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
 		}
 	}
 
 	created.Reviewers = reviewerUserIDs
 
-	return dtos.PRResponse{
-		PR: mapPRToDTO(created, author.UserID),
+	explanations := explainCreate(snap, picks)
+	if err := s.recordAssignment(ctx, created.ID, models.AssignmentCreate, seed, snap, reviewerUserIDs, explanations); err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	if created.Version, err = s.prRepo.GetVersion(ctx, created.ID); err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	out := mapPRToDTO(created, author.UserID)
+	out.ReviewerMatches = reviewerMatches(reviewers, created.RequiredTags)
+
+	return dtos.PRResponse{PR: out}, nil
+}
+
+// SuggestReviewers runs the selection for a PR that is not created yet and
+// returns who would be picked together with the ranked candidate pool. Nothing
+// is written.
+func (s *prService) SuggestReviewers(
+	ctx context.Context,
+	req dtos.CreatePRRequest) (dtos.SuggestReviewersResponse, error) {
+	if err := s.validator.ValidateSuggest(ctx, req); err != nil {
+		return dtos.SuggestReviewersResponse{}, err
+	}
+
+	author, err := s.userRepo.GetByUserID(ctx, req.Author)
+	if err != nil {
+		return dtos.SuggestReviewersResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	pr := models.PullRequest{
+		PullRequestID: req.PullRequestID,
+		Title:         req.Title,
+		AuthorUserID:  author.ID,
+		Repository:    req.Repository,
+		Labels:        req.Labels,
+		ChangedFiles:  req.ChangedFiles,
+		RequiredTags:  normalizeTags(req.RequiredTags),
+	}
+
+	picks, snap, err := s.pickReviewers(ctx, pr, author, s.seeds())
+	if err != nil {
+		return dtos.SuggestReviewersResponse{}, err
+	}
+
+	candidates := snap.candidates()
+	if snap.Load == nil && len(candidates) > 0 {
+		ids := make([]int64, 0, len(candidates))
+		for _, c := range candidates {
+			ids = append(ids, c.user.ID)
+		}
+		if snap.Load, err = s.userRepo.GetOpenReviewLoad(ctx, ids); err != nil {
+			return dtos.SuggestReviewersResponse{}, errors.New(errors.CodeInternal, "internal error")
+		}
+	}
+
+	reviewers := make([]string, 0, len(picks))
+	for _, p := range picks {
+		reviewers = append(reviewers, p.userID)
+	}
+
+	return dtos.SuggestReviewersResponse{
+		PullRequestID: req.PullRequestID,
+		Reviewers:     reviewers,
+		Candidates:    rankCandidates(snap, candidates, picks),
 	}, nil
 }
 
+func (s *prService) Update(ctx context.Context, req dtos.UpdatePRRequest) (dtos.PRResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, req); err != nil {
+		return dtos.PRResponse{}, err
+	}
+
+	var resp dtos.PRResponse
+	err := s.withPRLock(ctx, req.PullRequestID, func(ctx context.Context) error {
+		var err error
+		resp, err = s.update(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (s *prService) update(ctx context.Context, req dtos.UpdatePRRequest) (dtos.PRResponse, error) {
+	pr, err := s.prRepo.GetByPullRequestID(ctx, req.PullRequestID)
+	if stdrr.Is(err, repositories.ErrNotFound) {
+		return dtos.PRResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+	}
+	if err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	if err := checkVersion(pr, req.IfMatch); err != nil {
+		return dtos.PRResponse{}, err
+	}
+
+	if pr.Status == models.PRMerged {
+		return dtos.PRResponse{}, errors.New(errors.CodePRMerged, "cannot update merged PR")
+	}
+
+	author, err := s.getUserByInternalID(ctx, pr.AuthorUserID)
+	if err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	before := mapPRToDTO(pr, author.UserID)
+
+	// The request may change only one of the branches, so they are compared
+	// once the stored values are merged in.
+	applyPRUpdate(&pr, req)
+	if pr.SourceBranch != "" && pr.SourceBranch == pr.TargetBranch {
+		return dtos.PRResponse{}, errors.New(errors.CodeValidation, "source_branch and target_branch must differ")
+	}
+
+	updated, err := s.prRepo.UpdateMetadata(ctx, pr)
+	if stdrr.Is(err, repositories.ErrNotFound) {
+		return dtos.PRResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+	}
+	if err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	out := mapPRToDTO(updated, author.UserID)
+	err = s.audit.Record(ctx, models.AuditPRUpdate, models.AuditEntityPullRequest, pr.PullRequestID, before, out)
+	if err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	if err := s.outbox.Publish(ctx, models.EventPRUpdated, prEventKey(pr.PullRequestID), out); err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	return dtos.PRResponse{PR: out}, nil
+}
+
 func (s *prService) Merge(ctx context.Context, req dtos.MergePRRequest) (dtos.PRResponse, error) {
 	if err := s.validator.ValidateMerge(ctx, req); err != nil {
 		return dtos.PRResponse{}, err
 	}
 
+	var resp dtos.PRResponse
+	err := s.withPRLock(ctx, req.PullRequestID, func(ctx context.Context) error {
+		var err error
+		resp, err = s.merge(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (s *prService) merge(ctx context.Context, req dtos.MergePRRequest) (dtos.PRResponse, error) {
 	pr, err := s.prRepo.GetByPullRequestID(ctx, req.PullRequestID)
 	if stdrr.Is(err, repositories.ErrNotFound) {
 		return dtos.PRResponse{}, errors.New(errors.CodeNotFound, "resource not found")
@@ -97,11 +337,20 @@ func (s *prService) Merge(ctx context.Context, req dtos.MergePRRequest) (dtos.PR
 		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
+	if err := checkVersion(pr, req.IfMatch); err != nil {
+		return dtos.PRResponse{}, err
+	}
+
 	author, err := s.getUserByInternalID(ctx, pr.AuthorUserID)
 	if err != nil {
 		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
+	// Only the author, or an admin of the author's team, may merge.
+	if err := s.access.RequireSelfOrTeamRole(ctx, author.ID, author.TeamID, models.TeamRoleAdmin); err != nil {
+		return dtos.PRResponse{}, err
+	}
+
 	if pr.Status == models.PRMerged {
 		return dtos.PRResponse{PR: mapPRToDTO(pr, author.UserID)}, nil
 	}
@@ -113,15 +362,41 @@ func (s *prService) Merge(ctx context.Context, req dtos.MergePRRequest) (dtos.PR
 
 	pr.Status = models.PRMerged
 	pr.UpdatedAt = &now
+	if pr.Version, err = s.prRepo.GetVersion(ctx, pr.ID); err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
 
-	return dtos.PRResponse{PR: mapPRToDTO(pr, author.UserID)}, nil
+	err = s.audit.Record(ctx, models.AuditPRMerge, models.AuditEntityPullRequest, pr.PullRequestID,
+		map[string]string{"status": models.PROpen},
+		map[string]any{"status": models.PRMerged, "mergedAt": now})
+	if err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	out := mapPRToDTO(pr, author.UserID)
+	if err := s.outbox.Publish(ctx, models.EventPRMerged, prEventKey(pr.PullRequestID), out); err != nil {
+		return dtos.PRResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	return dtos.PRResponse{PR: out}, nil
 }
 
+// Reassign holds the PR row lock while it reads the current reviewers and
+// replaces one, so concurrent reassignments of the same PR are serialized.
 func (s *prService) Reassign(ctx context.Context, req dtos.ReassignRequest) (dtos.ReassignResponse, error) {
 	if err := s.validator.ValidateReassign(ctx, req); err != nil {
 		return dtos.ReassignResponse{}, err
 	}
 
+	var resp dtos.ReassignResponse
+	err := s.withPRLock(ctx, req.PullRequestID, func(ctx context.Context) error {
+		var err error
+		resp, err = s.reassign(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (s *prService) reassign(ctx context.Context, req dtos.ReassignRequest) (dtos.ReassignResponse, error) {
 	pr, err := s.prRepo.GetByPullRequestID(ctx, req.PullRequestID)
 	if stdrr.Is(err, repositories.ErrNotFound) {
 		return dtos.ReassignResponse{}, errors.New(errors.CodeNotFound, "resource not found")
@@ -130,6 +405,10 @@ func (s *prService) Reassign(ctx context.Context, req dtos.ReassignRequest) (dto
 		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
+	if err := checkVersion(pr, req.IfMatch); err != nil {
+		return dtos.ReassignResponse{}, err
+	}
+
 	if pr.Status == models.PRMerged || !contains(pr.Reviewers, req.OldUserID) {
 		return dtos.ReassignResponse{}, errors.New(errors.CodePRMerged, "cannot reassign on merged PR")
 	}
@@ -142,9 +421,9 @@ func (s *prService) Reassign(ctx context.Context, req dtos.ReassignRequest) (dto
 		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
-	teamMembers, err := s.userRepo.GetTeamMembers(ctx, oldUser.TeamID)
-	if err != nil {
-		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
+	// Reviewers decline their own assignments; leads may reassign for them.
+	if err := s.access.RequireSelfOrTeamRole(ctx, oldUser.ID, oldUser.TeamID, models.TeamRoleLead); err != nil {
+		return dtos.ReassignResponse{}, err
 	}
 
 	author, err := s.getUserByInternalID(ctx, pr.AuthorUserID)
@@ -152,79 +431,222 @@ func (s *prService) Reassign(ctx context.Context, req dtos.ReassignRequest) (dto
 		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
-	exclude := append(pr.Reviewers, author.UserID)
-	candidates := s.selectReviewers(teamMembers, exclude, 1)
-
-	if len(candidates) == 0 {
-		return dtos.ReassignResponse{}, errors.New(errors.CodeNoCandidate, "cannot reassign on merged PR")
+	assignment, err := s.prRepo.GetAssignment(ctx, pr.ID, oldUser.ID)
+	if err != nil {
+		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
-	newReviewerUserID := candidates[0]
-	newReviewer, err := s.userRepo.GetByUserID(ctx, newReviewerUserID)
+	seed := s.seeds()
+	pick, snap, err := s.pickReplacement(ctx, pr, author, oldUser, assignment, seed)
 	if err != nil {
-		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
+		return dtos.ReassignResponse{}, err
 	}
+	newReviewerUserID := pick.userID
 
-	slot, err := s.userRepo.GetReviewerSlot(ctx, pr.ID, oldUser.ID)
+	newReviewer, err := s.userRepo.GetByUserID(ctx, newReviewerUserID)
 	if err != nil {
 		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
-	if err := s.prRepo.RemoveReviewer(ctx, pr.ID, oldUser.ID); err != nil {
+	if err := s.prRepo.ReplaceReviewer(ctx, pr.ID, oldUser.ID, newReviewer.ID, assignment.Slot); err != nil {
+		if stdrr.Is(err, repositories.ErrConflict) {
+			return dtos.ReassignResponse{}, conflictError()
+		}
 		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
-	if err := s.prRepo.AddReviewer(ctx, pr.ID, newReviewer.ID, slot); err != nil {
+
+	explanation := explainReplacement(snap, assignment.Slot, pick)
+	err = s.recordAssignment(ctx, pr.ID, models.AssignmentReassign, seed, snap,
+		[]string{newReviewerUserID}, []models.AssignmentExplanation{explanation})
+	if err != nil {
 		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
+	before := append([]string(nil), pr.Reviewers...)
 	for i, r := range pr.Reviewers {
 		if r == req.OldUserID {
 			pr.Reviewers[i] = newReviewerUserID
 			break
 		}
 	}
+	err = s.audit.Record(ctx, models.AuditPRReassign, models.AuditEntityPullRequest, pr.PullRequestID,
+		map[string][]string{"assigned_reviewers": before},
+		map[string]any{"assigned_reviewers": pr.Reviewers, "replaced": map[string]string{req.OldUserID: newReviewerUserID}})
+	if err != nil {
+		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	err = s.outbox.Publish(ctx, models.EventReviewerReassigned, prEventKey(pr.PullRequestID), dtos.ReviewerEventData{
+		PullRequestID:      pr.PullRequestID,
+		ReviewerID:         newReviewerUserID,
+		ReplacedReviewerID: req.OldUserID,
+	})
+	if err != nil {
+		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	if pr.Version, err = s.prRepo.GetVersion(ctx, pr.ID); err != nil {
+		return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	out := mapPRToDTO(pr, author.UserID)
+	if len(pr.RequiredTags) > 0 {
+		reviewers, err := s.userRepo.GetByUserIDs(ctx, pr.Reviewers)
+		if err != nil {
+			return dtos.ReassignResponse{}, errors.New(errors.CodeInternal, "internal error")
+		}
+		out.ReviewerMatches = reviewerMatches(reviewers, pr.RequiredTags)
+	}
 
 	return dtos.ReassignResponse{
-		PR:         mapPRToDTO(pr, author.UserID),
+		PR:         out,
 		ReplacedBy: newReviewerUserID,
 	}, nil
 }
 
-func (s *prService) getUserByInternalID(ctx context.Context, internalID int64) (models.User, error) {
-	return s.userRepo.GetByInternalID(ctx, internalID)
+// Explain returns why each current reviewer, or only userID when given, was
+// picked. Reviewers assigned before explanations were recorded are omitted.
+func (s *prService) Explain(ctx context.Context, prID, userID string) (dtos.ExplainResponse, error) {
+	pr, err := s.prRepo.GetByPullRequestID(ctx, prID)
+	if stdrr.Is(err, repositories.ErrNotFound) {
+		return dtos.ExplainResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+	}
+	if err != nil {
+		return dtos.ExplainResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	reviewers := pr.Reviewers
+	if userID != "" {
+		if !contains(pr.Reviewers, userID) {
+			return dtos.ExplainResponse{}, errors.New(errors.CodeNotAssigned, "reviewer is not assigned to this PR")
+		}
+		reviewers = []string{userID}
+	}
+
+	explanations, err := s.eventRepo.ListExplanations(ctx, pr.ID)
+	if err != nil {
+		return dtos.ExplainResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	out := make([]dtos.SlotExplanationDTO, 0, len(reviewers))
+	for _, r := range reviewers {
+		// Explanations come newest first, so the first match is the current one.
+		for _, e := range explanations {
+			if e.ReviewerUserID == r {
+				out = append(out, mapExplanationToDTO(e))
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Slot < out[j].Slot })
+
+	return dtos.ExplainResponse{
+		PullRequestID:     pr.PullRequestID,
+		AssignedReviewers: pr.Reviewers,
+		Explanations:      out,
+	}, nil
 }
 
-func (s *prService) selectReviewers(members []models.User, exclude interface{}, limit int) []string {
-	var excludeSet map[string]bool
-	switch v := exclude.(type) {
-	case string:
-		excludeSet = map[string]bool{v: true}
-	case []string:
-		excludeSet = make(map[string]bool, len(v))
-		for _, e := range v {
-			excludeSet[e] = true
+func (s *prService) ListAssignmentEvents(
+	ctx context.Context,
+	prID string) (dtos.AssignmentEventListResponse, error) {
+	if _, err := s.prRepo.GetByPullRequestID(ctx, prID); err != nil {
+		if stdrr.Is(err, repositories.ErrNotFound) {
+			return dtos.AssignmentEventListResponse{}, errors.New(errors.CodeNotFound, "resource not found")
 		}
+		return dtos.AssignmentEventListResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	events, err := s.eventRepo.ListByPullRequestID(ctx, prID)
+	if err != nil {
+		return dtos.AssignmentEventListResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	out := make([]dtos.AssignmentEventDTO, 0, len(events))
+	for _, e := range events {
+		out = append(out, mapAssignmentEventToDTO(e))
+	}
+	return dtos.AssignmentEventListResponse{PullRequestID: prID, Events: out}, nil
+}
+
+// ReplayAssignment re-runs a recorded decision on its stored seed and candidate
+// snapshot. Nothing is written; the response tells whether the outcome matches.
+func (s *prService) ReplayAssignment(
+	ctx context.Context,
+	req dtos.ReplayAssignmentRequest) (dtos.ReplayAssignmentResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, req.EventID)
+	if stdrr.Is(err, repositories.ErrNotFound) {
+		return dtos.ReplayAssignmentResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+	}
+	if err != nil {
+		return dtos.ReplayAssignmentResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
-	var candidates []string
-	for _, m := range members {
-		if m.IsActive && !excludeSet[m.UserID] {
-			candidates = append(candidates, m.UserID)
+	var snap selectionSnapshot
+	if event.Kind != models.AssignmentBulkReassign {
+		if err := json.Unmarshal(event.Snapshot, &snap); err != nil {
+			return dtos.ReplayAssignmentResponse{}, errors.New(errors.CodeInternal, "internal error")
 		}
 	}
 
-	if len(candidates) == 0 {
-		return nil
+	rng := rand.New(rand.NewSource(event.Seed))
+	replayed := []string{}
+	switch event.Kind {
+	case models.AssignmentBulkReassign:
+		if replayed, err = replayBulkReassignment(event.Snapshot, event.Seed); err != nil {
+			return dtos.ReplayAssignmentResponse{}, errors.New(errors.CodeInternal, "internal error")
+		}
+	case models.AssignmentCreate:
+		var picks []reviewerPick
+		picks, err = decideReviewers(snap, rng)
+		for _, p := range picks {
+			replayed = append(replayed, p.userID)
+		}
+	case models.AssignmentReassign:
+		var pick reviewerPick
+		pick, err = decideReplacement(snap, rng)
+		if pick.userID != "" {
+			replayed = append(replayed, pick.userID)
+		}
+	default:
+		return dtos.ReplayAssignmentResponse{}, errors.New(errors.CodeValidation,
+			"replay is not supported for "+event.Kind+" events")
 	}
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
 
-	if len(candidates) > limit {
-		candidates = candidates[:limit]
+	out := dtos.ReplayAssignmentResponse{
+		Event:    mapAssignmentEventToDTO(event),
+		Replayed: replayed,
+		Matches:  err == nil && slices.Equal(replayed, event.Reviewers),
 	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out, nil
+}
+
+func (s *prService) recordAssignment(
+	ctx context.Context,
+	prInternalID int64,
+	kind string,
+	seed int64,
+	snap selectionSnapshot,
+	reviewers []string,
+	explanations []models.AssignmentExplanation) error {
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	_, err = s.eventRepo.Create(ctx, models.AssignmentEvent{
+		PRID:         prInternalID,
+		Kind:         kind,
+		Seed:         seed,
+		Snapshot:     raw,
+		Reviewers:    reviewers,
+		Explanations: explanations,
+	})
+	return err
+}
 
-	return candidates
+func (s *prService) getUserByInternalID(ctx context.Context, internalID int64) (models.User, error) {
+	return s.userRepo.GetByInternalID(ctx, internalID)
 }
 
 func mapPRToDTO(pr models.PullRequest, authorUserID string) dtos.PullRequestDTO {
@@ -233,11 +655,92 @@ func mapPRToDTO(pr models.PullRequest, authorUserID string) dtos.PullRequestDTO
 		Title:             pr.Title,
 		AuthorUserID:      authorUserID,
 		Status:            pr.Status,
+		Repository:        pr.Repository,
+		SourceBranch:      pr.SourceBranch,
+		TargetBranch:      pr.TargetBranch,
+		Description:       pr.Description,
+		URL:               pr.URL,
+		Labels:            pr.Labels,
+		RequiredTags:      pr.RequiredTags,
 		AssignedReviewers: pr.Reviewers,
 		CreatedAt:         pr.CreatedAt,
+		Version:           pr.Version,
+	}
+}
+
+func mapAssignmentEventToDTO(e models.AssignmentEvent) dtos.AssignmentEventDTO {
+	return dtos.AssignmentEventDTO{
+		EventID:       e.ID,
+		PullRequestID: e.PullRequestID,
+		Kind:          e.Kind,
+		Seed:          e.Seed,
+		Reviewers:     e.Reviewers,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func mapExplanationToDTO(e models.AssignmentExplanation) dtos.SlotExplanationDTO {
+	excluded := make([]dtos.ExcludedCandidateDTO, 0, len(e.Excluded))
+	for _, x := range e.Excluded {
+		excluded = append(excluded, dtos.ExcludedCandidateDTO{UserID: x.UserID, Reason: x.Reason})
+	}
+	return dtos.SlotExplanationDTO{
+		Slot:       e.Slot,
+		ReviewerID: e.ReviewerUserID,
+		Rule:       e.Rule,
+		Detail:     e.Detail,
+		Considered: e.Considered,
+		Excluded:   excluded,
+		EventID:    e.EventID,
+		AssignedAt: e.CreatedAt,
 	}
 }
 
+func applyPRUpdate(pr *models.PullRequest, req dtos.UpdatePRRequest) {
+	if req.Title != nil {
+		pr.Title = *req.Title
+	}
+	if req.Repository != nil {
+		pr.Repository = *req.Repository
+	}
+	if req.SourceBranch != nil {
+		pr.SourceBranch = *req.SourceBranch
+	}
+	if req.TargetBranch != nil {
+		pr.TargetBranch = *req.TargetBranch
+	}
+	if req.Description != nil {
+		pr.Description = *req.Description
+	}
+	if req.URL != nil {
+		pr.URL = *req.URL
+	}
+	if req.Labels != nil {
+		pr.Labels = *req.Labels
+	}
+}
+
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && !contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func remove(slice []string, val string) []string {
+	out := make([]string, 0, len(slice))
+	for _, v := range slice {
+		if v != val {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func contains(slice []string, val string) bool {
 	for _, v := range slice {
 		if v == val {
@@ -246,3 +749,36 @@ func contains(slice []string, val string) bool {
 	}
 	return false
 }
+
+// withPRLock runs fn in a transaction holding the row lock of the PR.
+func (s *prService) withPRLock(ctx context.Context, prID string, fn func(ctx context.Context) error) error {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.prRepo.LockByPullRequestID(ctx, prID); err != nil {
+			if stdrr.Is(err, repositories.ErrNotFound) {
+				return errors.New(errors.CodeNotFound, "resource not found")
+			}
+			return err
+		}
+		return fn(ctx)
+	})
+	if stdrr.Is(err, repositories.ErrConflict) {
+		return conflictError()
+	}
+	if _, ok := errors.IsDomain(err); err != nil && !ok {
+		return errors.New(errors.CodeInternal, "internal error")
+	}
+	return err
+}
+
+func conflictError() error {
+	return errors.New(errors.CodeConflict, "pull request was modified concurrently, retry the request")
+}
+
+// checkVersion enforces an If-Match precondition; a nil expected version
+// always passes.
+func checkVersion(pr models.PullRequest, expected *int64) error {
+	if expected != nil && *expected != pr.Version {
+		return errors.New(errors.CodeStaleVersion, "pull request was modified since it was read")
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+// DefaultRateLimitRule applies to paths no other rule matches.
+const DefaultRateLimitRule = "default"
+
+// RateLimitStore keeps token buckets by key.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit models.RateLimit) (bool, time.Duration, error)
+}
+
+type rateRule struct {
+	prefix string
+	limit  models.RateLimit
+}
+
+// RateLimiter picks the limit for a request path and charges the client's
+// bucket for it. Every rule has its own buckets.
+type RateLimiter struct {
+	rules    []rateRule
+	fallback *models.RateLimit
+	store    RateLimitStore
+}
+
+func NewRateLimiter(limits map[string]models.RateLimit, store RateLimitStore) *RateLimiter {
+	l := &RateLimiter{store: store}
+	for prefix, limit := range limits {
+		if prefix == DefaultRateLimitRule {
+			limit := limit
+			l.fallback = &limit
+			continue
+		}
+		l.rules = append(l.rules, rateRule{prefix: prefix, limit: limit})
+	}
+	// The longest matching prefix wins.
+	sort.Slice(l.rules, func(i, j int) bool { return len(l.rules[i].prefix) > len(l.rules[j].prefix) })
+	return l
+}
+
+// Allow takes a token for client on path. When the bucket is empty it returns
+// false and how long to wait. Store failures let the request through.
+func (l *RateLimiter) Allow(ctx context.Context, path, client string) (bool, time.Duration) {
+	prefix, limit, ok := l.match(path)
+	if !ok {
+		return true, 0
+	}
+	allowed, wait, err := l.store.Take(ctx, prefix+"|"+client, limit)
+	if err != nil {
+		log.Printf("rate limiter: %v", err)
+		return true, 0
+	}
+	return allowed, wait
+}
+
+func (l *RateLimiter) match(path string) (string, models.RateLimit, bool) {
+	for _, r := range l.rules {
+		if path == r.prefix || strings.HasPrefix(path, strings.TrimSuffix(r.prefix, "/")+"/") {
+			return r.prefix, r.limit, true
+		}
+	}
+	if l.fallback != nil {
+		return DefaultRateLimitRule, *l.fallback, true
+	}
+	return "", models.RateLimit{}, false
+}
+
+// ParseRateLimits parses "rule=count/unit[:burst];..." where rule is a path
+// prefix such as /pullRequest or /pullRequest/create, or "default", and unit
+// is s, m or h. The burst defaults to count.
+func ParseRateLimits(s string) (map[string]models.RateLimit, error) {
+	limits := make(map[string]models.RateLimit)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		rule, spec, ok := strings.Cut(entry, "=")
+		rule, spec = strings.TrimSpace(rule), strings.TrimSpace(spec)
+		if !ok || rule == "" || (rule != DefaultRateLimitRule && !strings.HasPrefix(rule, "/")) {
+			return nil, fmt.Errorf("malformed entry %q", entry)
+		}
+
+		spec, burstRaw, hasBurst := strings.Cut(spec, ":")
+		countRaw, unit, ok := strings.Cut(spec, "/")
+		count, err := strconv.Atoi(countRaw)
+		if !ok || err != nil || count <= 0 {
+			return nil, fmt.Errorf("malformed rate in %q", entry)
+		}
+		var per time.Duration
+		switch unit {
+		case "s":
+			per = time.Second
+		case "m":
+			per = time.Minute
+		case "h":
+			per = time.Hour
+		default:
+			return nil, fmt.Errorf("unknown unit %q in %q", unit, entry)
+		}
+		burst := count
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstRaw); err != nil || burst <= 0 {
+				return nil, fmt.Errorf("malformed burst in %q", entry)
+			}
+		}
+		limits[rule] = models.RateLimit{Rate: float64(count) / per.Seconds(), Burst: burst}
+	}
+	return limits, nil
+}
+
+// MemoryRateLimitStore keeps buckets in process memory.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	// idle is how long the bucket takes to refill, after which it is
+	// indistinguishable from a new one and can be dropped.
+	idle time.Duration
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket), now: time.Now}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit models.RateLimit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Burst), last: now, idle: limit.FullAfter()}
+		s.buckets[key] = b
+	}
+	var allowed bool
+	var wait time.Duration
+	b.tokens, wait, allowed = limit.Take(b.tokens, b.last, now)
+	b.last = now
+	return allowed, wait, nil
+}
+
+// sweep drops full buckets at most once a minute.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.idle {
+			delete(s.buckets, key)
+		}
+	}
+}
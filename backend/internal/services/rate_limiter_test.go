@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := models.RateLimit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	take := func(key string) (bool, time.Duration) {
+		t.Helper()
+		allowed, wait, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return allowed, wait
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := take("a"); !ok {
+			t.Fatalf("request %d within burst rejected", i+1)
+		}
+	}
+	if ok, wait := take("a"); ok || wait != time.Second {
+		t.Fatalf("request over burst = %v, wait %v; want rejected, wait 1s", ok, wait)
+	}
+	if ok, _ := take("b"); !ok {
+		t.Fatal("another key shares the bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := take("a"); !ok {
+		t.Fatal("request after refill rejected")
+	}
+
+	// Full buckets are dropped by the next sweep, a minute later.
+	now = now.Add(time.Minute)
+	take("c")
+	if _, ok := store.buckets["a"]; ok {
+		t.Fatal("idle bucket kept after sweep")
+	}
+	if len(store.buckets) != 1 {
+		t.Fatalf("%d buckets after sweep, want 1", len(store.buckets))
+	}
+}
+
+func TestRateLimiterMatchesLongestPrefix(t *testing.T) {
+	limits, err := ParseRateLimits("default=100/m; /pullRequest=10/s; /pullRequest/create=1/h:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewRateLimiter(limits, NewMemoryRateLimitStore())
+	cases := map[string]string{
+		"/pullRequest/create": "/pullRequest/create",
+		"/pullRequest/merge":  "/pullRequest",
+		"/pullRequestX":       DefaultRateLimitRule,
+		"/team/get":           DefaultRateLimitRule,
+	}
+	for path, want := range cases {
+		if got, _, _ := l.match(path); got != want {
+			t.Errorf("match(%q) = %q, want %q", path, got, want)
+		}
+	}
+	if _, limit, _ := l.match("/pullRequest/create"); limit.Burst != 2 || limit.Rate != 1.0/3600 {
+		t.Errorf("create limit = %+v", limit)
+	}
+
+	for _, bad := range []string{"pullRequest=1/m", "default=0/m", "default=1/d", "default=1/m:0", "default"} {
+		if _, err := ParseRateLimits(bad); err == nil {
+			t.Errorf("ParseRateLimits(%q) succeeded", bad)
+		}
+	}
+}
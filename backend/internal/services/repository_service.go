@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/codeowners"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+type RepositoryService interface {
+	Add(ctx context.Context, in dtos.AddRepositoryRequest) (dtos.RepositoryResponse, error)
+	Get(ctx context.Context, name string) (dtos.RepositoryResponse, error)
+	List(ctx context.Context) (dtos.RepositoryListResponse, error)
+	Update(ctx context.Context, in dtos.UpdateRepositoryRequest) (dtos.RepositoryResponse, error)
+	Delete(ctx context.Context, in dtos.DeleteRepositoryRequest) error
+	UploadCodeowners(ctx context.Context, in dtos.UploadCodeownersRequest) (dtos.CodeownersResponse, error)
+	GetCodeowners(ctx context.Context, name string) (dtos.CodeownersResponse, error)
+}
+
+type repositoryService struct {
+	repo      repositories.RepoRepository
+	txManager *repositories.TxManager
+	audit     *AuditService
+	validator validators.RepositoryValidator
+}
+
+func NewRepositoryService(
+	repo repositories.RepoRepository,
+	txManager *repositories.TxManager,
+	audit *AuditService,
+	validator validators.RepositoryValidator) RepositoryService {
+	return &repositoryService{repo: repo, txManager: txManager, audit: audit, validator: validator}
+}
+
+func (s *repositoryService) Add(ctx context.Context, in dtos.AddRepositoryRequest) (dtos.RepositoryResponse, error) {
+	if err := s.validator.ValidateAdd(ctx, in); err != nil {
+		return dtos.RepositoryResponse{}, err
+	}
+
+	var out dtos.RepositoryDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.repo.Create(ctx, models.Repository{
+			Name:       strings.TrimSpace(in.Name),
+			URL:        in.URL,
+			OwnerTeams: in.OwnerTeams,
+			Reviewers:  in.ReviewerPool,
+		})
+		if err != nil {
+			return err
+		}
+		out = mapRepositoryToDTO(created)
+		return s.audit.Record(ctx, models.AuditRepositoryCreate, models.AuditEntityRepository, out.Name, nil, out)
+	})
+	if err != nil {
+		return dtos.RepositoryResponse{}, mapRepositoryError(err)
+	}
+
+	return dtos.RepositoryResponse{Repository: out}, nil
+}
+
+func (s *repositoryService) Get(ctx context.Context, name string) (dtos.RepositoryResponse, error) {
+	if err := s.validator.ValidateName(ctx, name); err != nil {
+		return dtos.RepositoryResponse{}, err
+	}
+
+	repo, err := s.repo.GetByName(ctx, strings.TrimSpace(name))
+	if err != nil {
+		return dtos.RepositoryResponse{}, mapRepositoryError(err)
+	}
+
+	return dtos.RepositoryResponse{Repository: mapRepositoryToDTO(repo)}, nil
+}
+
+func (s *repositoryService) List(ctx context.Context) (dtos.RepositoryListResponse, error) {
+	repos, err := s.repo.List(ctx)
+	if err != nil {
+		return dtos.RepositoryListResponse{}, derr.New(derr.CodeInternal, "internal error")
+	}
+
+	out := make([]dtos.RepositoryDTO, 0, len(repos))
+	for _, r := range repos {
+		out = append(out, mapRepositoryToDTO(r))
+	}
+	return dtos.RepositoryListResponse{Repositories: out}, nil
+}
+
+func (s *repositoryService) Update(
+	ctx context.Context,
+	in dtos.UpdateRepositoryRequest) (dtos.RepositoryResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, in); err != nil {
+		return dtos.RepositoryResponse{}, err
+	}
+
+	var out dtos.RepositoryDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		repo, err := s.repo.GetByName(ctx, strings.TrimSpace(in.Name))
+		if err != nil {
+			return err
+		}
+		before := mapRepositoryToDTO(repo)
+
+		if in.URL != nil {
+			repo.URL = *in.URL
+		}
+		if in.OwnerTeams != nil {
+			repo.OwnerTeams = *in.OwnerTeams
+		}
+		if in.ReviewerPool != nil {
+			repo.Reviewers = *in.ReviewerPool
+		}
+
+		updated, err := s.repo.Update(ctx, repo)
+		if err != nil {
+			return err
+		}
+		out = mapRepositoryToDTO(updated)
+		return s.audit.Record(ctx, models.AuditRepositoryUpdate, models.AuditEntityRepository, out.Name, before, out)
+	})
+	if err != nil {
+		return dtos.RepositoryResponse{}, mapRepositoryError(err)
+	}
+
+	return dtos.RepositoryResponse{Repository: out}, nil
+}
+
+func (s *repositoryService) Delete(ctx context.Context, in dtos.DeleteRepositoryRequest) error {
+	if err := s.validator.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		repo, err := s.repo.GetByName(ctx, strings.TrimSpace(in.Name))
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, repo.Name); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditRepositoryDelete, models.AuditEntityRepository, repo.Name,
+			mapRepositoryToDTO(repo), nil)
+	})
+	if err != nil {
+		return mapRepositoryError(err)
+	}
+	return nil
+}
+
+func (s *repositoryService) UploadCodeowners(
+	ctx context.Context,
+	in dtos.UploadCodeownersRequest) (dtos.CodeownersResponse, error) {
+	if err := s.validator.ValidateCodeowners(ctx, in); err != nil {
+		return dtos.CodeownersResponse{}, err
+	}
+
+	name := strings.TrimSpace(in.Name)
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		repo, err := s.repo.GetByName(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SetCodeowners(ctx, name, in.Content); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditRepositorySetCodeowners, models.AuditEntityRepository, name,
+			mapCodeownersToDTO(name, repo.Codeowners), mapCodeownersToDTO(name, in.Content))
+	})
+	if err != nil {
+		return dtos.CodeownersResponse{}, mapRepositoryError(err)
+	}
+
+	return mapCodeownersToDTO(name, in.Content), nil
+}
+
+func (s *repositoryService) GetCodeowners(ctx context.Context, name string) (dtos.CodeownersResponse, error) {
+	if err := s.validator.ValidateName(ctx, name); err != nil {
+		return dtos.CodeownersResponse{}, err
+	}
+
+	repo, err := s.repo.GetByName(ctx, strings.TrimSpace(name))
+	if err != nil {
+		return dtos.CodeownersResponse{}, mapRepositoryError(err)
+	}
+
+	return mapCodeownersToDTO(repo.Name, repo.Codeowners), nil
+}
+
+func mapCodeownersToDTO(name, content string) dtos.CodeownersResponse {
+	rules := 0
+	if rs, err := codeowners.Parse(content); err == nil {
+		rules = len(rs.Rules)
+	}
+	return dtos.CodeownersResponse{Name: name, Content: content, Rules: rules}
+}
+
+func mapRepositoryError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrRepoExists):
+		return derr.New(derr.CodeRepositoryExists, "repository already exists")
+	case errors.Is(err, repositories.ErrNotFound):
+		return derr.New(derr.CodeNotFound, "resource not found")
+	default:
+		return derr.New(derr.CodeInternal, "internal error")
+	}
+}
+
+func mapRepositoryToDTO(r models.Repository) dtos.RepositoryDTO {
+	owners := r.OwnerTeams
+	if owners == nil {
+		owners = []string{}
+	}
+	pool := r.Reviewers
+	if pool == nil {
+		pool = []string{}
+	}
+	return dtos.RepositoryDTO{
+		Name:         r.Name,
+		URL:          r.URL,
+		OwnerTeams:   owners,
+		ReviewerPool: pool,
+	}
+}
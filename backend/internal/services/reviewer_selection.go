@@ -0,0 +1,1054 @@
+package services
+
+import (
+	"context"
+	stdrr "errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/codeowners"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+)
+
+const reviewersPerPR = 2
+
+type reviewerPick struct {
+	userID  string
+	groupID *int64
+
+	// Explanation of the pick: the rule that chose it and the pool it was
+	// chosen from, restricted to level when a seniority was required.
+	rule   string
+	detail string
+	pool   []models.User
+	level  string
+}
+
+type groupCandidates struct {
+	Rule    models.GroupRule `json:"rule"`
+	Members []models.User    `json:"members"`
+}
+
+// selectionSnapshot captures every input of an assignment decision. It is
+// stored together with the seed so that the decision can be replayed later.
+type selectionSnapshot struct {
+	AuthorUserID string        `json:"author_user_id"`
+	Policy       string        `json:"policy"`
+	RequiredTags []string      `json:"required_tags,omitempty"`
+	Load         map[int64]int `json:"load,omitempty"`
+	Pairs        map[int64]int `json:"pairs,omitempty"`
+	// MaxOpenReviews caps the open reviews of a candidate; zero is no cap.
+	MaxOpenReviews int `json:"max_open_reviews,omitempty"`
+
+	Tiers     [][]models.User          `json:"tiers,omitempty"`
+	TierRules []string                 `json:"tier_rules,omitempty"`
+	Groups    []groupCandidates        `json:"groups,omitempty"`
+	Owners    map[string][]models.User `json:"owners,omitempty"`
+
+	// Reassignment only.
+	Reviewers       []string      `json:"reviewers,omitempty"`
+	OldUserID       string        `json:"old_user_id,omitempty"`
+	OldSeniority    string        `json:"old_seniority,omitempty"`
+	Others          []models.User `json:"others,omitempty"`
+	RequiredGroupID *int64        `json:"required_group_id,omitempty"`
+	GroupMembers    []models.User `json:"group_members,omitempty"`
+}
+
+func (snap selectionSnapshot) criteria(rng *rand.Rand) selectionCriteria {
+	return selectionCriteria{
+		requiredTags:   snap.RequiredTags,
+		load:           snap.Load,
+		pairs:          snap.Pairs,
+		maxOpenReviews: snap.MaxOpenReviews,
+		rng:            rng,
+	}
+}
+
+func (snap selectionSnapshot) overCapacity(u models.User) bool {
+	return overCapacity(snap.Load, snap.MaxOpenReviews, u)
+}
+
+// overCapacity tells whether u already holds as many open reviews as the cap
+// allows.
+func overCapacity(load map[int64]int, maxOpenReviews int, u models.User) bool {
+	return maxOpenReviews > 0 && load[u.ID] >= maxOpenReviews
+}
+
+// pickReviewers chooses reviewers for a new PR using a generator seeded with
+// seed, and returns the inputs the decision was based on.
+func (s *prService) pickReviewers(
+	ctx context.Context,
+	pr models.PullRequest,
+	author models.User,
+	seed int64) ([]reviewerPick, selectionSnapshot, error) {
+	snap, err := s.snapshotForCreate(ctx, pr, author)
+	if err != nil {
+		return nil, selectionSnapshot{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	picks, err := decideReviewers(snap, rand.New(rand.NewSource(seed)))
+	return picks, snap, err
+}
+
+// pickReplacement chooses who takes over the slot of oldUser using a generator
+// seeded with seed, and returns the inputs the decision was based on.
+func (s *prService) pickReplacement(
+	ctx context.Context,
+	pr models.PullRequest,
+	author models.User,
+	oldUser models.User,
+	assignment models.ReviewAssignment,
+	seed int64) (reviewerPick, selectionSnapshot, error) {
+	snap, err := s.snapshotForReplacement(ctx, pr, author, oldUser, assignment)
+	if err != nil {
+		return reviewerPick{}, selectionSnapshot{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	pick, err := decideReplacement(snap, rand.New(rand.NewSource(seed)))
+	return pick, snap, err
+}
+
+func (s *prService) snapshotForCreate(
+	ctx context.Context,
+	pr models.PullRequest,
+	author models.User) (selectionSnapshot, error) {
+	snap := selectionSnapshot{
+		AuthorUserID:   author.UserID,
+		RequiredTags:   pr.RequiredTags,
+		MaxOpenReviews: s.maxOpenReviews,
+	}
+
+	var err error
+	if snap.Tiers, snap.TierRules, err = s.candidateTiers(ctx, pr.Repository, author.TeamID); err != nil {
+		return selectionSnapshot{}, err
+	}
+	if snap.Groups, err = s.requiredGroups(ctx, pr.Labels, pr.Title); err != nil {
+		return selectionSnapshot{}, err
+	}
+	if snap.Policy, err = s.teamPolicy(ctx, author.TeamID); err != nil {
+		return selectionSnapshot{}, err
+	}
+	if snap.Owners, err = s.codeOwnersByPath(ctx, pr.Repository, pr.ChangedFiles); err != nil {
+		return selectionSnapshot{}, err
+	}
+
+	pools := snap.Tiers
+	for _, g := range snap.Groups {
+		pools = append(pools, g.Members)
+	}
+	if err := s.loadCriteria(ctx, author.ID, pools, &snap); err != nil {
+		return selectionSnapshot{}, err
+	}
+	return snap, nil
+}
+
+func (s *prService) snapshotForReplacement(
+	ctx context.Context,
+	pr models.PullRequest,
+	author models.User,
+	oldUser models.User,
+	assignment models.ReviewAssignment) (selectionSnapshot, error) {
+	snap := selectionSnapshot{
+		AuthorUserID:    author.UserID,
+		RequiredTags:    pr.RequiredTags,
+		Reviewers:       pr.Reviewers,
+		OldUserID:       oldUser.UserID,
+		OldSeniority:    oldUser.Seniority,
+		RequiredGroupID: assignment.RequiredGroupID,
+		MaxOpenReviews:  s.maxOpenReviews,
+	}
+
+	var err error
+	if snap.Policy, err = s.teamPolicy(ctx, author.TeamID); err != nil {
+		return selectionSnapshot{}, err
+	}
+	if snap.Policy != models.PolicyNone {
+		if snap.Others, err = s.userRepo.GetByUserIDs(ctx, remove(pr.Reviewers, oldUser.UserID)); err != nil {
+			return selectionSnapshot{}, err
+		}
+	}
+
+	var pools [][]models.User
+	if assignment.RequiredGroupID != nil {
+		if snap.GroupMembers, err = s.groupRepo.GetMembers(ctx, *assignment.RequiredGroupID); err != nil {
+			return selectionSnapshot{}, err
+		}
+		pools = [][]models.User{snap.GroupMembers}
+	} else {
+		if snap.Tiers, snap.TierRules, err = s.candidateTiers(ctx, pr.Repository, oldUser.TeamID); err != nil {
+			return selectionSnapshot{}, err
+		}
+		if snap.Owners, err = s.codeOwnersByPath(ctx, pr.Repository, pr.ChangedFiles); err != nil {
+			return selectionSnapshot{}, err
+		}
+		pools = snap.Tiers
+	}
+
+	if err := s.loadCriteria(ctx, author.ID, pools, &snap); err != nil {
+		return selectionSnapshot{}, err
+	}
+	return snap, nil
+}
+
+// decideReviewers fills the slots of a new PR. Slots required by reviewer
+// groups are filled first, then a code owner of the touched paths, then the
+// seniority levels demanded by the author's team policy, and the rest from the
+// repository pools and the author's team.
+func decideReviewers(snap selectionSnapshot, rng *rand.Rand) ([]reviewerPick, error) {
+	crit := snap.criteria(rng)
+
+	exclude := []string{snap.AuthorUserID}
+	picks, err := selectGroupReviewers(snap.Groups, exclude, crit)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range picks {
+		exclude = append(exclude, p.userID)
+	}
+
+	if !coversCodeOwners(snap.Owners, exclude[1:]) {
+		if len(picks) >= reviewersPerPR {
+			return nil, errors.New(errors.CodeCodeOwner, "reviewer groups leave no slot for a code owner")
+		}
+		owner := selectCodeOwner(snap.Owners, exclude, crit)
+		if owner == "" {
+			return nil, codeOwnerError()
+		}
+		picks = append(picks, codeOwnerPick(snap.Owners, owner))
+		exclude = append(exclude, owner)
+	}
+
+	known := indexUsers(snap.Tiers...)
+	for _, g := range snap.Groups {
+		for _, u := range g.Members {
+			known[u.UserID] = u
+		}
+	}
+	for _, users := range snap.Owners {
+		for _, u := range users {
+			known[u.UserID] = u
+		}
+	}
+	picked := make([]models.User, 0, len(picks))
+	for _, p := range picks {
+		picked = append(picked, known[p.userID])
+	}
+
+	for _, level := range unmetLevels(snap.Policy, picked) {
+		if len(picks) >= reviewersPerPR {
+			return nil, seniorityError(snap.Policy, level)
+		}
+		found := selectFromTiers(filterTiers(snap.Tiers, hasSeniority(level)), snap.TierRules, exclude, 1, crit)
+		if len(found) == 0 {
+			return nil, seniorityError(snap.Policy, level)
+		}
+		picks = append(picks, seniorityPick(found[0], snap, level))
+		exclude = append(exclude, found[0].userID)
+	}
+
+	picks = append(picks, selectFromTiers(snap.Tiers, snap.TierRules, exclude, reviewersPerPR-len(picks), crit)...)
+	return picks, nil
+}
+
+// decideReplacement picks the successor of the old reviewer. A slot reserved
+// for a reviewer group is only ever refilled from that group, and the seniority
+// composition required by the team policy is kept.
+func decideReplacement(snap selectionSnapshot, rng *rand.Rand) (reviewerPick, error) {
+	crit := snap.criteria(rng)
+	exclude := append(append([]string{}, snap.Reviewers...), snap.AuthorUserID)
+	remaining := remove(snap.Reviewers, snap.OldUserID)
+
+	eligible := func(models.User) bool { return true }
+	var level string
+	if unmet := unmetLevels(snap.Policy, snap.Others); len(unmet) > 0 {
+		level = unmet[0]
+		if contains(unmet, snap.OldSeniority) {
+			level = snap.OldSeniority
+		}
+		eligible = hasSeniority(level)
+	}
+
+	if snap.RequiredGroupID != nil {
+		if len(availableUsers(snap.GroupMembers, exclude, crit)) == 0 {
+			return reviewerPick{}, errors.New(errors.CodeRequiredGroup, "no available reviewer in required group")
+		}
+		candidates := selectReviewers(filterUsers(snap.GroupMembers, eligible), exclude, 1, crit)
+		if len(candidates) == 0 {
+			return reviewerPick{}, seniorityError(snap.Policy, level)
+		}
+		return reviewerPick{
+			userID:  candidates[0],
+			groupID: snap.RequiredGroupID,
+			rule:    models.RuleRequiredGroup,
+			detail:  "slot is reserved for a reviewer group; " + crit.ranking(),
+			pool:    snap.GroupMembers,
+			level:   level,
+		}, nil
+	}
+
+	if !coversCodeOwners(snap.Owners, remaining) {
+		eligibleOwners := make(map[string][]models.User, len(snap.Owners))
+		for path, users := range snap.Owners {
+			eligibleOwners[path] = filterUsers(users, eligible)
+		}
+		owner := selectCodeOwner(eligibleOwners, exclude, crit)
+		if owner == "" {
+			return reviewerPick{}, codeOwnerError()
+		}
+		pick := codeOwnerPick(snap.Owners, owner)
+		pick.level = level
+		return pick, nil
+	}
+
+	candidates := selectFromTiers(filterTiers(snap.Tiers, eligible), snap.TierRules, exclude, 1, crit)
+	if len(candidates) == 0 {
+		for _, tier := range snap.Tiers {
+			if level != "" && len(availableUsers(tier, exclude, crit)) > 0 {
+				return reviewerPick{}, seniorityError(snap.Policy, level)
+			}
+		}
+		return reviewerPick{}, errors.New(errors.CodeNoCandidate, "no active replacement candidate")
+	}
+	if level != "" {
+		return seniorityPick(candidates[0], snap, level), nil
+	}
+	return candidates[0], nil
+}
+
+func codeOwnerPick(ownersByPath map[string][]models.User, owner string) reviewerPick {
+	var pool []models.User
+	seen := make(map[string]bool)
+	covered := 0
+	for _, users := range ownersByPath {
+		for _, u := range users {
+			if u.UserID == owner {
+				covered++
+			}
+			if !seen[u.UserID] {
+				seen[u.UserID] = true
+				pool = append(pool, u)
+			}
+		}
+	}
+	sort.Slice(pool, func(i, j int) bool { return pool[i].UserID < pool[j].UserID })
+
+	return reviewerPick{
+		userID: owner,
+		rule:   models.RuleCodeOwner,
+		detail: fmt.Sprintf("owns %d of %d changed paths that have code owners, the most among eligible owners", covered, len(ownersByPath)),
+		pool:   pool,
+	}
+}
+
+// seniorityPick reports a pick made to satisfy the team policy against every
+// tier candidate, so that the other levels show up as excluded.
+func seniorityPick(pick reviewerPick, snap selectionSnapshot, level string) reviewerPick {
+	var pool []models.User
+	for _, tier := range snap.Tiers {
+		pool = append(pool, tier...)
+	}
+	pick.rule = models.RuleSeniorityPolicy
+	pick.detail = fmt.Sprintf("team policy %s requires a %s reviewer; %s", snap.Policy, level, pick.detail)
+	pick.pool = pool
+	pick.level = level
+	return pick
+}
+
+// explainPick turns a pick into the stored explanation of its slot. reasonFor
+// returns why a candidate of the pool was not eligible, or an empty string.
+func explainPick(slot int, pick reviewerPick, reasonFor func(models.User) string) models.AssignmentExplanation {
+	out := models.AssignmentExplanation{
+		Slot:           slot,
+		ReviewerUserID: pick.userID,
+		Rule:           pick.rule,
+		Detail:         pick.detail,
+		Considered:     []string{},
+		Excluded:       []models.ExcludedCandidate{},
+	}
+
+	seen := make(map[string]bool)
+	for _, u := range pick.pool {
+		if seen[u.UserID] {
+			continue
+		}
+		seen[u.UserID] = true
+		out.Considered = append(out.Considered, u.UserID)
+
+		if u.UserID == pick.userID {
+			continue
+		}
+		reason := reasonFor(u)
+		if reason == "" && pick.level != "" && u.Seniority != pick.level {
+			reason = models.ExcludedSeniorityMismatch
+		}
+		if reason != "" {
+			out.Excluded = append(out.Excluded, models.ExcludedCandidate{UserID: u.UserID, Reason: reason})
+		}
+	}
+	return out
+}
+
+// explainCreate explains every slot of a new PR. Each slot sees the reviewers
+// picked for earlier slots as already assigned.
+func explainCreate(snap selectionSnapshot, picks []reviewerPick) []models.AssignmentExplanation {
+	out := make([]models.AssignmentExplanation, 0, len(picks))
+	for i, p := range picks {
+		assigned := make([]string, 0, i)
+		for _, prev := range picks[:i] {
+			assigned = append(assigned, prev.userID)
+		}
+		out = append(out, explainPick(i+1, p, func(u models.User) string {
+			switch {
+			case u.UserID == snap.AuthorUserID:
+				return models.ExcludedAuthor
+			case contains(assigned, u.UserID):
+				return models.ExcludedAlreadyAssigned
+			case !u.IsActive:
+				return models.ExcludedInactive
+			case snap.overCapacity(u):
+				return models.ExcludedOverCapacity
+			}
+			return ""
+		}))
+	}
+	return out
+}
+
+func explainReplacement(snap selectionSnapshot, slot int, pick reviewerPick) models.AssignmentExplanation {
+	return explainPick(slot, pick, func(u models.User) string {
+		switch {
+		case u.UserID == snap.AuthorUserID:
+			return models.ExcludedAuthor
+		case u.UserID == snap.OldUserID:
+			return models.ExcludedReplaced
+		case contains(snap.Reviewers, u.UserID):
+			return models.ExcludedAlreadyAssigned
+		case !u.IsActive:
+			return models.ExcludedInactive
+		case snap.overCapacity(u):
+			return models.ExcludedOverCapacity
+		}
+		return ""
+	})
+}
+
+type poolCandidate struct {
+	user models.User
+	tier int
+	rule string
+}
+
+// candidates lists every eligible user of the snapshot once, with the most
+// preferred pool they belong to. Required groups and code owners rank after
+// the regular tiers since they only win reserved slots.
+func (snap selectionSnapshot) candidates() []poolCandidate {
+	var out []poolCandidate
+	seen := make(map[string]bool)
+	add := func(u models.User, tier int, rule string) {
+		if seen[u.UserID] || !u.IsActive || snap.overCapacity(u) || u.UserID == snap.AuthorUserID {
+			return
+		}
+		seen[u.UserID] = true
+		out = append(out, poolCandidate{user: u, tier: tier, rule: rule})
+	}
+
+	for i, tier := range snap.Tiers {
+		rule := models.RuleTeam
+		if i < len(snap.TierRules) {
+			rule = snap.TierRules[i]
+		}
+		for _, u := range tier {
+			add(u, i, rule)
+		}
+	}
+	for _, g := range snap.Groups {
+		for _, u := range g.Members {
+			add(u, len(snap.Tiers), models.RuleRequiredGroup)
+		}
+	}
+	for _, users := range snap.Owners {
+		for _, u := range users {
+			add(u, len(snap.Tiers), models.RuleCodeOwner)
+		}
+	}
+	return out
+}
+
+// candidateScore mirrors the selection preferences: earlier tiers first, then
+// required tag overlap, then fewer recent reviews of the author and a lighter
+// open review load.
+func candidateScore(snap selectionSnapshot, c poolCandidate) int {
+	matched := len(matchedTags(c.user.Tags, snap.RequiredTags))
+	return 100*(len(snap.Tiers)+1-c.tier) + 10*matched - snap.Pairs[c.user.ID] - snap.Load[c.user.ID]
+}
+
+// rankCandidates puts the picked reviewers first, in slot order, followed by
+// the rest of the pool by descending score.
+func rankCandidates(
+	snap selectionSnapshot,
+	candidates []poolCandidate,
+	picks []reviewerPick) []dtos.SuggestedReviewerDTO {
+	pickIdx := make(map[string]int, len(picks))
+	for i, p := range picks {
+		pickIdx[p.userID] = i
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		pi, iPicked := pickIdx[candidates[i].user.UserID]
+		pj, jPicked := pickIdx[candidates[j].user.UserID]
+		if iPicked != jPicked {
+			return iPicked
+		}
+		if iPicked {
+			return pi < pj
+		}
+		si, sj := candidateScore(snap, candidates[i]), candidateScore(snap, candidates[j])
+		if si != sj {
+			return si > sj
+		}
+		return candidates[i].user.UserID < candidates[j].user.UserID
+	})
+
+	out := make([]dtos.SuggestedReviewerDTO, 0, len(candidates))
+	for i, c := range candidates {
+		rule := c.rule
+		idx, picked := pickIdx[c.user.UserID]
+		if picked {
+			rule = picks[idx].rule
+		}
+		matched := matchedTags(c.user.Tags, snap.RequiredTags)
+		if matched == nil {
+			matched = []string{}
+		}
+		out = append(out, dtos.SuggestedReviewerDTO{
+			UserID:      c.user.UserID,
+			Username:    c.user.Name,
+			Rank:        i + 1,
+			Score:       candidateScore(snap, c),
+			Selected:    picked,
+			Rule:        rule,
+			Seniority:   c.user.Seniority,
+			MatchedTags: matched,
+			RecentPairs: snap.Pairs[c.user.ID],
+			OpenReviews: snap.Load[c.user.ID],
+		})
+	}
+	return out
+}
+
+func (s *prService) teamPolicy(ctx context.Context, teamID int64) (string, error) {
+	team, err := s.teamRepo.GetByID(ctx, teamID)
+	if stdrr.Is(err, repositories.ErrNotFound) {
+		return models.PolicyNone, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if team.ReviewPolicy == "" {
+		return models.PolicyNone, nil
+	}
+	return team.ReviewPolicy, nil
+}
+
+// unmetLevels lists seniority levels the policy demands that none of the
+// reviewers provides yet.
+func unmetLevels(policy string, reviewers []models.User) []string {
+	var required []string
+	switch policy {
+	case models.PolicySeniorRequired:
+		required = []string{models.SenioritySenior}
+	case models.PolicyMentorPair:
+		required = []string{models.SenioritySenior, models.SeniorityJunior}
+	}
+
+	var unmet []string
+	for _, level := range required {
+		if len(filterUsers(reviewers, hasSeniority(level))) == 0 {
+			unmet = append(unmet, level)
+		}
+	}
+	return unmet
+}
+
+func seniorityError(policy, level string) error {
+	return errors.New(errors.CodeSeniorityPolicy,
+		"team policy "+policy+" requires a "+level+" reviewer, but none is available")
+}
+
+func hasSeniority(level string) func(models.User) bool {
+	return func(u models.User) bool { return u.Seniority == level }
+}
+
+func filterUsers(users []models.User, keep func(models.User) bool) []models.User {
+	var out []models.User
+	for _, u := range users {
+		if keep(u) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func filterTiers(tiers [][]models.User, keep func(models.User) bool) [][]models.User {
+	out := make([][]models.User, 0, len(tiers))
+	for _, tier := range tiers {
+		out = append(out, filterUsers(tier, keep))
+	}
+	return out
+}
+
+func availableUsers(users []models.User, exclude []string, crit selectionCriteria) []models.User {
+	return filterUsers(users, func(u models.User) bool {
+		return crit.available(u) && !contains(exclude, u.UserID)
+	})
+}
+
+func indexUsers(pools ...[]models.User) map[string]models.User {
+	out := make(map[string]models.User)
+	for _, pool := range pools {
+		for _, u := range pool {
+			out[u.UserID] = u
+		}
+	}
+	return out
+}
+
+// requiredGroups returns the reviewer groups whose rules match the PR labels or
+// title, ordered by their first matching rule.
+func (s *prService) requiredGroups(ctx context.Context, labels []string, title string) ([]groupCandidates, error) {
+	rules, err := s.groupRepo.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []groupCandidates
+	seen := make(map[int64]bool)
+	for _, rule := range rules {
+		if seen[rule.GroupID] || !ruleMatches(rule, labels, title) {
+			continue
+		}
+		seen[rule.GroupID] = true
+
+		members, err := s.groupRepo.GetMembers(ctx, rule.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, groupCandidates{Rule: rule, Members: members})
+	}
+	return groups, nil
+}
+
+func selectGroupReviewers(
+	groups []groupCandidates,
+	exclude []string,
+	crit selectionCriteria) ([]reviewerPick, error) {
+	exclude = append([]string{}, exclude...)
+
+	var picks []reviewerPick
+	for _, g := range groups {
+		if groupSatisfied(g.Members, picks) {
+			continue
+		}
+		if len(picks) >= reviewersPerPR {
+			return nil, errors.New(errors.CodeRequiredGroup,
+				"too many required reviewer groups, cannot staff group "+g.Rule.GroupName)
+		}
+
+		candidates := selectReviewers(g.Members, exclude, 1, crit)
+		if len(candidates) == 0 {
+			return nil, errors.New(errors.CodeRequiredGroup,
+				"no available reviewer in required group "+g.Rule.GroupName)
+		}
+
+		groupID := g.Rule.GroupID
+		picks = append(picks, reviewerPick{
+			userID:  candidates[0],
+			groupID: &groupID,
+			rule:    models.RuleRequiredGroup,
+			detail:  groupRuleDetail(g.Rule),
+			pool:    g.Members,
+		})
+		exclude = append(exclude, candidates[0])
+	}
+	return picks, nil
+}
+
+func groupRuleDetail(rule models.GroupRule) string {
+	if rule.Label != "" {
+		return fmt.Sprintf("label %q requires a reviewer from group %s", rule.Label, rule.GroupName)
+	}
+	return fmt.Sprintf("title pattern %q requires a reviewer from group %s", rule.TitlePattern, rule.GroupName)
+}
+
+func groupSatisfied(members []models.User, picks []reviewerPick) bool {
+	for _, p := range picks {
+		for _, m := range members {
+			if m.UserID == p.userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ruleMatches(rule models.GroupRule, labels []string, title string) bool {
+	if rule.Label != "" {
+		for _, l := range labels {
+			if strings.EqualFold(strings.TrimSpace(l), rule.Label) {
+				return true
+			}
+		}
+	}
+	if rule.TitlePattern != "" {
+		re, err := regexp.Compile("(?i)" + rule.TitlePattern)
+		if err == nil && re.MatchString(title) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidateTiers returns reviewer pools in order of preference: the repository's
+// own reviewer pool, members of the teams owning the repository and finally the
+// fallback team. Slots are filled from earlier tiers first.
+func (s *prService) candidateTiers(
+	ctx context.Context,
+	repository string,
+	teamID int64) ([][]models.User, []string, error) {
+	var tiers [][]models.User
+	var rules []string
+
+	if repository != "" {
+		repo, err := s.repoRepo.GetByName(ctx, repository)
+		switch {
+		case err == nil:
+			pool, err := s.repoRepo.GetReviewerPool(ctx, repo.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			owners, err := s.repoRepo.GetOwnerTeamMembers(ctx, repo.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			tiers = append(tiers, pool, owners)
+			rules = append(rules, models.RuleRepositoryPool, models.RuleOwnerTeams)
+		case !stdrr.Is(err, repositories.ErrNotFound):
+			return nil, nil, err
+		}
+	}
+
+	teamMembers, err := s.userRepo.GetTeamMembers(ctx, teamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(tiers, teamMembers), append(rules, models.RuleTeam), nil
+}
+
+// codeOwnersByPath resolves CODEOWNERS of the repository for the changed paths
+// into users. Paths without owners are omitted.
+func (s *prService) codeOwnersByPath(
+	ctx context.Context,
+	repository string,
+	changedFiles []string) (map[string][]models.User, error) {
+	if repository == "" || len(changedFiles) == 0 {
+		return nil, nil
+	}
+
+	repo, err := s.repoRepo.GetByName(ctx, repository)
+	if stdrr.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if repo.Codeowners == "" {
+		return nil, nil
+	}
+
+	rules, err := codeowners.Parse(repo.Codeowners)
+	if err != nil {
+		return nil, err
+	}
+	ownersByPath := rules.OwnersByPath(changedFiles)
+	if len(ownersByPath) == 0 {
+		return nil, nil
+	}
+
+	var userIDs, teamNames []string
+	for _, owners := range ownersByPath {
+		for _, o := range owners {
+			if codeowners.IsTeam(o) {
+				teamNames = append(teamNames, codeowners.TeamName(o))
+			} else {
+				userIDs = append(userIDs, o)
+			}
+		}
+	}
+
+	users := make(map[string]models.User)
+	if len(userIDs) > 0 {
+		found, err := s.userRepo.GetByUserIDs(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range found {
+			users[u.UserID] = u
+		}
+	}
+	teamMembers := make(map[string][]string)
+	if len(teamNames) > 0 {
+		found, byTeam, err := s.userRepo.GetMembersByTeamNames(ctx, teamNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range found {
+			users[u.UserID] = u
+		}
+		teamMembers = byTeam
+	}
+
+	result := make(map[string][]models.User, len(ownersByPath))
+	for path, owners := range ownersByPath {
+		seen := make(map[string]bool)
+		for _, o := range owners {
+			ids := []string{o}
+			if codeowners.IsTeam(o) {
+				ids = teamMembers[codeowners.TeamName(o)]
+			}
+			for _, id := range ids {
+				if u, ok := users[id]; ok && !seen[id] {
+					seen[id] = true
+					result[path] = append(result[path], u)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// selectCodeOwner picks the eligible owner covering the most touched paths,
+// breaking ties randomly. It returns an empty string when nobody qualifies.
+func selectCodeOwner(ownersByPath map[string][]models.User, exclude []string, crit selectionCriteria) string {
+	coverage := make(map[string]int)
+	for _, owners := range ownersByPath {
+		for _, u := range owners {
+			if crit.available(u) && !contains(exclude, u.UserID) {
+				coverage[u.UserID]++
+			}
+		}
+	}
+	if len(coverage) == 0 {
+		return ""
+	}
+
+	candidates := make([]string, 0, len(coverage))
+	for id := range coverage {
+		candidates = append(candidates, id)
+	}
+	sort.Strings(candidates)
+	crit.rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	best := candidates[0]
+	for _, id := range candidates[1:] {
+		if coverage[id] > coverage[best] {
+			best = id
+		}
+	}
+	return best
+}
+
+func coversCodeOwners(ownersByPath map[string][]models.User, reviewers []string) bool {
+	if len(ownersByPath) == 0 {
+		return true
+	}
+	for _, owners := range ownersByPath {
+		for _, u := range owners {
+			if contains(reviewers, u.UserID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// codeOwnerError reports that no owner of the touched paths is active, free of
+// the PR and below the review cap.
+func codeOwnerError() error {
+	return errors.New(errors.CodeCodeOwner, "no available code owner for the changed files")
+}
+
+type selectionCriteria struct {
+	requiredTags   []string
+	load           map[int64]int
+	pairs          map[int64]int
+	maxOpenReviews int
+	rng            *rand.Rand
+}
+
+// available tells whether u may take another review.
+func (c selectionCriteria) available(u models.User) bool {
+	return u.IsActive && !overCapacity(c.load, c.maxOpenReviews, u)
+}
+
+// ranking describes how candidates of a pool were ordered.
+func (c selectionCriteria) ranking() string {
+	if len(c.requiredTags) > 0 {
+		return "ranked by required tag match, then recent reviews of the author, then open review load"
+	}
+	return "ranked by recent reviews of the author, ties broken randomly"
+}
+
+// loadCriteria records how often each candidate recently reviewed the author,
+// so that selection spreads pairs, and the open review load when the PR asks
+// for specific expertise or reviewers have a cap on open reviews.
+func (s *prService) loadCriteria(
+	ctx context.Context,
+	authorID int64,
+	pools [][]models.User,
+	snap *selectionSnapshot) error {
+	var ids []int64
+	for _, pool := range pools {
+		for _, u := range pool {
+			ids = append(ids, u.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if s.pairWindow > 0 {
+		pairs, err := s.userRepo.GetPairHistory(ctx, authorID, ids, time.Now().Add(-s.pairWindow))
+		if err != nil {
+			return err
+		}
+		snap.Pairs = pairs
+	}
+
+	if len(snap.RequiredTags) == 0 && snap.MaxOpenReviews == 0 {
+		return nil
+	}
+	load, err := s.userRepo.GetOpenReviewLoad(ctx, ids)
+	if err != nil {
+		return err
+	}
+	snap.Load = load
+	return nil
+}
+
+func selectFromTiers(
+	tiers [][]models.User,
+	tierRules []string,
+	exclude []string,
+	limit int,
+	crit selectionCriteria) []reviewerPick {
+	exclude = append([]string{}, exclude...)
+
+	var selected []reviewerPick
+	for i, tier := range tiers {
+		if len(selected) >= limit {
+			break
+		}
+		rule := models.RuleTeam
+		if i < len(tierRules) {
+			rule = tierRules[i]
+		}
+		for _, id := range selectReviewers(tier, exclude, limit-len(selected), crit) {
+			selected = append(selected, reviewerPick{userID: id, rule: rule, detail: crit.ranking(), pool: tier})
+			exclude = append(exclude, id)
+		}
+	}
+	return selected
+}
+
+func selectReviewers(
+	members []models.User,
+	exclude interface{},
+	limit int,
+	crit selectionCriteria) []string {
+	var excludeSet map[string]bool
+	switch v := exclude.(type) {
+	case string:
+		excludeSet = map[string]bool{v: true}
+	case []string:
+		excludeSet = make(map[string]bool, len(v))
+		for _, e := range v {
+			excludeSet[e] = true
+		}
+	}
+
+	var candidates []models.User
+	for _, m := range members {
+		if crit.available(m) && !excludeSet[m.UserID] {
+			candidates = append(candidates, m)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	// The shuffle starts from a fixed order, so that the same seed always
+	// yields the same picks however the pool was read.
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	crit.rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	// Ties keep the shuffled order, so selection stays random among equals.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(crit.requiredTags) > 0 {
+			si := len(matchedTags(candidates[i].Tags, crit.requiredTags))
+			sj := len(matchedTags(candidates[j].Tags, crit.requiredTags))
+			if si != sj {
+				return si > sj
+			}
+		}
+		pi, pj := crit.pairs[candidates[i].ID], crit.pairs[candidates[j].ID]
+		if pi != pj {
+			return pi < pj
+		}
+		return crit.load[candidates[i].ID] < crit.load[candidates[j].ID]
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.UserID)
+	}
+	return ids
+}
+
+func matchedTags(userTags, required []string) []string {
+	var matched []string
+	for _, t := range required {
+		if contains(userTags, t) {
+			matched = append(matched, t)
+		}
+	}
+	return matched
+}
+
+// reviewerMatches explains which required tags each reviewer matched.
+func reviewerMatches(reviewers []models.User, required []string) map[string][]string {
+	if len(required) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(reviewers))
+	for _, r := range reviewers {
+		m := matchedTags(r.Tags, required)
+		if m == nil {
+			m = []string{}
+		}
+		out[r.UserID] = m
+	}
+	return out
+}
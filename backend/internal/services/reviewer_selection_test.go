@@ -0,0 +1,156 @@
+package services
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+func wantCode(t *testing.T, err error, code derr.Code) {
+	t.Helper()
+	if de, ok := derr.IsDomain(err); !ok || de.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+}
+
+func TestDecideReviewersPlacesCodeOwner(t *testing.T) {
+	users := bulkUsers(1, 2, 3, 4, 5)
+	snap := selectionSnapshot{
+		AuthorUserID: "u1",
+		Tiers:        [][]models.User{users},
+		Owners:       map[string][]models.User{"billing/invoice.go": {users[4]}},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		picks, err := decideReviewers(snap, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(picks) != reviewersPerPR || picks[0].userID != "u5" || picks[0].rule != models.RuleCodeOwner {
+			t.Fatalf("seed %d: picks = %+v, want code owner u5 first", seed, picks)
+		}
+	}
+}
+
+func TestDecideReviewersWithoutAvailableOwner(t *testing.T) {
+	users := bulkUsers(1, 2, 3, 4, 5)
+	inactive := users[4]
+	inactive.IsActive = false
+	cases := map[string]selectionSnapshot{
+		"owner inactive": {
+			AuthorUserID: "u1",
+			Tiers:        [][]models.User{users[:4]},
+			Owners:       map[string][]models.User{"billing/invoice.go": {inactive}},
+		},
+		"owner is the author": {
+			AuthorUserID: "u1",
+			Tiers:        [][]models.User{users},
+			Owners:       map[string][]models.User{"billing/invoice.go": {users[0]}},
+		},
+		"owner at cap": {
+			AuthorUserID:   "u1",
+			Tiers:          [][]models.User{users},
+			Owners:         map[string][]models.User{"billing/invoice.go": {users[4]}},
+			Load:           map[int64]int{5: 3},
+			MaxOpenReviews: 3,
+		},
+		"groups take every slot": {
+			AuthorUserID: "u1",
+			Tiers:        [][]models.User{users},
+			Groups: []groupCandidates{
+				{Rule: models.GroupRule{GroupID: 1, GroupName: "security"}, Members: users[1:2]},
+				{Rule: models.GroupRule{GroupID: 2, GroupName: "dba"}, Members: users[2:3]},
+			},
+			Owners: map[string][]models.User{"billing/invoice.go": {users[4]}},
+		},
+	}
+	for name, snap := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := decideReviewers(snap, rand.New(rand.NewSource(1)))
+			wantCode(t, err, derr.CodeCodeOwner)
+		})
+	}
+}
+
+func TestDecideReplacementKeepsCodeOwner(t *testing.T) {
+	users := bulkUsers(1, 2, 3, 4, 5)
+	snap := selectionSnapshot{
+		AuthorUserID: "u1",
+		Reviewers:    []string{"u2", "u3"},
+		OldUserID:    "u2",
+		Tiers:        [][]models.User{users},
+		Owners:       map[string][]models.User{"billing/invoice.go": {users[1], users[4]}},
+	}
+	pick, err := decideReplacement(snap, rand.New(rand.NewSource(1)))
+	if err != nil || pick.userID != "u5" {
+		t.Fatalf("replacement = %+v, %v; want code owner u5", pick, err)
+	}
+
+	snap.Owners = map[string][]models.User{"billing/invoice.go": {users[1]}}
+	_, err = decideReplacement(snap, rand.New(rand.NewSource(1)))
+	wantCode(t, err, derr.CodeCodeOwner)
+}
+
+// TestSelectionSeedIgnoresPoolOrder checks that a seed reproduces a decision
+// whatever order the database returned the candidates in.
+func TestSelectionSeedIgnoresPoolOrder(t *testing.T) {
+	users := bulkUsers(1, 2, 3, 4, 5, 6, 7, 8)
+	reversed := func(pool []models.User) []models.User {
+		out := slices.Clone(pool)
+		slices.Reverse(out)
+		return out
+	}
+	snapshots := func(order func([]models.User) []models.User) (selectionSnapshot, selectionSnapshot) {
+		create := selectionSnapshot{
+			AuthorUserID: "u1",
+			Tiers:        [][]models.User{order(users[:4]), order(users[4:])},
+			Groups:       []groupCandidates{{Rule: models.GroupRule{GroupID: 1, GroupName: "dba"}, Members: order(users)}},
+		}
+		replace := selectionSnapshot{
+			AuthorUserID: "u1",
+			Reviewers:    []string{"u2", "u3"},
+			OldUserID:    "u2",
+			Tiers:        [][]models.User{order(users)},
+		}
+		return create, replace
+	}
+	createA, replaceA := snapshots(slices.Clone[[]models.User])
+	createB, replaceB := snapshots(reversed)
+
+	for seed := int64(0); seed < 50; seed++ {
+		a, err := decideReviewers(createA, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := decideReviewers(createB, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pickIDs(a) != pickIDs(b) {
+			t.Fatalf("seed %d: create picks %s and %s", seed, pickIDs(a), pickIDs(b))
+		}
+
+		ra, err := decideReplacement(replaceA, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rb, err := decideReplacement(replaceB, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ra.userID != rb.userID {
+			t.Fatalf("seed %d: replacement %s and %s", seed, ra.userID, rb.userID)
+		}
+	}
+}
+
+func pickIDs(picks []reviewerPick) string {
+	ids := make([]string, 0, len(picks))
+	for _, p := range picks {
+		ids = append(ids, p.userID)
+	}
+	return strings.Join(ids, ",")
+}
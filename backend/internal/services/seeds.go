@@ -0,0 +1,27 @@
+package services
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// SeedSource yields the seed of each assignment decision. Every decision runs
+// on its own generator, so recording the seed is enough to reproduce it.
+// Implementations must be safe for concurrent use.
+type SeedSource func() int64
+
+// RandomSeeds draws seeds from the process-wide generator.
+func RandomSeeds() SeedSource {
+	return rand.Int63
+}
+
+// FixedSeeds yields a reproducible sequence of seeds derived from seed.
+func FixedSeeds(seed int64) SeedSource {
+	var mu sync.Mutex
+	r := rand.New(rand.NewSource(seed))
+	return func() int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return r.Int63()
+	}
+}
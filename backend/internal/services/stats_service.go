@@ -2,17 +2,20 @@ package services
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
 )
 
 type StatsService struct {
-	repo *repositories.StatsRepository
+	repo       *repositories.StatsRepository
+	pairWindow time.Duration
 }
 
-func NewStatsService(repo *repositories.StatsRepository) *StatsService {
-	return &StatsService{repo: repo}
+func NewStatsService(repo *repositories.StatsRepository, pairWindow time.Duration) *StatsService {
+	return &StatsService{repo: repo, pairWindow: pairWindow}
 }
 
 func (s *StatsService) GetAssignmentStats(ctx context.Context) (*dtos.AssignmentStats, error) {
@@ -37,3 +40,48 @@ func (s *StatsService) GetAssignmentStats(ctx context.Context) (*dtos.Assignment
 		TotalAssignments: total,
 	}, nil
 }
+
+// GetPairMatrix builds the author x reviewer matrix of shared reviews within
+// the window. A non-positive windowDays falls back to the configured window.
+func (s *StatsService) GetPairMatrix(ctx context.Context, windowDays int) (*dtos.PairMatrix, error) {
+	window := s.pairWindow
+	if windowDays > 0 {
+		window = time.Duration(windowDays) * 24 * time.Hour
+	}
+
+	pairs, err := s.repo.GetPairStats(ctx, time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := make(map[string]map[string]int)
+	reviewerSet := make(map[string]struct{})
+	for _, p := range pairs {
+		row, ok := matrix[p.AuthorUserID]
+		if !ok {
+			row = make(map[string]int)
+			matrix[p.AuthorUserID] = row
+		}
+		row[p.ReviewerUserID] = p.Count
+		reviewerSet[p.ReviewerUserID] = struct{}{}
+	}
+
+	authors := make([]string, 0, len(matrix))
+	for a := range matrix {
+		authors = append(authors, a)
+	}
+	reviewers := make([]string, 0, len(reviewerSet))
+	for r := range reviewerSet {
+		reviewers = append(reviewers, r)
+	}
+	sort.Strings(authors)
+	sort.Strings(reviewers)
+
+	return &dtos.PairMatrix{
+		WindowDays: int(window / (24 * time.Hour)),
+		Authors:    authors,
+		Reviewers:  reviewers,
+		Matrix:     matrix,
+		Pairs:      pairs,
+	}, nil
+}
@@ -2,7 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"log"
+	"maps"
+	"math/rand"
+	"slices"
 	"strings"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
@@ -16,26 +21,69 @@ type TeamService interface {
 	AddTeam(ctx context.Context, in dtos.AddTeamRequest) (dtos.TeamResponse, error)
 	GetTeam(ctx context.Context, name string) (dtos.TeamDTO, error)
 	BulkDeactivate(ctx context.Context, req dtos.BulkDeactivateRequest) (*dtos.BulkDeactivateResponse, error)
+	BulkDeactivateAsync(ctx context.Context, req dtos.BulkDeactivateRequest) (dtos.JobDTO, error)
+	UndoBulkDeactivate(ctx context.Context, req dtos.UndoBulkDeactivateRequest) (*dtos.UndoBulkDeactivateResponse, error)
+	SetReviewPolicy(ctx context.Context, req dtos.SetReviewPolicyRequest) (dtos.TeamResponse, error)
+	SetRole(ctx context.Context, req dtos.SetTeamRoleRequest) (dtos.SetTeamRoleResponse, error)
 }
 
 type teamService struct {
 	repo      repositories.TeamRepository
 	userRepo  repositories.UserRepository
 	prRepo    repositories.PRRepository
+	groupRepo repositories.GroupRepository
+	eventRepo repositories.AssignmentEventRepository
+	opRepo    repositories.BulkOperationRepository
+	roleRepo  repositories.TeamRoleRepository
+	txManager *repositories.TxManager
+	jobs      JobService
+	access    *AccessControl
+	audit     *AuditService
+	outbox    *Outbox
 	validator validators.TeamValidator
+	seeds     SeedSource
+
+	// maxOpenReviews caps the open reviews of a replacement reviewer; zero
+	// disables the cap.
+	maxOpenReviews int
 }
 
 func NewTeamService(
 	repo repositories.TeamRepository,
 	userRepo repositories.UserRepository,
 	prRepo repositories.PRRepository,
-	validator validators.TeamValidator) TeamService {
-	return &teamService{
+	groupRepo repositories.GroupRepository,
+	eventRepo repositories.AssignmentEventRepository,
+	opRepo repositories.BulkOperationRepository,
+	roleRepo repositories.TeamRoleRepository,
+	txManager *repositories.TxManager,
+	jobs JobService,
+	access *AccessControl,
+	audit *AuditService,
+	outbox *Outbox,
+	validator validators.TeamValidator,
+	maxOpenReviews int,
+	seeds SeedSource) TeamService {
+	s := &teamService{
 		repo:      repo,
 		userRepo:  userRepo,
 		prRepo:    prRepo,
+		groupRepo: groupRepo,
+		eventRepo: eventRepo,
+		opRepo:    opRepo,
+		roleRepo:  roleRepo,
+		txManager: txManager,
+		jobs:      jobs,
+		access:    access,
+		audit:     audit,
+		outbox:    outbox,
 		validator: validator,
+		seeds:     seeds,
+
+		maxOpenReviews: maxOpenReviews,
 	}
+	jobs.Register(models.JobBulkDeactivate, s.bulkDeactivateJob)
+	return s
 }
 
 func (s *teamService) AddTeam(ctx context.Context, in dtos.AddTeamRequest) (dtos.TeamResponse, error) {
@@ -45,19 +93,42 @@ func (s *teamService) AddTeam(ctx context.Context, in dtos.AddTeamRequest) (dtos
 	members := make([]models.User, 0, len(in.Members))
 	for _, m := range in.Members {
 		members = append(members, models.User{
-			Name:     m.Username,
-			IsActive: m.IsActive,
-			UserID:   m.UserID,
+			Name:      m.Username,
+			IsActive:  m.IsActive,
+			UserID:    m.UserID,
+			Seniority: m.Seniority,
 		})
 	}
 	teamName := strings.TrimSpace(in.TeamName)
-	if err := s.repo.CreateTeamWithMembers(ctx, teamName, members); err != nil {
-		if errors.Is(err, repositories.ErrTeamExists) {
-			return dtos.TeamResponse{}, derr.New(derr.CodeTeamExists, "team already exists")
+
+	var resp dtos.TeamResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateTeamWithMembers(ctx, teamName, members); err != nil {
+			if errors.Is(err, repositories.ErrTeamExists) {
+				return derr.New(derr.CodeTeamExists, "team already exists")
+			}
+			return err
+		}
+		team, _, err := s.repo.GetTeamWithMembers(ctx, teamName)
+		if err != nil {
+			return err
 		}
+		// The creator administers the new team, so it can appoint leads.
+		if p, ok := PrincipalFrom(ctx); ok && p.UserID != 0 {
+			if err := s.roleRepo.SetRole(ctx, team.ID, p.UserID, models.TeamRoleAdmin); err != nil {
+				return err
+			}
+			if team, _, err = s.repo.GetTeamWithMembers(ctx, teamName); err != nil {
+				return err
+			}
+		}
+		resp = dtos.TeamResponse{Team: dtos.TeamDTO{TeamName: teamName, Members: in.Members, Version: team.Version}}
+		return s.audit.Record(ctx, models.AuditTeamCreate, models.AuditEntityTeam, teamName, nil, resp.Team)
+	})
+	if err != nil {
 		return dtos.TeamResponse{}, err
 	}
-	return dtos.TeamResponse{Team: dtos.TeamDTO{TeamName: teamName, Members: in.Members}}, nil
+	return resp, nil
 }
 
 func (s *teamService) GetTeam(ctx context.Context, name string) (dtos.TeamDTO, error) {
@@ -74,41 +145,226 @@ func (s *teamService) GetTeam(ctx context.Context, name string) (dtos.TeamDTO, e
 	outMembers := make([]dtos.TeamMemberDTO, 0, len(users))
 	for _, u := range users {
 		outMembers = append(outMembers, dtos.TeamMemberDTO{
-			UserID:   u.UserID,
-			Username: u.Name,
-			IsActive: u.IsActive,
+			UserID:    u.UserID,
+			Username:  u.Name,
+			IsActive:  u.IsActive,
+			Seniority: u.Seniority,
 		})
 	}
 
 	return dtos.TeamDTO{
-		TeamName: t.Name,
-		Members:  outMembers,
+		TeamName:     t.Name,
+		ReviewPolicy: t.ReviewPolicy,
+		Members:      outMembers,
+		Version:      t.Version,
 	}, nil
 }
 
+func (s *teamService) SetReviewPolicy(
+	ctx context.Context,
+	req dtos.SetReviewPolicyRequest) (dtos.TeamResponse, error) {
+	if err := s.validator.ValidateSetReviewPolicy(ctx, req); err != nil {
+		return dtos.TeamResponse{}, err
+	}
+
+	teamName := strings.TrimSpace(req.TeamName)
+	team, err := s.teamByName(ctx, teamName)
+	if err != nil {
+		return dtos.TeamResponse{}, err
+	}
+	if err := s.access.RequireTeamRole(ctx, team.ID, models.TeamRoleLead); err != nil {
+		return dtos.TeamResponse{}, err
+	}
+
+	var out dtos.TeamDTO
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.SetReviewPolicy(ctx, teamName, req.ReviewPolicy, req.IfMatch); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "team not found")
+			}
+			if errors.Is(err, repositories.ErrVersionMismatch) {
+				return derr.New(derr.CodeStaleVersion, "team was modified since it was read")
+			}
+			return err
+		}
+		var err error
+		if out, err = s.GetTeam(ctx, teamName); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditTeamSetReviewPolicy, models.AuditEntityTeam, teamName,
+			teamPolicyState{ReviewPolicy: team.ReviewPolicy, Version: team.Version},
+			teamPolicyState{ReviewPolicy: out.ReviewPolicy, Version: out.Version})
+	})
+	if err != nil {
+		return dtos.TeamResponse{}, err
+	}
+	return dtos.TeamResponse{Team: out}, nil
+}
+
+type teamPolicyState struct {
+	ReviewPolicy string `json:"review_policy"`
+	Version      int64  `json:"version"`
+}
+
+// SetRole appoints a user to a role in a team. Only team admins may do so.
+func (s *teamService) SetRole(
+	ctx context.Context,
+	req dtos.SetTeamRoleRequest) (dtos.SetTeamRoleResponse, error) {
+	if err := s.validator.ValidateSetRole(ctx, req); err != nil {
+		return dtos.SetTeamRoleResponse{}, err
+	}
+
+	team, err := s.teamByName(ctx, strings.TrimSpace(req.TeamName))
+	if err != nil {
+		return dtos.SetTeamRoleResponse{}, err
+	}
+	if err := s.access.RequireTeamRole(ctx, team.ID, models.TeamRoleAdmin); err != nil {
+		return dtos.SetTeamRoleResponse{}, err
+	}
+
+	user, err := s.userRepo.GetByUserID(ctx, strings.TrimSpace(req.UserID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return dtos.SetTeamRoleResponse{}, derr.New(derr.CodeNotFound, "user not found")
+		}
+		return dtos.SetTeamRoleResponse{}, err
+	}
+	resp := dtos.SetTeamRoleResponse{TeamName: team.Name, UserID: user.UserID, Role: req.Role}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := s.roleRepo.GetRole(ctx, team.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.roleRepo.SetRole(ctx, team.ID, user.ID, req.Role); err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditTeamSetRole, models.AuditEntityTeam, team.Name,
+			dtos.SetTeamRoleResponse{TeamName: team.Name, UserID: user.UserID, Role: before}, resp)
+	})
+	if err != nil {
+		return dtos.SetTeamRoleResponse{}, err
+	}
+	return resp, nil
+}
+
+func (s *teamService) teamByName(ctx context.Context, name string) (models.Team, error) {
+	team, _, err := s.repo.GetTeamWithMembers(ctx, name)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return models.Team{}, derr.New(derr.CodeNotFound, "team not found")
+		}
+		return models.Team{}, err
+	}
+	return team, nil
+}
+
+// BulkDeactivate deactivates the users and restaffs their open PRs. With
+// dry_run the same work runs in a transaction that is rolled back, so the
+// response previews the outcome without changing anything.
 func (s *teamService) BulkDeactivate(
 	ctx context.Context,
 	req dtos.BulkDeactivateRequest) (*dtos.BulkDeactivateResponse, error) {
-	team, _, err := s.repo.GetTeamWithMembers(ctx, strings.TrimSpace(req.TeamName))
+	return s.runBulkDeactivate(ctx, req, nopProgress{})
+}
+
+// BulkDeactivateAsync queues the bulk deactivation as a background job. The
+// team is checked up front so an unknown team is reported synchronously.
+func (s *teamService) BulkDeactivateAsync(
+	ctx context.Context,
+	req dtos.BulkDeactivateRequest) (dtos.JobDTO, error) {
+	team, err := s.teamByName(ctx, strings.TrimSpace(req.TeamName))
 	if err != nil {
-		if errors.Is(err, repositories.ErrNotFound) {
-			return nil, derr.New(derr.CodeNotFound, "team not found")
+		return dtos.JobDTO{}, err
+	}
+	if err := s.access.RequireTeamRole(ctx, team.ID, models.TeamRoleLead); err != nil {
+		return dtos.JobDTO{}, err
+	}
+	job := bulkDeactivateJob{BulkDeactivateRequest: req, RequestID: RequestIDFrom(ctx)}
+	if p, ok := PrincipalFrom(ctx); ok {
+		job.Principal = &p
+	}
+	return s.jobs.Enqueue(ctx, models.JobBulkDeactivate, job)
+}
+
+// bulkDeactivateJob is the job payload. The caller and request ID travel
+// with it so the job is authorized and audited as the original request.
+type bulkDeactivateJob struct {
+	dtos.BulkDeactivateRequest
+	Principal *models.Principal `json:"principal,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+func (s *teamService) bulkDeactivateJob(ctx context.Context, payload []byte, progress JobProgress) (any, error) {
+	var job bulkDeactivateJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, err
+	}
+	if job.Principal != nil {
+		ctx = WithPrincipal(ctx, *job.Principal)
+	}
+	ctx = WithRequestID(ctx, job.RequestID)
+	return s.runBulkDeactivate(ctx, job.BulkDeactivateRequest, progress)
+}
+
+func (s *teamService) runBulkDeactivate(
+	ctx context.Context,
+	req dtos.BulkDeactivateRequest,
+	progress JobProgress) (*dtos.BulkDeactivateResponse, error) {
+	run := s.txManager.WithinTx
+	if req.DryRun {
+		run = s.txManager.DryRun
+	}
+
+	var resp *dtos.BulkDeactivateResponse
+	err := run(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = s.bulkDeactivate(ctx, req, progress)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, derr.New(derr.CodeConflict, "reviewers were modified concurrently, retry the request")
 		}
 		return nil, err
 	}
+	if !req.DryRun {
+		return resp, nil
+	}
+	resp.DryRun = true
+	resp.OperationID = 0
+	return resp, nil
+}
 
-	deactivatedIDs, err := s.userRepo.BulkDeactivate(ctx, team.ID, req.UserIDs)
+func (s *teamService) bulkDeactivate(
+	ctx context.Context,
+	req dtos.BulkDeactivateRequest,
+	progress JobProgress) (*dtos.BulkDeactivateResponse, error) {
+	team, err := s.teamByName(ctx, strings.TrimSpace(req.TeamName))
 	if err != nil {
 		return nil, err
 	}
+	if err := s.access.RequireTeamRole(ctx, team.ID, models.TeamRoleLead); err != nil {
+		return nil, err
+	}
+
+	flips, err := s.userRepo.BulkDeactivate(ctx, team.ID, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+	deactivatedIDs := make([]int64, 0, len(flips))
+	for _, f := range flips {
+		deactivatedIDs = append(deactivatedIDs, f.UserID)
+	}
 	if len(deactivatedIDs) == 0 {
+		progress.SetTotal(0)
 		return &dtos.BulkDeactivateResponse{
 			DeactivatedUsers: req.UserIDs,
 			ReassignedPRs:    []dtos.ReassignedPRSummary{},
+			Warnings:         []dtos.RestaffWarning{},
 		}, nil
 	}
 
-	prsWithReviewers, err := s.prRepo.GetOpenPRsWithReviewers(ctx, deactivatedIDs)
+	prsWithReviewers, err := s.lockOpenPRs(ctx, deactivatedIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -118,65 +374,713 @@ func (s *teamService) BulkDeactivate(
 		return nil, err
 	}
 
-	reassigned := s.reassignReviewers(ctx, prsWithReviewers, activeCandidates, deactivatedIDs)
+	load, err := s.reviewLoad(ctx, activeCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome, err := s.reassignReviewers(ctx, prsWithReviewers, activeCandidates, load, deactivatedIDs, s.seeds(),
+		progress)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := s.opRepo.Create(ctx, models.BulkOperation{
+		TeamID:       team.ID,
+		Users:        flips,
+		Replacements: outcome.replacements,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return &dtos.BulkDeactivateResponse{
+	resp := &dtos.BulkDeactivateResponse{
+		OperationID:      op.ID,
 		DeactivatedUsers: req.UserIDs,
-		ReassignedPRs:    reassigned,
-	}, nil
+		ReassignedPRs:    outcome.reassigned,
+		Warnings:         outcome.warnings,
+	}
+	wasActive := make(map[string]bool, len(flips))
+	for _, f := range flips {
+		wasActive[f.ExtUserID] = f.WasActive
+	}
+	err = s.audit.Record(ctx, models.AuditBulkDeactivate, models.AuditEntityTeam, team.Name,
+		map[string]any{"was_active": wasActive}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range flips {
+		if !f.WasActive {
+			continue
+		}
+		err := s.outbox.Publish(ctx, models.EventUserDeactivated, userEventKey(f.ExtUserID),
+			dtos.UserEventData{UserID: f.ExtUserID, TeamName: team.Name})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range outcome.replacements {
+		err := s.outbox.Publish(ctx, models.EventReviewerReassigned, prEventKey(r.PullRequestID), dtos.ReviewerEventData{
+			PullRequestID:      r.PullRequestID,
+			ReviewerID:         r.NewUserID,
+			ReplacedReviewerID: r.OldUserID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// UndoBulkDeactivate restores the is_active flags and original reviewers of a
+// bulk operation. PRs merged since, or whose reviewers changed since, are left
+// as they are.
+func (s *teamService) UndoBulkDeactivate(
+	ctx context.Context,
+	req dtos.UndoBulkDeactivateRequest) (*dtos.UndoBulkDeactivateResponse, error) {
+	var resp *dtos.UndoBulkDeactivateResponse
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		op, err := s.opRepo.GetByID(ctx, req.OperationID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "operation not found")
+			}
+			return err
+		}
+		if err := s.access.RequireTeamRole(ctx, op.TeamID, models.TeamRoleLead); err != nil {
+			return err
+		}
+		// Lock the affected PRs, then re-read the operation so the
+		// current-reviewer annotations cannot change under us.
+		if err := s.prRepo.LockByIDs(ctx, replacedPRIDs(op.Replacements)); err != nil {
+			return err
+		}
+		if op, err = s.opRepo.GetByID(ctx, op.ID); err != nil {
+			return err
+		}
+		if err := s.opRepo.MarkUndone(ctx, op.ID); err != nil {
+			if errors.Is(err, repositories.ErrAlreadyUndone) {
+				return derr.New(derr.CodeAlreadyUndone, "operation already undone")
+			}
+			return err
+		}
+
+		if err := s.userRepo.RestoreActive(ctx, op.Users); err != nil {
+			return err
+		}
+		restoredUsers := make([]string, 0, len(op.Users))
+		for _, u := range op.Users {
+			restoredUsers = append(restoredUsers, u.ExtUserID)
+		}
+
+		resp = &dtos.UndoBulkDeactivateResponse{
+			OperationID:   op.ID,
+			RestoredUsers: restoredUsers,
+			RestoredPRs:   []dtos.ReassignedPRSummary{},
+			SkippedPRs:    []dtos.SkippedPR{},
+		}
+
+		for _, group := range groupByPR(op.Replacements) {
+			if reason := undoBlocker(group); reason != "" {
+				resp.SkippedPRs = append(resp.SkippedPRs, dtos.SkippedPR{
+					PullRequestID: group[0].PullRequestID,
+					Reason:        reason,
+				})
+				continue
+			}
+
+			restored := make(map[string]string, len(group))
+			for _, rp := range group {
+				if err := s.prRepo.ReplaceReviewer(ctx, rp.PRID, rp.NewReviewerID, rp.OldReviewerID, rp.Slot); err != nil {
+					return err
+				}
+				restored[rp.NewUserID] = rp.OldUserID
+			}
+			resp.RestoredPRs = append(resp.RestoredPRs, dtos.ReassignedPRSummary{
+				PullRequestID: group[0].PullRequestID,
+				Replacements:  restored,
+			})
+		}
+
+		team, err := s.repo.GetByID(ctx, op.TeamID)
+		if err != nil {
+			return err
+		}
+		err = s.audit.Record(ctx, models.AuditBulkDeactivateUndo, models.AuditEntityTeam, team.Name,
+			map[string]int64{"operation_id": op.ID}, resp)
+		if err != nil {
+			return err
+		}
+		return s.emitUndo(ctx, op, team.Name, resp)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, derr.New(derr.CodeConflict, "reviewers were modified concurrently, retry the request")
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// emitUndo announces the users the undo made active again and every reviewer
+// put back in place of their replacement.
+func (s *teamService) emitUndo(
+	ctx context.Context,
+	op models.BulkOperation,
+	teamName string,
+	resp *dtos.UndoBulkDeactivateResponse) error {
+	for _, u := range op.Users {
+		if !u.WasActive {
+			continue
+		}
+		err := s.outbox.Publish(ctx, models.EventUserActivated, userEventKey(u.ExtUserID),
+			dtos.UserEventData{UserID: u.ExtUserID, TeamName: teamName})
+		if err != nil {
+			return err
+		}
+	}
+	for _, pr := range resp.RestoredPRs {
+		for _, replacement := range slices.Sorted(maps.Keys(pr.Replacements)) {
+			restored := pr.Replacements[replacement]
+			err := s.outbox.Publish(ctx, models.EventReviewerReassigned, prEventKey(pr.PullRequestID),
+				dtos.ReviewerEventData{
+					PullRequestID:      pr.PullRequestID,
+					ReviewerID:         restored,
+					ReplacedReviewerID: replacement,
+				})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func replacedPRIDs(replacements []models.BulkReplacement) []int64 {
+	ids := make([]int64, 0, len(replacements))
+	for _, rp := range replacements {
+		ids = append(ids, rp.PRID)
+	}
+	return ids
+}
+
+// lockOpenPRs locks the open PRs reviewed by any of reviewerIDs and returns
+// them with their reviewers as seen under the lock.
+func (s *teamService) lockOpenPRs(ctx context.Context, reviewerIDs []int64) ([]dtos.PRWithReviewers, error) {
+	prs, err := s.prRepo.GetOpenPRsWithReviewers(ctx, reviewerIDs)
+	if err != nil || len(prs) == 0 {
+		return prs, err
+	}
+	ids := make([]int64, 0, len(prs))
+	for _, prwr := range prs {
+		ids = append(ids, prwr.PR.ID)
+	}
+	if err := s.prRepo.LockByIDs(ctx, ids); err != nil {
+		return nil, err
+	}
+	return s.prRepo.GetOpenPRsWithReviewers(ctx, reviewerIDs)
+}
+
+func groupByPR(replacements []models.BulkReplacement) [][]models.BulkReplacement {
+	var groups [][]models.BulkReplacement
+	idx := make(map[int64]int)
+	for _, rp := range replacements {
+		i, ok := idx[rp.PRID]
+		if !ok {
+			i = len(groups)
+			idx[rp.PRID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], rp)
+	}
+	return groups
+}
+
+// undoBlocker tells why the replacements of one PR cannot be reverted, or
+// returns an empty string when they can.
+func undoBlocker(group []models.BulkReplacement) string {
+	for _, rp := range group {
+		if rp.PRStatus == models.PRMerged {
+			return "MERGED"
+		}
+	}
+	for _, rp := range group {
+		if rp.CurrentReviewerID == nil || *rp.CurrentReviewerID != rp.NewReviewerID || rp.OldReviewerOnPR {
+			return "CHANGED"
+		}
+	}
+	return ""
+}
+
+// errRestaffFailed rolls back the savepoint of a PR whose reviewers could not
+// all be replaced.
+var errRestaffFailed = errors.New("restaff failed")
+
+type restaffOutcome struct {
+	reassigned   []dtos.ReassignedPRSummary
+	warnings     []dtos.RestaffWarning
+	replacements []models.BulkReplacement
 }
 
 func (s *teamService) reassignReviewers(
 	ctx context.Context,
 	prsWithReviewers []dtos.PRWithReviewers,
 	activeCandidates []models.User,
+	load map[int64]int,
 	deactivatedInternalIDs []int64,
-) []dtos.ReassignedPRSummary {
-	if len(activeCandidates) == 0 || len(deactivatedInternalIDs) == 0 {
-		return []dtos.ReassignedPRSummary{}
+	seed int64,
+	progress JobProgress,
+) (restaffOutcome, error) {
+	outcome := restaffOutcome{
+		reassigned: make([]dtos.ReassignedPRSummary, 0),
+		warnings:   make([]dtos.RestaffWarning, 0),
 	}
+	if len(deactivatedInternalIDs) == 0 {
+		return outcome, nil
+	}
+	progress.SetTotal(len(prsWithReviewers))
 
 	deactivatedSet := make(map[int64]struct{}, len(deactivatedInternalIDs))
 	for _, id := range deactivatedInternalIDs {
 		deactivatedSet[id] = struct{}{}
 	}
 
-	reassigned := make([]dtos.ReassignedPRSummary, 0)
-	candidateIdx := 0
+	// Candidates come ordered by id, so the shuffle only depends on the seed.
+	// Every PR then draws its own seed, which lets a single PR be replayed.
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(activeCandidates), func(i, j int) {
+		activeCandidates[i], activeCandidates[j] = activeCandidates[j], activeCandidates[i]
+	})
+	planner := &bulkPlanner{
+		candidates:     activeCandidates,
+		deactivated:    deactivatedSet,
+		groupMembers:   make(map[int64][]models.User),
+		load:           load,
+		maxOpenReviews: s.maxOpenReviews,
+	}
 
 	for _, prwr := range prsWithReviewers {
+		prSeed := rng.Int63()
+		cursor := planner.cursor
+		prLoad := maps.Clone(load)
+		groups := maps.Clone(planner.groupMembers)
+		done := len(outcome.replacements)
 		replacements := make(map[string]string)
+		var failures []string
 
-		for _, reviewer := range prwr.Reviewers {
-			if _, needReplace := deactivatedSet[reviewer.ID]; !needReplace {
-				continue
+		// Each PR runs in its own savepoint: a failed statement aborts only
+		// this PR, and the rest of the job carries on.
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			prRng := rand.New(rand.NewSource(prSeed))
+			var explanations []models.AssignmentExplanation
+			for _, reviewer := range prwr.Reviewers {
+				if _, needReplace := deactivatedSet[reviewer.ID]; !needReplace {
+					continue
+				}
+				pick, slot, found, err := s.replaceDeactivated(ctx, planner, load, prwr, reviewer, replacements, prRng)
+				if err != nil {
+					log.Printf("bulk reassignment of %s, reviewer %s: %v", prwr.PR.PullRequestID, reviewer.UserID, err)
+					failures = append(failures, reviewer.UserID+": reviewer could not be replaced")
+					return errRestaffFailed
+				}
+				if !found {
+					continue
+				}
+				newReviewer := pick.user
+				replacements[reviewer.UserID] = newReviewer.UserID
+				load[newReviewer.ID]++
+				outcome.replacements = append(outcome.replacements, models.BulkReplacement{
+					PRID:          prwr.PR.ID,
+					Slot:          slot,
+					OldReviewerID: reviewer.ID,
+					NewReviewerID: newReviewer.ID,
+				})
+				explanations = append(explanations,
+					explainPick(slot, pick.reviewerPick, planner.exclusion(prwr, reviewer)))
 			}
-			if len(activeCandidates) == 0 {
-				break
+			if len(replacements) == 0 {
+				return nil
 			}
+			return s.recordBulkReassignment(ctx, prwr, prSeed, bulkSnapshot{
+				Deactivated:    deactivatedInternalIDs,
+				Candidates:     activeCandidates,
+				Cursor:         cursor,
+				Load:           prLoad,
+				MaxOpenReviews: s.maxOpenReviews,
+				AuthorID:       prwr.PR.AuthorUserID,
+				Reviewers:      prwr.Reviewers,
+				RequiredGroups: prwr.RequiredGroups,
+				GroupMembers:   planner.groupMembers,
+				Replacements:   replacements,
+			}, explanations)
+		})
+		switch {
+		case errors.Is(err, errRestaffFailed):
+			// The savepoint is gone, so is every change made for this PR.
+			planner.cursor, planner.groupMembers = cursor, groups
+			clear(load)
+			maps.Copy(load, prLoad)
+			outcome.replacements = outcome.replacements[:done]
+			clear(replacements)
+		case err != nil:
+			return restaffOutcome{}, err
+		case len(replacements) > 0:
+			outcome.reassigned = append(outcome.reassigned, dtos.ReassignedPRSummary{
+				PullRequestID: prwr.PR.PullRequestID,
+				Replacements:  replacements,
+			})
+		}
+
+		if w, understaffed := restaffWarning(prwr, deactivatedSet, replacements); understaffed {
+			outcome.warnings = append(outcome.warnings, w)
+		}
+
+		result := dtos.BulkPRResult{
+			PullRequestID: prwr.PR.PullRequestID,
+			Status:        "UNCHANGED",
+			Replacements:  replacements,
+			Errors:        failures,
+		}
+		switch {
+		case len(failures) > 0:
+			result.Status = "FAILED"
+		case len(replacements) > 0:
+			result.Status = "REASSIGNED"
+		}
+		progress.Record(result)
+	}
+
+	return outcome, nil
+}
+
+// replaceDeactivated picks the successor of reviewer on prwr and writes it to
+// the PR. found is false when nobody can take the slot.
+func (s *teamService) replaceDeactivated(
+	ctx context.Context,
+	planner *bulkPlanner,
+	load map[int64]int,
+	prwr dtos.PRWithReviewers,
+	reviewer models.User,
+	replacements map[string]string,
+	rng *rand.Rand,
+) (pick bulkPick, slot int, found bool, err error) {
+	slot, err = s.prRepo.GetReviewerSlot(ctx, prwr.PR.ID, reviewer.ID)
+	if err != nil {
+		return bulkPick{}, 0, false, err
+	}
 
-			slot, err := s.prRepo.GetReviewerSlot(ctx, prwr.PR.ID, reviewer.ID)
+	if groupID, reserved := prwr.RequiredGroups[reviewer.ID]; reserved {
+		if _, ok := planner.groupMembers[groupID]; !ok {
+			members, err := s.groupRepo.GetMembers(ctx, groupID)
 			if err != nil {
-				continue
+				return bulkPick{}, 0, false, err
 			}
+			if err := s.addReviewLoad(ctx, load, members); err != nil {
+				return bulkPick{}, 0, false, err
+			}
+			planner.groupMembers[groupID] = members
+		}
+	}
 
-			newReviewer := activeCandidates[candidateIdx]
-			candidateIdx = (candidateIdx + 1) % len(activeCandidates)
+	pick, found = planner.pick(prwr, reviewer, replacements, rng)
+	if !found {
+		return bulkPick{}, slot, false, nil
+	}
+	if err := s.prRepo.ReplaceReviewer(ctx, prwr.PR.ID, reviewer.ID, pick.user.ID, slot); err != nil {
+		return bulkPick{}, 0, false, err
+	}
+	return pick, slot, true, nil
+}
 
-			if err := s.prRepo.ReplaceReviewer(ctx, prwr.PR.ID, reviewer.ID, newReviewer.ID, slot); err != nil {
-				continue
+// reviewLoad returns the open review load of users. It stays empty while
+// open reviews are not capped.
+func (s *teamService) reviewLoad(ctx context.Context, users []models.User) (map[int64]int, error) {
+	load := make(map[int64]int)
+	return load, s.addReviewLoad(ctx, load, users)
+}
+
+// addReviewLoad adds the load of the users not in load yet.
+func (s *teamService) addReviewLoad(ctx context.Context, load map[int64]int, users []models.User) error {
+	if s.maxOpenReviews == 0 {
+		return nil
+	}
+	var ids []int64
+	for _, u := range users {
+		if _, ok := load[u.ID]; !ok {
+			ids = append(ids, u.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	found, err := s.userRepo.GetOpenReviewLoad(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		load[id] = found[id]
+	}
+	return nil
+}
+
+// bulkPlanner decides the successors of deactivated reviewers. It does not
+// touch the database, so a bulk reassignment event is replayed by running it
+// over the stored snapshot.
+type bulkPlanner struct {
+	candidates     []models.User
+	cursor         int
+	deactivated    map[int64]struct{}
+	groupMembers   map[int64][]models.User
+	load           map[int64]int
+	maxOpenReviews int
+}
+
+type bulkPick struct {
+	reviewerPick
+	user models.User
+}
+
+func (p *bulkPlanner) pick(
+	prwr dtos.PRWithReviewers,
+	reviewer models.User,
+	replacements map[string]string,
+	rng *rand.Rand) (bulkPick, bool) {
+	if groupID, reserved := prwr.RequiredGroups[reviewer.ID]; reserved {
+		members := p.groupMembers[groupID]
+		u, found := pickGroupReplacement(filterUsers(members, p.hasCapacity), prwr, p.deactivated, rng)
+		return bulkPick{
+			reviewerPick: reviewerPick{
+				userID: u.UserID,
+				rule:   models.RuleRequiredGroup,
+				detail: "slot is reserved for a reviewer group; random among eligible members",
+				pool:   members,
+			},
+			user: u,
+		}, found
+	}
+	u, found := p.nextRoundRobin(prwr, replacements)
+	return bulkPick{
+		reviewerPick: reviewerPick{
+			userID: u.UserID,
+			rule:   models.RuleRoundRobin,
+			detail: "next active team member in round-robin order",
+			pool:   p.candidates,
+		},
+		user: u,
+	}, found
+}
+
+func (p *bulkPlanner) hasCapacity(u models.User) bool {
+	return !overCapacity(p.load, p.maxOpenReviews, u)
+}
+
+// exclusion tells why a candidate could not replace the reviewer.
+func (p *bulkPlanner) exclusion(prwr dtos.PRWithReviewers, replaced models.User) func(models.User) string {
+	excluded := bulkExclusion(prwr, replaced, p.deactivated)
+	return func(u models.User) string {
+		if reason := excluded(u); reason != "" {
+			return reason
+		}
+		if !p.hasCapacity(u) {
+			return models.ExcludedOverCapacity
+		}
+		return ""
+	}
+}
+
+// replayBulkReassignment runs the decisions of one PR of a bulk reassignment
+// again and returns the new reviewers in the order they were recorded.
+func replayBulkReassignment(raw []byte, seed int64) ([]string, error) {
+	var snap bulkSnapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+	planner := &bulkPlanner{
+		candidates:     snap.Candidates,
+		cursor:         snap.Cursor,
+		deactivated:    make(map[int64]struct{}, len(snap.Deactivated)),
+		groupMembers:   snap.GroupMembers,
+		load:           snap.Load,
+		maxOpenReviews: snap.MaxOpenReviews,
+	}
+	if planner.load == nil {
+		planner.load = make(map[int64]int)
+	}
+	for _, id := range snap.Deactivated {
+		planner.deactivated[id] = struct{}{}
+	}
+	prwr := dtos.PRWithReviewers{
+		PR:             models.PullRequest{AuthorUserID: snap.AuthorID},
+		Reviewers:      snap.Reviewers,
+		RequiredGroups: snap.RequiredGroups,
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	replacements := make(map[string]string)
+	replayed := []string{}
+	for _, r := range prwr.Reviewers {
+		if _, ok := planner.deactivated[r.ID]; !ok {
+			continue
+		}
+		if pick, found := planner.pick(prwr, r, replacements, rng); found {
+			replacements[r.UserID] = pick.userID
+			planner.load[pick.user.ID]++
+			replayed = append(replayed, pick.userID)
+		}
+	}
+	return replayed, nil
+}
+
+// nextRoundRobin returns the next candidate in round-robin order that is not
+// the author, is not already reviewing the PR and has room for another
+// review, advancing the cursor past it.
+func (p *bulkPlanner) nextRoundRobin(
+	prwr dtos.PRWithReviewers,
+	replacements map[string]string,
+) (models.User, bool) {
+	onPR := make(map[string]struct{}, len(prwr.Reviewers)+len(replacements))
+	for _, r := range prwr.Reviewers {
+		onPR[r.UserID] = struct{}{}
+	}
+	for _, userID := range replacements {
+		onPR[userID] = struct{}{}
+	}
+	for range p.candidates {
+		c := p.candidates[p.cursor]
+		p.cursor = (p.cursor + 1) % len(p.candidates)
+		if _, taken := onPR[c.UserID]; !taken && c.ID != prwr.PR.AuthorUserID && p.hasCapacity(c) {
+			return c, true
+		}
+	}
+	return models.User{}, false
+}
+
+func restaffWarning(
+	prwr dtos.PRWithReviewers,
+	deactivatedSet map[int64]struct{},
+	replacements map[string]string) (dtos.RestaffWarning, bool) {
+	var unreplaced []string
+	for _, r := range prwr.Reviewers {
+		if _, deactivated := deactivatedSet[r.ID]; deactivated {
+			if _, replaced := replacements[r.UserID]; !replaced {
+				unreplaced = append(unreplaced, r.UserID)
 			}
+		}
+	}
+	if len(unreplaced) == 0 {
+		return dtos.RestaffWarning{}, false
+	}
+
+	remaining := len(prwr.Reviewers) - len(unreplaced)
+	code := "UNDERSTAFFED"
+	if remaining == 0 {
+		code = "NO_REVIEWERS"
+	}
+	return dtos.RestaffWarning{
+		PullRequestID:      prwr.PR.PullRequestID,
+		Code:               code,
+		UnreplacedUsers:    unreplaced,
+		RemainingReviewers: remaining,
+	}, true
+}
 
-			replacements[reviewer.UserID] = newReviewer.UserID
+func pickGroupReplacement(
+	members []models.User,
+	prwr dtos.PRWithReviewers,
+	deactivatedSet map[int64]struct{},
+	rng *rand.Rand) (models.User, bool) {
+	var candidates []models.User
+	for _, m := range members {
+		if _, deactivated := deactivatedSet[m.ID]; deactivated || !m.IsActive || m.ID == prwr.PR.AuthorUserID {
+			continue
 		}
+		assigned := false
+		for _, r := range prwr.Reviewers {
+			if r.ID == m.ID {
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return models.User{}, false
+	}
+	return candidates[rng.Intn(len(candidates))], true
+}
 
-		if len(replacements) > 0 {
-			reassigned = append(reassigned, dtos.ReassignedPRSummary{
-				PullRequestID: prwr.PR.PullRequestID,
-				Replacements:  replacements,
-			})
+func bulkExclusion(
+	prwr dtos.PRWithReviewers,
+	replaced models.User,
+	deactivatedSet map[int64]struct{}) func(models.User) string {
+	return func(u models.User) string {
+		if _, ok := deactivatedSet[u.ID]; ok {
+			if u.ID == replaced.ID {
+				return models.ExcludedReplaced
+			}
+			return models.ExcludedDeactivated
+		}
+		if u.ID == prwr.PR.AuthorUserID {
+			return models.ExcludedAuthor
 		}
+		for _, r := range prwr.Reviewers {
+			if r.ID == u.ID {
+				return models.ExcludedAlreadyAssigned
+			}
+		}
+		if !u.IsActive {
+			return models.ExcludedInactive
+		}
+		return ""
+	}
+}
+
+// bulkSnapshot is what one PR of a bulk reassignment was decided from. The
+// candidates are stored in round-robin order and Cursor is where the PR
+// started in it.
+type bulkSnapshot struct {
+	Deactivated    []int64                 `json:"deactivated"`
+	Candidates     []models.User           `json:"candidates"`
+	Cursor         int                     `json:"cursor"`
+	Load           map[int64]int           `json:"load,omitempty"`
+	MaxOpenReviews int                     `json:"max_open_reviews,omitempty"`
+	AuthorID       int64                   `json:"author_id"`
+	Reviewers      []models.User           `json:"reviewers"`
+	RequiredGroups map[int64]int64         `json:"required_groups,omitempty"`
+	GroupMembers   map[int64][]models.User `json:"group_members,omitempty"`
+	Replacements   map[string]string       `json:"replacements"`
+}
+
+// recordBulkReassignment stores the seed the PR was decided with. It runs in
+// the transaction of the bulk operation, so a failure rolls the whole
+// operation back.
+func (s *teamService) recordBulkReassignment(
+	ctx context.Context,
+	prwr dtos.PRWithReviewers,
+	seed int64,
+	snap bulkSnapshot,
+	explanations []models.AssignmentExplanation) error {
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		return err
 	}
 
-	return reassigned
+	newReviewers := make([]string, 0, len(snap.Replacements))
+	for _, r := range prwr.Reviewers {
+		if id, ok := snap.Replacements[r.UserID]; ok {
+			newReviewers = append(newReviewers, id)
+		}
+	}
+	_, err = s.eventRepo.Create(ctx, models.AssignmentEvent{
+		PRID:         prwr.PR.ID,
+		Kind:         models.AssignmentBulkReassign,
+		Seed:         seed,
+		Snapshot:     raw,
+		Reviewers:    newReviewers,
+		Explanations: explanations,
+	})
+	return err
 }
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"encoding/json"
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+func bulkUsers(ids ...int64) []models.User {
+	users := make([]models.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, models.User{ID: id, UserID: "u" + strconv.FormatInt(id, 10), IsActive: true})
+	}
+	return users
+}
+
+func TestNextRoundRobinSkipsAuthor(t *testing.T) {
+	users := bulkUsers(1, 2, 3, 4)
+	prwr := dtos.PRWithReviewers{
+		PR:        models.PullRequest{ID: 10, AuthorUserID: 1},
+		Reviewers: []models.User{users[1]},
+	}
+	p := &bulkPlanner{candidates: users, load: map[int64]int{}}
+
+	got, ok := p.nextRoundRobin(prwr, map[string]string{})
+	if !ok || got.ID != 3 {
+		t.Fatalf("nextRoundRobin = %v, %v; want user 3", got.ID, ok)
+	}
+	if p.cursor != 3 {
+		t.Fatalf("cursor = %d, want 3", p.cursor)
+	}
+}
+
+func TestNextRoundRobinNoCandidate(t *testing.T) {
+	users := bulkUsers(1, 2)
+	prwr := dtos.PRWithReviewers{
+		PR:        models.PullRequest{ID: 10, AuthorUserID: 1},
+		Reviewers: []models.User{users[1]},
+	}
+	p := &bulkPlanner{candidates: users, load: map[int64]int{}}
+
+	if got, ok := p.nextRoundRobin(prwr, map[string]string{}); ok {
+		t.Fatalf("nextRoundRobin = %v, want no candidate", got.ID)
+	}
+}
+
+func TestNextRoundRobinSkipsFullReviewers(t *testing.T) {
+	users := bulkUsers(1, 2, 3)
+	prwr := dtos.PRWithReviewers{PR: models.PullRequest{ID: 10, AuthorUserID: 9}}
+	p := &bulkPlanner{candidates: users, load: map[int64]int{1: 2, 2: 1}, maxOpenReviews: 2}
+
+	got, ok := p.nextRoundRobin(prwr, map[string]string{})
+	if !ok || got.ID != 2 {
+		t.Fatalf("nextRoundRobin = %v, %v; want user 2", got.ID, ok)
+	}
+	if reason := p.exclusion(prwr, models.User{ID: 5})(users[0]); reason != models.ExcludedOverCapacity {
+		t.Fatalf("exclusion = %q, want %q", reason, models.ExcludedOverCapacity)
+	}
+}
+
+func TestReplayBulkReassignment(t *testing.T) {
+	users := bulkUsers(1, 2, 3, 4, 5, 6)
+	deactivated := users[1]
+	groupID := int64(7)
+	prwr := dtos.PRWithReviewers{
+		PR:             models.PullRequest{ID: 10, AuthorUserID: 1},
+		Reviewers:      []models.User{deactivated, users[2]},
+		RequiredGroups: map[int64]int64{deactivated.ID: groupID},
+	}
+	p := &bulkPlanner{
+		candidates:   users[2:],
+		cursor:       1,
+		deactivated:  map[int64]struct{}{deactivated.ID: {}},
+		groupMembers: map[int64][]models.User{groupID: users},
+		load:         map[int64]int{},
+	}
+	snap := bulkSnapshot{
+		Deactivated:    []int64{deactivated.ID},
+		Candidates:     p.candidates,
+		Cursor:         p.cursor,
+		AuthorID:       prwr.PR.AuthorUserID,
+		Reviewers:      prwr.Reviewers,
+		RequiredGroups: prwr.RequiredGroups,
+		GroupMembers:   p.groupMembers,
+	}
+
+	const seed = 42
+	pick, ok := p.pick(prwr, deactivated, map[string]string{}, rand.New(rand.NewSource(seed)))
+	if !ok {
+		t.Fatal("no replacement picked")
+	}
+	if pick.user.ID == prwr.PR.AuthorUserID || pick.user.ID == deactivated.ID || pick.user.ID == users[2].ID {
+		t.Fatalf("picked ineligible user %d", pick.user.ID)
+	}
+
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := replayBulkReassignment(raw, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{pick.userID}; !slices.Equal(replayed, want) {
+		t.Fatalf("replayed %v, want %v", replayed, want)
+	}
+}
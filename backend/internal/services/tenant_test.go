@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/testdb"
+)
+
+// TestTenantIsolation fills two organizations with teams, users, repositories
+// and pull requests that share every external ID, and checks that neither can
+// read or change the other's rows.
+func TestTenantIsolation(t *testing.T) {
+	pool := testdb.New(t)
+	svc := newTestServices(pool)
+	acme, err := repositories.NewOrganizationRepository(pool).Create(context.Background(),
+		models.Organization{Slug: "acme", Name: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgs := map[string]context.Context{
+		"default": repositories.WithOrganization(context.Background(), models.DefaultOrganizationID),
+		"acme":    repositories.WithOrganization(context.Background(), acme.ID),
+	}
+
+	for name, ctx := range orgs {
+		team := dtos.AddTeamRequest{TeamName: "backend"}
+		for i := 1; i <= 3; i++ {
+			id := fmt.Sprintf("u%d", i)
+			team.Members = append(team.Members,
+				dtos.TeamMemberDTO{UserID: id, Username: name + "-" + id, IsActive: true})
+		}
+		if _, err := svc.teams.AddTeam(ctx, team); err != nil {
+			t.Fatalf("%s: add team: %v", name, err)
+		}
+	}
+
+	ctx, other := orgs["default"], orgs["acme"]
+	if _, err := svc.repos.Add(ctx, dtos.AddRepositoryRequest{
+		Name: "core", OwnerTeams: []string{"backend"}, ReviewerPool: []string{"u2"},
+	}); err != nil {
+		t.Fatalf("add repository: %v", err)
+	}
+	pr, err := svc.prs.Create(ctx, dtos.CreatePRRequest{PullRequestID: "pr-1", Title: "change", Author: "u1"})
+	if err != nil {
+		t.Fatalf("create pr: %v", err)
+	}
+	if len(pr.PR.AssignedReviewers) == 0 {
+		t.Fatal("no reviewers assigned")
+	}
+
+	team, err := svc.teams.GetTeam(other, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range team.Members {
+		if m.Username != "acme-"+m.UserID {
+			t.Errorf("acme team lists member %s (%s)", m.UserID, m.Username)
+		}
+	}
+
+	wantNotFound := func(what string, err error) {
+		t.Helper()
+		if de, ok := derr.IsDomain(err); !ok || de.Code != derr.CodeNotFound {
+			t.Errorf("%s from another organization: error = %v, want NOT_FOUND", what, err)
+		}
+	}
+	_, err = svc.repos.Get(other, "core")
+	wantNotFound("get repository", err)
+	_, err = svc.prs.Explain(other, "pr-1", "")
+	wantNotFound("explain pr", err)
+	_, err = svc.prs.Merge(other, dtos.MergePRRequest{PullRequestID: "pr-1"})
+	wantNotFound("merge pr", err)
+	_, err = svc.prs.Reassign(other, dtos.ReassignRequest{
+		PullRequestID: "pr-1",
+		OldUserID:     pr.PR.AssignedReviewers[0],
+	})
+	wantNotFound("reassign pr", err)
+
+	repos, err := svc.repos.List(other)
+	if err != nil || len(repos.Repositories) != 0 {
+		t.Errorf("acme repositories = %v, %v; want none", repos.Repositories, err)
+	}
+	for _, reviewer := range pr.PR.AssignedReviewers {
+		reviews, err := svc.users.GetReview(other, reviewer)
+		if err != nil || len(reviews.PullRequests) != 0 {
+			t.Errorf("acme %s reviews = %v, %v; want none", reviewer, reviews.PullRequests, err)
+		}
+	}
+	entries, err := svc.audit.List(other, dtos.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries.Entries {
+		if e.EntityType != models.AuditEntityTeam {
+			t.Errorf("acme audit log has %s %s", e.Action, e.EntityID)
+		}
+	}
+
+	// The same external IDs stay free in the other organization.
+	_, err = svc.prs.Create(other, dtos.CreatePRRequest{PullRequestID: "pr-1", Title: "change", Author: "u1"})
+	if err != nil {
+		t.Fatalf("create pr-1 in acme: %v", err)
+	}
+	if _, err := svc.prs.Merge(other, dtos.MergePRRequest{PullRequestID: "pr-1"}); err != nil {
+		t.Fatalf("merge acme pr-1: %v", err)
+	}
+	var status string
+	err = pool.QueryRow(ctx, `SELECT status FROM pull_requests WHERE organization_id = $1 AND pr_id = 'pr-1'`,
+		models.DefaultOrganizationID).Scan(&status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "OPEN" {
+		t.Errorf("default pr-1 status = %s after merging acme pr-1, want OPEN", status)
+	}
+}
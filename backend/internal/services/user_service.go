@@ -6,6 +6,7 @@ import (
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
 )
@@ -13,16 +14,34 @@ import (
 type UserService interface {
 	SetIsActive(ctx context.Context, in dtos.SetIsActiveRequest) (dtos.SetIsActiveResponse, error)
 	GetReview(ctx context.Context, userID string) (dtos.GetReviewResponse, error)
+	SetSeniority(ctx context.Context, in dtos.SetSeniorityRequest) (dtos.SetSeniorityResponse, error)
+	SetTags(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error)
+	AddTags(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error)
+	RemoveTags(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error)
 }
 
 type userService struct {
 	users     repositories.UserRepository
+	txManager *repositories.TxManager
+	access    *AccessControl
+	audit     *AuditService
+	outbox    *Outbox
 	validator validators.UserValidator
 }
 
-func NewUserService(users repositories.UserRepository, validator validators.UserValidator) UserService {
+func NewUserService(
+	users repositories.UserRepository,
+	txManager *repositories.TxManager,
+	access *AccessControl,
+	audit *AuditService,
+	outbox *Outbox,
+	validator validators.UserValidator) UserService {
 	return &userService{
 		users:     users,
+		txManager: txManager,
+		access:    access,
+		audit:     audit,
+		outbox:    outbox,
 		validator: validator,
 	}
 }
@@ -31,21 +50,90 @@ func (s *userService) SetIsActive(ctx context.Context, in dtos.SetIsActiveReques
 	if err := s.validator.ValidateSetIsActive(ctx, in); err != nil {
 		return dtos.SetIsActiveResponse{}, err
 	}
+	user, err := s.authorize(ctx, in.UserID, false)
+	if err != nil {
+		return dtos.SetIsActiveResponse{}, err
+	}
 
-	updated, teamName, err := s.users.SetIsActive(ctx, in.UserID, in.IsActive)
+	var updated models.User
+	var teamName string
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		updated, teamName, err = s.users.SetIsActive(ctx, in.UserID, in.IsActive)
+		if err != nil {
+			if stderrs.Is(err, repositories.ErrNotFound) {
+				return errors.New(errors.CodeNotFound, "resource not found")
+			}
+			return errors.New(errors.CodeValidation, "invalid request")
+		}
+		err = s.audit.Record(ctx, models.AuditUserSetActive, models.AuditEntityUser, updated.UserID,
+			map[string]bool{"is_active": user.IsActive}, map[string]bool{"is_active": updated.IsActive})
+		if err != nil || user.IsActive == updated.IsActive {
+			return err
+		}
+		event := models.EventUserDeactivated
+		if updated.IsActive {
+			event = models.EventUserActivated
+		}
+		return s.outbox.Publish(ctx, event, userEventKey(updated.UserID),
+			dtos.UserEventData{UserID: updated.UserID, TeamName: teamName})
+	})
 	if err != nil {
-		if stderrs.Is(err, repositories.ErrNotFound) {
-			return dtos.SetIsActiveResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+		if _, ok := errors.IsDomain(err); ok {
+			return dtos.SetIsActiveResponse{}, err
 		}
-		return dtos.SetIsActiveResponse{}, errors.New(errors.CodeValidation, "invalid request")
+		return dtos.SetIsActiveResponse{}, errors.New(errors.CodeInternal, "internal error")
 	}
 
 	return dtos.SetIsActiveResponse{
 		User: dtos.User{
-			UserID:   updated.UserID,
-			Username: updated.Name,
-			TeamName: teamName,
-			IsActive: updated.IsActive,
+			UserID:    updated.UserID,
+			Username:  updated.Name,
+			TeamName:  teamName,
+			IsActive:  updated.IsActive,
+			Tags:      updated.Tags,
+			Seniority: updated.Seniority,
+		},
+	}, nil
+}
+
+func (s *userService) SetSeniority(
+	ctx context.Context,
+	in dtos.SetSeniorityRequest) (dtos.SetSeniorityResponse, error) {
+	if err := s.validator.ValidateSetSeniority(ctx, in); err != nil {
+		return dtos.SetSeniorityResponse{}, err
+	}
+	user, err := s.authorize(ctx, in.UserID, false)
+	if err != nil {
+		return dtos.SetSeniorityResponse{}, err
+	}
+
+	var updated models.User
+	var teamName string
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		updated, teamName, err = s.users.SetSeniority(ctx, in.UserID, in.Seniority)
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditUserSetSeniority, models.AuditEntityUser, updated.UserID,
+			map[string]string{"seniority": user.Seniority}, map[string]string{"seniority": updated.Seniority})
+	})
+	if err != nil {
+		if stderrs.Is(err, repositories.ErrNotFound) {
+			return dtos.SetSeniorityResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+		}
+		return dtos.SetSeniorityResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	return dtos.SetSeniorityResponse{
+		User: dtos.User{
+			UserID:    updated.UserID,
+			Username:  updated.Name,
+			TeamName:  teamName,
+			IsActive:  updated.IsActive,
+			Tags:      updated.Tags,
+			Seniority: updated.Seniority,
 		},
 	}, nil
 }
@@ -65,3 +153,77 @@ func (s *userService) GetReview(ctx context.Context, userID string) (dtos.GetRev
 		PullRequests: prs,
 	}, nil
 }
+
+func (s *userService) SetTags(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error) {
+	return s.updateTags(ctx, in, models.AuditUserSetTags, s.users.SetTags)
+}
+
+func (s *userService) AddTags(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error) {
+	return s.updateTags(ctx, in, models.AuditUserAddTags, s.users.AddTags)
+}
+
+func (s *userService) RemoveTags(ctx context.Context, in dtos.UserTagsRequest) (dtos.UserTagsResponse, error) {
+	return s.updateTags(ctx, in, models.AuditUserRemoveTags, s.users.RemoveTags)
+}
+
+func (s *userService) updateTags(
+	ctx context.Context,
+	in dtos.UserTagsRequest,
+	action string,
+	update func(ctx context.Context, userID string, tags []string) (models.User, string, error),
+) (dtos.UserTagsResponse, error) {
+	if err := s.validator.ValidateTags(ctx, in); err != nil {
+		return dtos.UserTagsResponse{}, err
+	}
+	user, err := s.authorize(ctx, in.UserID, true)
+	if err != nil {
+		return dtos.UserTagsResponse{}, err
+	}
+
+	var updated models.User
+	var teamName string
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		updated, teamName, err = update(ctx, in.UserID, normalizeTags(in.Tags))
+		if err != nil {
+			return err
+		}
+		return s.audit.Record(ctx, action, models.AuditEntityUser, updated.UserID,
+			map[string][]string{"tags": user.Tags}, map[string][]string{"tags": updated.Tags})
+	})
+	if err != nil {
+		if stderrs.Is(err, repositories.ErrNotFound) {
+			return dtos.UserTagsResponse{}, errors.New(errors.CodeNotFound, "resource not found")
+		}
+		return dtos.UserTagsResponse{}, errors.New(errors.CodeInternal, "internal error")
+	}
+
+	return dtos.UserTagsResponse{
+		User: dtos.User{
+			UserID:    updated.UserID,
+			Username:  updated.Name,
+			TeamName:  teamName,
+			IsActive:  updated.IsActive,
+			Tags:      updated.Tags,
+			Seniority: updated.Seniority,
+		},
+	}, nil
+}
+
+// authorize loads the user and requires a lead of their team; with allowSelf
+// the user may also change their own profile.
+func (s *userService) authorize(ctx context.Context, userID string, allowSelf bool) (models.User, error) {
+	user, err := s.users.GetByUserID(ctx, userID)
+	if err != nil {
+		if stderrs.Is(err, repositories.ErrNotFound) {
+			return models.User{}, errors.New(errors.CodeNotFound, "resource not found")
+		}
+		return models.User{}, errors.New(errors.CodeInternal, "internal error")
+	}
+	if allowSelf {
+		err = s.access.RequireSelfOrTeamRole(ctx, user.ID, user.TeamID, models.TeamRoleLead)
+	} else {
+		err = s.access.RequireTeamRole(ctx, user.TeamID, models.TeamRoleLead)
+	}
+	return user, err
+}
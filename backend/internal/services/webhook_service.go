@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	derr "github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+const (
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookTimeout     = 10 * time.Second
+	// webhookLease must outlast an attempt, or a slow receiver gets the same
+	// delivery twice.
+	webhookLease = time.Minute
+
+	defaultWebhookLimit = 100
+	maxWebhookLimit     = 1000
+)
+
+const (
+	headerWebhookEvent     = "X-Webhook-Event"
+	headerWebhookDelivery  = "X-Webhook-Delivery"
+	headerWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookService manages subscriptions and sends queued deliveries. It is
+// fed by the outbox as an EventSink.
+type WebhookService struct {
+	repo         repositories.WebhookRepository
+	txManager    *repositories.TxManager
+	audit        *AuditService
+	validator    validators.WebhookValidator
+	client       *http.Client
+	pollInterval time.Duration
+	wake         chan struct{}
+}
+
+func NewWebhookService(
+	repo repositories.WebhookRepository,
+	txManager *repositories.TxManager,
+	audit *AuditService,
+	validator validators.WebhookValidator,
+	pollInterval time.Duration) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		txManager:    txManager,
+		audit:        audit,
+		validator:    validator,
+		client:       webhookClient(validator.CheckDestination),
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// webhookClient checks every address it connects to, so a host that resolved
+// to a public address at registration cannot be pointed inside later, and
+// does not follow redirects, which would lead to a host nobody validated.
+func webhookClient(checkDestination func(netip.Addr) error) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			return checkDestination(addrPort.Addr())
+		},
+	}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			ForceAttemptHTTP2:   true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+func (s *WebhookService) Name() string { return "webhook" }
+
+// Publish queues ev for every subscriber in the event's organization.
+func (s *WebhookService) Publish(ctx context.Context, ev models.OutboxEvent) error {
+	payload, err := json.Marshal(dtos.WebhookEvent{
+		Event:      ev.Event,
+		OccurredAt: ev.CreatedAt.UTC(),
+		Data:       json.RawMessage(ev.Payload),
+	})
+	if err != nil {
+		return err
+	}
+	return s.repo.Enqueue(repositories.WithOrganization(ctx, ev.OrganizationID), ev.Event, payload)
+}
+
+func (s *WebhookService) Add(ctx context.Context, req dtos.AddWebhookRequest) (dtos.AddWebhookResponse, error) {
+	if err := s.validator.ValidateAdd(ctx, req); err != nil {
+		return dtos.AddWebhookResponse{}, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return dtos.AddWebhookResponse{}, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+	events := slices.Clone(req.Events)
+	slices.Sort(events)
+
+	var out dtos.WebhookSubscriptionDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		sub, err := s.repo.CreateSubscription(ctx, models.WebhookSubscription{
+			URL:    req.URL,
+			Secret: secret,
+			Events: slices.Compact(events),
+		})
+		if err != nil {
+			return err
+		}
+		out = mapWebhookToDTO(sub)
+		return s.audit.Record(ctx, models.AuditWebhookAdd, models.AuditEntityWebhook,
+			strconv.FormatInt(out.SubscriptionID, 10), nil, out)
+	})
+	if err != nil {
+		return dtos.AddWebhookResponse{}, err
+	}
+	return dtos.AddWebhookResponse{Subscription: out, Secret: secret}, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) (dtos.WebhookListResponse, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return dtos.WebhookListResponse{}, err
+	}
+	out := make([]dtos.WebhookSubscriptionDTO, 0, len(subs))
+	for _, sub := range subs {
+		out = append(out, mapWebhookToDTO(sub))
+	}
+	return dtos.WebhookListResponse{Subscriptions: out}, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, req dtos.DeleteWebhookRequest) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		subs, err := s.repo.ListSubscriptions(ctx)
+		if err != nil {
+			return err
+		}
+		var before any
+		if i := slices.IndexFunc(subs, func(sub models.WebhookSubscription) bool {
+			return sub.ID == req.SubscriptionID
+		}); i >= 0 {
+			before = mapWebhookToDTO(subs[i])
+		}
+		if err := s.repo.DeleteSubscription(ctx, req.SubscriptionID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "subscription not found")
+			}
+			return err
+		}
+		return s.audit.Record(ctx, models.AuditWebhookDelete, models.AuditEntityWebhook,
+			strconv.FormatInt(req.SubscriptionID, 10), before, nil)
+	})
+}
+
+func (s *WebhookService) Deliveries(
+	ctx context.Context,
+	q dtos.WebhookDeliveryQuery) (dtos.WebhookDeliveryListResponse, error) {
+	switch q.Status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return dtos.WebhookDeliveryListResponse{}, derr.New(derr.CodeValidation,
+			"status must be PENDING, DELIVERED or DEAD")
+	}
+	f := models.WebhookDeliveryFilter{
+		SubscriptionID: q.SubscriptionID,
+		Status:         q.Status,
+		AfterID:        q.AfterID,
+		Limit:          q.Limit,
+	}
+	if f.Limit <= 0 {
+		f.Limit = defaultWebhookLimit
+	}
+	if f.Limit > maxWebhookLimit {
+		return dtos.WebhookDeliveryListResponse{}, derr.New(derr.CodeValidation, "limit too large")
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, f)
+	if err != nil {
+		return dtos.WebhookDeliveryListResponse{}, err
+	}
+	resp := dtos.WebhookDeliveryListResponse{Deliveries: make([]dtos.WebhookDeliveryDTO, 0, len(deliveries))}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, mapDeliveryToDTO(d))
+	}
+	if len(deliveries) == f.Limit {
+		resp.NextAfterID = deliveries[len(deliveries)-1].ID
+	}
+	return resp, nil
+}
+
+// Redeliver queues a delivery again, including delivered and dead ones.
+func (s *WebhookService) Redeliver(
+	ctx context.Context,
+	req dtos.RedeliverWebhookRequest) (dtos.WebhookDeliveryResponse, error) {
+	var d models.WebhookDelivery
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if d, err = s.repo.Redeliver(ctx, req.DeliveryID); err != nil {
+			if errors.Is(err, repositories.ErrNotFound) {
+				return derr.New(derr.CodeNotFound, "delivery not found")
+			}
+			return err
+		}
+		// The payload is already in the delivery log; the entry only notes the reset.
+		after := mapDeliveryToDTO(d)
+		after.Payload = nil
+		return s.audit.Record(ctx, models.AuditWebhookRedeliver, models.AuditEntityDelivery,
+			strconv.FormatInt(d.ID, 10), nil, after)
+	})
+	if err != nil {
+		return dtos.WebhookDeliveryResponse{}, err
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return dtos.WebhookDeliveryResponse{Delivery: mapDeliveryToDTO(d)}, nil
+}
+
+// Start runs the sender until ctx is cancelled.
+func (s *WebhookService) Start(ctx context.Context) {
+	go s.work(ctx)
+}
+
+func (s *WebhookService) work(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		d, err := s.repo.ClaimDelivery(ctx, webhookLease)
+		if err == nil {
+			s.deliver(ctx, d)
+			continue
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			log.Printf("claim webhook delivery: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, d models.WebhookDelivery) {
+	status, err := s.send(ctx, d)
+	if err == nil {
+		if err := s.repo.MarkDelivered(ctx, d.ID, status); err != nil {
+			log.Printf("webhook delivery %d: %v", d.ID, err)
+		}
+		return
+	}
+
+	var respStatus *int
+	if status != 0 {
+		respStatus = &status
+	}
+	dead := d.Attempts >= webhookMaxAttempts
+	if err := s.repo.MarkFailed(ctx, d.ID, dead, webhookBackoff(d.Attempts), respStatus, err.Error()); err != nil {
+		log.Printf("webhook delivery %d: %v", d.ID, err)
+	}
+}
+
+// send POSTs the payload signed with the subscription secret. Any 2xx
+// response counts as delivered.
+func (s *WebhookService) send(ctx context.Context, d models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerWebhookEvent, d.Event)
+	req.Header.Set(headerWebhookDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(headerWebhookSignature, SignWebhook(d.Secret, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the X-Webhook-Signature value for body: "sha256=" and
+// the hex HMAC-SHA256 of the body keyed by the subscription secret.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff doubles the wait after every failed attempt.
+func webhookBackoff(attempts int) time.Duration {
+	d := webhookBaseBackoff
+	for i := 1; i < attempts && d < webhookMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, webhookMaxBackoff)
+}
+
+func mapWebhookToDTO(sub models.WebhookSubscription) dtos.WebhookSubscriptionDTO {
+	return dtos.WebhookSubscriptionDTO{
+		SubscriptionID: sub.ID,
+		URL:            sub.URL,
+		Events:         sub.Events,
+		CreatedAt:      sub.CreatedAt,
+	}
+}
+
+func mapDeliveryToDTO(d models.WebhookDelivery) dtos.WebhookDeliveryDTO {
+	out := dtos.WebhookDeliveryDTO{
+		DeliveryID:     d.ID,
+		SubscriptionID: d.SubscriptionID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastError:      d.LastError,
+		ResponseStatus: d.ResponseStatus,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == models.DeliveryPending {
+		out.NextAttemptAt = &d.NextAttemptAt
+	}
+	return out
+}
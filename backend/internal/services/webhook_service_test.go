@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/testdb"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
+)
+
+const testWebhookSecret = "0123456789abcdef"
+
+// webhookReceiver is a subscriber endpoint that checks every signature and
+// answers with status.
+type webhookReceiver struct {
+	*httptest.Server
+	status   atomic.Int32
+	requests atomic.Int32
+	badSigs  atomic.Int32
+}
+
+func newWebhookReceiver(t *testing.T, status int) *webhookReceiver {
+	r := &webhookReceiver{}
+	r.status.Store(int32(status))
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.requests.Add(1)
+		body, _ := io.ReadAll(req.Body)
+		want := SignWebhook(testWebhookSecret, body)
+		if !hmac.Equal([]byte(req.Header.Get(headerWebhookSignature)), []byte(want)) {
+			r.badSigs.Add(1)
+		}
+		w.WriteHeader(int(r.status.Load()))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// fakeWebhookRepo keeps deliveries in memory and ignores next attempt times,
+// so every retry can be claimed right away.
+type fakeWebhookRepo struct {
+	repositories.WebhookRepository
+	deliveries []*models.WebhookDelivery
+	retries    []time.Duration
+}
+
+func (r *fakeWebhookRepo) ClaimDelivery(context.Context, time.Duration) (models.WebhookDelivery, error) {
+	for _, d := range r.deliveries {
+		if d.Status == models.DeliveryPending {
+			d.Attempts++
+			return *d, nil
+		}
+	}
+	return models.WebhookDelivery{}, repositories.ErrNotFound
+}
+
+func (r *fakeWebhookRepo) MarkDelivered(_ context.Context, id int64, responseStatus int) error {
+	d := r.get(id)
+	d.Status, d.ResponseStatus = models.DeliveryDelivered, &responseStatus
+	return nil
+}
+
+func (r *fakeWebhookRepo) MarkFailed(
+	_ context.Context,
+	id int64,
+	dead bool,
+	retryIn time.Duration,
+	responseStatus *int,
+	errMsg string) error {
+	d := r.get(id)
+	if dead {
+		d.Status = models.DeliveryDead
+	} else {
+		r.retries = append(r.retries, retryIn)
+	}
+	d.ResponseStatus, d.LastError = responseStatus, errMsg
+	return nil
+}
+
+func (r *fakeWebhookRepo) get(id int64) *models.WebhookDelivery {
+	i := slices.IndexFunc(r.deliveries, func(d *models.WebhookDelivery) bool { return d.ID == id })
+	return r.deliveries[i]
+}
+
+// drainWebhooks does what the sender loop does until nothing is left to
+// claim, calling between after every attempt.
+func drainWebhooks(t *testing.T, s *WebhookService, ctx context.Context, between func()) {
+	t.Helper()
+	for {
+		d, err := s.repo.ClaimDelivery(ctx, webhookLease)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.deliver(ctx, d)
+		between()
+	}
+}
+
+// deliverOnce claims and sends the pending delivery a single time.
+func deliverOnce(t *testing.T, s *WebhookService) {
+	t.Helper()
+	d, err := s.repo.ClaimDelivery(context.Background(), webhookLease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.deliver(context.Background(), d)
+}
+
+func newFakeWebhookService(url string) (*WebhookService, *fakeWebhookRepo) {
+	return newFakeWebhookServiceWith(url, validators.NewWebhookValidator(true))
+}
+
+func newFakeWebhookServiceWith(url string, v validators.WebhookValidator) (*WebhookService, *fakeWebhookRepo) {
+	repo := &fakeWebhookRepo{deliveries: []*models.WebhookDelivery{{
+		ID:      1,
+		Event:   models.EventPRCreated,
+		Payload: []byte(`{"event":"pr.created"}`),
+		Status:  models.DeliveryPending,
+		URL:     url,
+		Secret:  testWebhookSecret,
+	}}}
+	return NewWebhookService(repo, nil, nil, v, time.Second), repo
+}
+
+func TestWebhookDeliverySigned(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusNoContent)
+	s, repo := newFakeWebhookService(receiver.URL)
+
+	drainWebhooks(t, s, context.Background(), func() {})
+
+	d := repo.deliveries[0]
+	if d.Status != models.DeliveryDelivered || d.Attempts != 1 {
+		t.Fatalf("delivery %s after %d attempts, want DELIVERED after 1", d.Status, d.Attempts)
+	}
+	if receiver.requests.Load() != 1 || receiver.badSigs.Load() != 0 {
+		t.Fatalf("receiver got %d requests, %d badly signed", receiver.requests.Load(), receiver.badSigs.Load())
+	}
+}
+
+// TestWebhookDeliveryChecksDestination sends to a loopback URL that never went
+// through ValidateAdd, as if its host had resolved to a public address at
+// registration and moved since: the connection itself must be refused.
+func TestWebhookDeliveryChecksDestination(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusNoContent)
+	s, repo := newFakeWebhookServiceWith(receiver.URL, validators.NewWebhookValidator(false))
+
+	deliverOnce(t, s)
+
+	d := repo.deliveries[0]
+	if d.Status != models.DeliveryPending || d.LastError == "" || d.ResponseStatus != nil {
+		t.Fatalf("delivery %s, status %v, error %q; want a failed attempt", d.Status, d.ResponseStatus, d.LastError)
+	}
+	if n := receiver.requests.Load(); n != 0 {
+		t.Fatalf("receiver got %d requests", n)
+	}
+}
+
+func TestWebhookDeliveryDoesNotFollowRedirects(t *testing.T) {
+	target := newWebhookReceiver(t, http.StatusNoContent)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	t.Cleanup(redirect.Close)
+	s, repo := newFakeWebhookService(redirect.URL)
+
+	deliverOnce(t, s)
+
+	d := repo.deliveries[0]
+	if d.Status != models.DeliveryPending || d.ResponseStatus == nil || *d.ResponseStatus != http.StatusFound {
+		t.Fatalf("delivery %s with status %v, want a failed attempt with 302", d.Status, d.ResponseStatus)
+	}
+	if n := target.requests.Load(); n != 0 {
+		t.Fatalf("redirect target got %d requests", n)
+	}
+}
+
+func TestWebhookDeadAfterLastAttempt(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable)
+	s, repo := newFakeWebhookService(receiver.URL)
+
+	drainWebhooks(t, s, context.Background(), func() {})
+
+	d := repo.deliveries[0]
+	if d.Status != models.DeliveryDead || d.Attempts != webhookMaxAttempts {
+		t.Fatalf("delivery %s after %d attempts, want DEAD after %d", d.Status, d.Attempts, webhookMaxAttempts)
+	}
+	if d.ResponseStatus == nil || *d.ResponseStatus != http.StatusServiceUnavailable {
+		t.Fatalf("response status = %v, want 503", d.ResponseStatus)
+	}
+	if n := receiver.requests.Load(); n != webhookMaxAttempts {
+		t.Fatalf("receiver got %d requests, want %d", n, webhookMaxAttempts)
+	}
+	want := []time.Duration{
+		30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute,
+		8 * time.Minute, 16 * time.Minute, 32 * time.Minute,
+	}
+	if !slices.Equal(repo.retries, want) {
+		t.Fatalf("retries = %v, want %v", repo.retries, want)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:   webhookBaseBackoff,
+		1:   30 * time.Second,
+		2:   time.Minute,
+		8:   64 * time.Minute,
+		10:  256 * time.Minute,
+		11:  webhookMaxBackoff,
+		100: webhookMaxBackoff,
+	}
+	for attempts, want := range cases {
+		if got := webhookBackoff(attempts); got != want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+// TestWebhookRedeliver runs a delivery to DEAD against the database, then
+// redelivers it to a receiver that has recovered.
+func TestWebhookRedeliver(t *testing.T) {
+	pool := testdb.New(t)
+	ctx := repositories.WithOrganization(context.Background(), models.DefaultOrganizationID)
+	audit := NewAuditService(repositories.NewAuditRepository(pool))
+	s := NewWebhookService(repositories.NewWebhookRepository(pool), repositories.NewTxManager(pool), audit,
+		validators.NewWebhookValidator(true), time.Second)
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+
+	if _, err := s.Add(ctx, dtos.AddWebhookRequest{
+		URL:    receiver.URL,
+		Events: []string{models.EventPRCreated},
+		Secret: testWebhookSecret,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Publish(ctx, models.OutboxEvent{
+		OrganizationID: models.DefaultOrganizationID,
+		Event:          models.EventPRCreated,
+		Payload:        []byte(`{"pull_request_id":"pr-1"}`),
+		CreatedAt:      time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Skip the backoff so the retries run back to back.
+	retryNow := func() {
+		if _, err := pool.Exec(ctx, `UPDATE webhook_deliveries SET next_attempt_at = NOW()`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	drainWebhooks(t, s, ctx, retryNow)
+	d := onlyDelivery(t, s, ctx)
+	if d.Status != models.DeliveryDead || d.Attempts != webhookMaxAttempts {
+		t.Fatalf("delivery %s after %d attempts, want DEAD after %d", d.Status, d.Attempts, webhookMaxAttempts)
+	}
+
+	receiver.status.Store(http.StatusOK)
+	resp, err := s.Redeliver(ctx, dtos.RedeliverWebhookRequest{DeliveryID: d.DeliveryID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Delivery.Status != models.DeliveryPending || resp.Delivery.Attempts != 0 {
+		t.Fatalf("redelivered %s with %d attempts, want PENDING with 0", resp.Delivery.Status, resp.Delivery.Attempts)
+	}
+	drainWebhooks(t, s, ctx, retryNow)
+	d = onlyDelivery(t, s, ctx)
+	if d.Status != models.DeliveryDelivered || d.Attempts != 1 {
+		t.Fatalf("delivery %s after %d attempts, want DELIVERED after 1", d.Status, d.Attempts)
+	}
+	if n := receiver.requests.Load(); n != webhookMaxAttempts+1 || receiver.badSigs.Load() != 0 {
+		t.Fatalf("receiver got %d requests, %d badly signed", n, receiver.badSigs.Load())
+	}
+
+	entries, err := audit.List(ctx, dtos.AuditQuery{Action: models.AuditWebhookRedeliver})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries.Entries) != 1 || entries.Entries[0].EntityID != strconv.FormatInt(d.DeliveryID, 10) {
+		t.Fatalf("redeliver audit entries = %+v", entries.Entries)
+	}
+}
+
+func onlyDelivery(t *testing.T, s *WebhookService, ctx context.Context) dtos.WebhookDeliveryDTO {
+	t.Helper()
+	list, err := s.Deliveries(ctx, dtos.WebhookDeliveryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Deliveries) != 1 {
+		t.Fatalf("%d deliveries, want 1", len(list.Deliveries))
+	}
+	return list.Deliveries[0]
+}
@@ -0,0 +1,54 @@
+package validators
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type AuthValidator interface {
+	ValidateIssue(ctx context.Context, in dtos.IssueTokenRequest) error
+}
+
+const maxTokenNameLen = 100
+
+type authValidator struct{}
+
+func NewAuthValidator() AuthValidator {
+	return &authValidator{}
+}
+
+func (v *authValidator) ValidateIssue(_ context.Context, in dtos.IssueTokenRequest) error {
+	if strings.TrimSpace(in.UserID) == "" {
+		return errors.New(errors.CodeValidation, "user_id required")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return errors.New(errors.CodeValidation, "name required")
+	}
+	if utf8.RuneCountInString(name) > maxTokenNameLen {
+		return errors.New(errors.CodeValidation, "name too long")
+	}
+	if len(in.Scopes) == 0 {
+		return errors.New(errors.CodeValidation, "scopes required")
+	}
+	for _, s := range in.Scopes {
+		if !knownScope(s) {
+			return errors.New(errors.CodeValidation, "unknown scope: "+s)
+		}
+	}
+	return nil
+}
+
+func knownScope(scope string) bool {
+	for _, s := range models.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
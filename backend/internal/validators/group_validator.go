@@ -0,0 +1,79 @@
+package validators
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+)
+
+type GroupValidator interface {
+	ValidateAdd(ctx context.Context, in dtos.AddReviewerGroupRequest) error
+	ValidateSetMembers(ctx context.Context, in dtos.SetGroupMembersRequest) error
+	ValidateName(ctx context.Context, name string) error
+	ValidateAddRule(ctx context.Context, in dtos.AddGroupRuleRequest) error
+}
+
+const (
+	maxGroupNameLen    = 100
+	maxTitlePatternLen = 200
+)
+
+type groupValidator struct{}
+
+func NewGroupValidator() GroupValidator {
+	return &groupValidator{}
+}
+
+func (v *groupValidator) ValidateAdd(ctx context.Context, in dtos.AddReviewerGroupRequest) error {
+	if err := v.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(in.Description) > maxDescriptionLen {
+		return errors.New(errors.CodeValidation, "description too long")
+	}
+	return validateIDList("members", in.Members)
+}
+
+func (v *groupValidator) ValidateSetMembers(ctx context.Context, in dtos.SetGroupMembersRequest) error {
+	if err := v.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	return validateIDList("members", in.Members)
+}
+
+func (v *groupValidator) ValidateName(_ context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New(errors.CodeValidation, "group_name required")
+	}
+	if utf8.RuneCountInString(name) > maxGroupNameLen {
+		return errors.New(errors.CodeValidation, "group_name too long")
+	}
+	return nil
+}
+
+func (v *groupValidator) ValidateAddRule(ctx context.Context, in dtos.AddGroupRuleRequest) error {
+	if err := v.ValidateName(ctx, in.GroupName); err != nil {
+		return err
+	}
+	label := strings.TrimSpace(in.Label)
+	if label == "" && in.TitlePattern == "" {
+		return errors.New(errors.CodeValidation, "label or title_pattern required")
+	}
+	if utf8.RuneCountInString(label) > maxLabelLen {
+		return errors.New(errors.CodeValidation, "label too long")
+	}
+	if in.TitlePattern != "" {
+		if len(in.TitlePattern) > maxTitlePatternLen {
+			return errors.New(errors.CodeValidation, "title_pattern too long")
+		}
+		if _, err := regexp.Compile(in.TitlePattern); err != nil {
+			return errors.New(errors.CodeValidation, "title_pattern is not a valid regular expression")
+		}
+	}
+	return nil
+}
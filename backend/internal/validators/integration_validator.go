@@ -0,0 +1,32 @@
+package validators
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type IntegrationValidator interface {
+	ValidateSetIdentity(ctx context.Context, in dtos.SetIdentityRequest) error
+}
+
+const maxIdentityUsernameLen = 255
+
+type integrationValidator struct{}
+
+func NewIntegrationValidator() IntegrationValidator {
+	return &integrationValidator{}
+}
+
+func (v *integrationValidator) ValidateSetIdentity(_ context.Context, in dtos.SetIdentityRequest) error {
+	if in.Provider != models.ProviderGitHub && in.Provider != models.ProviderGitLab {
+		return errors.New(errors.CodeValidation, "provider must be github or gitlab")
+	}
+	if in.Username == "" || utf8.RuneCountInString(in.Username) > maxIdentityUsernameLen {
+		return errors.New(errors.CodeValidation, "username must be 1-255 characters")
+	}
+	return nil
+}
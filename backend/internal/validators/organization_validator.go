@@ -0,0 +1,40 @@
+package validators
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+)
+
+type OrganizationValidator interface {
+	ValidateAdd(ctx context.Context, in dtos.AddOrganizationRequest) error
+}
+
+const maxOrgNameLen = 150
+
+// orgSlugRe keeps slugs safe to pass in the X-Organization header.
+var orgSlugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+type organizationValidator struct{}
+
+func NewOrganizationValidator() OrganizationValidator {
+	return &organizationValidator{}
+}
+
+func (v *organizationValidator) ValidateAdd(_ context.Context, in dtos.AddOrganizationRequest) error {
+	if !orgSlugRe.MatchString(in.Slug) {
+		return errors.New(errors.CodeValidation, "slug must be 1-50 lowercase letters, digits or dashes")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return errors.New(errors.CodeValidation, "name required")
+	}
+	if utf8.RuneCountInString(name) > maxOrgNameLen {
+		return errors.New(errors.CodeValidation, "name too long")
+	}
+	return nil
+}
@@ -3,6 +3,10 @@ package validators
 import (
 	"context"
 	stderrs "errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
@@ -11,22 +15,39 @@ import (
 
 type PRValidator interface {
 	ValidateCreate(ctx context.Context, req dtos.CreatePRRequest) error
+	ValidateSuggest(ctx context.Context, req dtos.CreatePRRequest) error
+	ValidateUpdate(ctx context.Context, req dtos.UpdatePRRequest) error
 	ValidateMerge(ctx context.Context, req dtos.MergePRRequest) error
 	ValidateReassign(ctx context.Context, req dtos.ReassignRequest) error
 }
 
+const (
+	maxTitleLen       = 100
+	maxRepositoryLen  = 200
+	maxBranchLen      = 255
+	maxDescriptionLen = 10000
+	maxURLLen         = 500
+	maxLabels         = 20
+	maxLabelLen       = 50
+	maxChangedFiles   = 3000
+)
+
 type prValidator struct {
 	prRepo   repositories.PRRepository
 	userRepo repositories.UserRepository
+	repoRepo repositories.RepoRepository
 }
 
-func NewPRValidator(pr repositories.PRRepository, user repositories.UserRepository) PRValidator {
-	return &prValidator{prRepo: pr, userRepo: user}
+func NewPRValidator(
+	pr repositories.PRRepository,
+	user repositories.UserRepository,
+	repo repositories.RepoRepository) PRValidator {
+	return &prValidator{prRepo: pr, userRepo: user, repoRepo: repo}
 }
 
 func (v *prValidator) ValidateCreate(ctx context.Context, req dtos.CreatePRRequest) error {
-	if req.PullRequestID == "" || req.Title == "" || req.Author == "" {
-		return errors.New(errors.CodeValidation, "invalid request")
+	if err := validateCreateFields(req); err != nil {
+		return err
 	}
 
 	exists, err := v.prRepo.ExistsByPullRequestID(ctx, req.PullRequestID)
@@ -37,7 +58,38 @@ func (v *prValidator) ValidateCreate(ctx context.Context, req dtos.CreatePRReque
 		return errors.New(errors.CodePRExists, "PR id already exists")
 	}
 
-	_, err = v.userRepo.GetByUserID(ctx, req.Author)
+	return v.validateAuthorAndRepository(ctx, req)
+}
+
+// ValidateSuggest accepts the create payload of a PR that may already exist,
+// since a preview writes nothing.
+func (v *prValidator) ValidateSuggest(ctx context.Context, req dtos.CreatePRRequest) error {
+	if err := validateCreateFields(req); err != nil {
+		return err
+	}
+	return v.validateAuthorAndRepository(ctx, req)
+}
+
+func validateCreateFields(req dtos.CreatePRRequest) error {
+	if req.PullRequestID == "" || req.Title == "" || req.Author == "" {
+		return errors.New(errors.CodeValidation, "invalid request")
+	}
+	if err := validateTitle(req.Title); err != nil {
+		return err
+	}
+	if err := validateMetadata(
+		req.Repository, req.SourceBranch, req.TargetBranch, req.Description, req.URL, req.Labels,
+	); err != nil {
+		return err
+	}
+	if err := validateChangedFiles(req.ChangedFiles); err != nil {
+		return err
+	}
+	return validateTags("required_tags", req.RequiredTags)
+}
+
+func (v *prValidator) validateAuthorAndRepository(ctx context.Context, req dtos.CreatePRRequest) error {
+	_, err := v.userRepo.GetByUserID(ctx, req.Author)
 	if stderrs.Is(err, repositories.ErrNotFound) {
 		return errors.New(errors.CodeNotFound, "resource not found")
 	}
@@ -45,7 +97,35 @@ func (v *prValidator) ValidateCreate(ctx context.Context, req dtos.CreatePRReque
 		return errors.New(errors.CodePRExists, "internal error")
 	}
 
-	return nil
+	return v.validateRepositoryExists(ctx, req.Repository)
+}
+
+func (v *prValidator) ValidateUpdate(ctx context.Context, req dtos.UpdatePRRequest) error {
+	if req.PullRequestID == "" {
+		return errors.New(errors.CodeValidation, "invalid request")
+	}
+	if req.Title == nil && req.Repository == nil && req.SourceBranch == nil && req.TargetBranch == nil &&
+		req.Description == nil && req.URL == nil && req.Labels == nil {
+		return errors.New(errors.CodeValidation, "nothing to update")
+	}
+	if req.Title != nil {
+		if err := validateTitle(*req.Title); err != nil {
+			return err
+		}
+	}
+
+	var labels []string
+	if req.Labels != nil {
+		labels = *req.Labels
+	}
+	if err := validateMetadata(
+		deref(req.Repository), deref(req.SourceBranch), deref(req.TargetBranch),
+		deref(req.Description), deref(req.URL), labels,
+	); err != nil {
+		return err
+	}
+
+	return v.validateRepositoryExists(ctx, deref(req.Repository))
 }
 
 func (v *prValidator) ValidateMerge(ctx context.Context, req dtos.MergePRRequest) error {
@@ -61,3 +141,116 @@ func (v *prValidator) ValidateReassign(ctx context.Context, req dtos.ReassignReq
 	}
 	return nil
 }
+
+func (v *prValidator) validateRepositoryExists(ctx context.Context, repository string) error {
+	if repository == "" {
+		return nil
+	}
+	_, err := v.repoRepo.GetByName(ctx, repository)
+	if stderrs.Is(err, repositories.ErrNotFound) {
+		return errors.New(errors.CodeNotFound, "repository not found")
+	}
+	if err != nil {
+		return errors.New(errors.CodeInternal, "internal error")
+	}
+	return nil
+}
+
+func validateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return errors.New(errors.CodeValidation, "pull_request_name empty")
+	}
+	if utf8.RuneCountInString(title) > maxTitleLen {
+		return errors.New(errors.CodeValidation, "pull_request_name too long")
+	}
+	return nil
+}
+
+func validateMetadata(repository, source, target, description, rawURL string, labels []string) error {
+	if utf8.RuneCountInString(repository) > maxRepositoryLen {
+		return errors.New(errors.CodeValidation, "repository too long")
+	}
+	if err := validateBranch("source_branch", source); err != nil {
+		return err
+	}
+	if err := validateBranch("target_branch", target); err != nil {
+		return err
+	}
+	if source != "" && source == target {
+		return errors.New(errors.CodeValidation, "source_branch and target_branch must differ")
+	}
+	if utf8.RuneCountInString(description) > maxDescriptionLen {
+		return errors.New(errors.CodeValidation, "description too long")
+	}
+	if err := validateURL(rawURL); err != nil {
+		return err
+	}
+	return validateLabels(labels)
+}
+
+func validateBranch(field, branch string) error {
+	if branch == "" {
+		return nil
+	}
+	if len(branch) > maxBranchLen {
+		return errors.New(errors.CodeValidation, field+" too long")
+	}
+	if strings.ContainsAny(branch, " \t\n~^:?*[\\") || strings.Contains(branch, "..") ||
+		strings.HasPrefix(branch, "/") || strings.HasSuffix(branch, "/") {
+		return errors.New(errors.CodeValidation, field+" is not a valid branch name")
+	}
+	return nil
+}
+
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	if len(rawURL) > maxURLLen {
+		return errors.New(errors.CodeValidation, "url too long")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New(errors.CodeValidation, "url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+func validateLabels(labels []string) error {
+	if len(labels) > maxLabels {
+		return errors.New(errors.CodeValidation, "too many labels")
+	}
+	seen := make(map[string]struct{}, len(labels))
+	for i, l := range labels {
+		if strings.TrimSpace(l) == "" {
+			return errors.New(errors.CodeValidation, "labels["+strconv.Itoa(i)+"] empty")
+		}
+		if utf8.RuneCountInString(l) > maxLabelLen {
+			return errors.New(errors.CodeValidation, "labels["+strconv.Itoa(i)+"] too long")
+		}
+		if _, ok := seen[l]; ok {
+			return errors.New(errors.CodeValidation, "duplicate label "+l)
+		}
+		seen[l] = struct{}{}
+	}
+	return nil
+}
+
+func validateChangedFiles(files []string) error {
+	if len(files) > maxChangedFiles {
+		return errors.New(errors.CodeValidation, "too many changed_files")
+	}
+	for i, f := range files {
+		if strings.TrimSpace(f) == "" {
+			return errors.New(errors.CodeValidation, "changed_files["+strconv.Itoa(i)+"] empty")
+		}
+	}
+	return nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,100 @@
+package validators
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/codeowners"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+)
+
+type RepositoryValidator interface {
+	ValidateAdd(ctx context.Context, in dtos.AddRepositoryRequest) error
+	ValidateUpdate(ctx context.Context, in dtos.UpdateRepositoryRequest) error
+	ValidateName(ctx context.Context, name string) error
+	ValidateCodeowners(ctx context.Context, in dtos.UploadCodeownersRequest) error
+}
+
+const maxCodeownersLen = 1 << 20
+
+type repositoryValidator struct{}
+
+func NewRepositoryValidator() RepositoryValidator {
+	return &repositoryValidator{}
+}
+
+func (v *repositoryValidator) ValidateAdd(ctx context.Context, in dtos.AddRepositoryRequest) error {
+	if err := v.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	if err := validateURL(in.URL); err != nil {
+		return err
+	}
+	if err := validateIDList("owner_teams", in.OwnerTeams); err != nil {
+		return err
+	}
+	return validateIDList("reviewer_pool", in.ReviewerPool)
+}
+
+func (v *repositoryValidator) ValidateUpdate(ctx context.Context, in dtos.UpdateRepositoryRequest) error {
+	if err := v.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	if in.URL == nil && in.OwnerTeams == nil && in.ReviewerPool == nil {
+		return errors.New(errors.CodeValidation, "nothing to update")
+	}
+	if in.URL != nil {
+		if err := validateURL(*in.URL); err != nil {
+			return err
+		}
+	}
+	if in.OwnerTeams != nil {
+		if err := validateIDList("owner_teams", *in.OwnerTeams); err != nil {
+			return err
+		}
+	}
+	if in.ReviewerPool != nil {
+		return validateIDList("reviewer_pool", *in.ReviewerPool)
+	}
+	return nil
+}
+
+func (v *repositoryValidator) ValidateName(_ context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New(errors.CodeValidation, "repository_name required")
+	}
+	if len(name) > maxRepositoryLen {
+		return errors.New(errors.CodeValidation, "repository_name too long")
+	}
+	return nil
+}
+
+func (v *repositoryValidator) ValidateCodeowners(ctx context.Context, in dtos.UploadCodeownersRequest) error {
+	if err := v.ValidateName(ctx, in.Name); err != nil {
+		return err
+	}
+	if len(in.Content) > maxCodeownersLen {
+		return errors.New(errors.CodeValidation, "codeowners file too large")
+	}
+	if _, err := codeowners.Parse(in.Content); err != nil {
+		return errors.New(errors.CodeValidation, "codeowners: "+err.Error())
+	}
+	return nil
+}
+
+func validateIDList(field string, ids []string) error {
+	seen := make(map[string]struct{}, len(ids))
+	for i, id := range ids {
+		if strings.TrimSpace(id) == "" {
+			return errors.New(errors.CodeValidation, field+"["+strconv.Itoa(i)+"] empty")
+		}
+		if _, ok := seen[id]; ok {
+			return errors.New(errors.CodeValidation, "duplicate "+field+" entry "+id)
+		}
+		seen[id] = struct{}{}
+	}
+	return nil
+}
@@ -13,6 +13,8 @@ import (
 
 type TeamValidator interface {
 	ValidateAddTeam(ctx context.Context, in dtos.AddTeamRequest) error
+	ValidateSetReviewPolicy(ctx context.Context, in dtos.SetReviewPolicyRequest) error
+	ValidateSetRole(ctx context.Context, in dtos.SetTeamRoleRequest) error
 }
 
 type DefaultTeamValidator struct {
@@ -45,6 +47,11 @@ func (v *DefaultTeamValidator) ValidateAddTeam(ctx context.Context, in dtos.AddT
 		if strings.TrimSpace(m.Username) == "" {
 			return errors.New(errors.CodeValidation, "members["+strconv.Itoa(i)+"].username empty")
 		}
+		if m.Seniority != "" {
+			if err := validateSeniority("members["+strconv.Itoa(i)+"].seniority", m.Seniority); err != nil {
+				return err
+			}
+		}
 		if _, ok := seen[m.UserID]; ok {
 			return errors.New(errors.CodeValidation, "duplicate user_id "+m.UserID)
 		}
@@ -69,3 +76,28 @@ func (v *DefaultTeamValidator) ValidateAddTeam(ctx context.Context, in dtos.AddT
 
 	return nil
 }
+
+func (v *DefaultTeamValidator) ValidateSetReviewPolicy(_ context.Context, in dtos.SetReviewPolicyRequest) error {
+	if strings.TrimSpace(in.TeamName) == "" {
+		return errors.New(errors.CodeValidation, "team_name empty")
+	}
+	switch in.ReviewPolicy {
+	case models.PolicyNone, models.PolicySeniorRequired, models.PolicyMentorPair:
+		return nil
+	default:
+		return errors.New(errors.CodeValidation, "review_policy must be one of NONE, SENIOR_REQUIRED, MENTOR_PAIR")
+	}
+}
+
+func (v *DefaultTeamValidator) ValidateSetRole(_ context.Context, in dtos.SetTeamRoleRequest) error {
+	if strings.TrimSpace(in.TeamName) == "" {
+		return errors.New(errors.CodeValidation, "team_name empty")
+	}
+	if strings.TrimSpace(in.UserID) == "" {
+		return errors.New(errors.CodeValidation, "user_id empty")
+	}
+	if _, ok := models.TeamRoleRank[in.Role]; !ok {
+		return errors.New(errors.CodeValidation, "role must be one of MEMBER, LEAD, ADMIN")
+	}
+	return nil
+}
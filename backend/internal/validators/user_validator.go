@@ -2,17 +2,26 @@ package validators
 
 import (
 	"context"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
 )
 
 type UserValidator interface {
 	ValidateSetIsActive(ctx context.Context, in dtos.SetIsActiveRequest) error
 	ValidateUserID(ctx context.Context, userID string) error
+	ValidateTags(ctx context.Context, in dtos.UserTagsRequest) error
+	ValidateSetSeniority(ctx context.Context, in dtos.SetSeniorityRequest) error
 }
 
+const maxTags = 20
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9+#._-]{0,29}$`)
+
 type userValidator struct{}
 
 func NewUserValidator() UserValidator {
@@ -32,3 +41,41 @@ func (v *userValidator) ValidateUserID(_ context.Context, userID string) error {
 	}
 	return nil
 }
+
+func (v *userValidator) ValidateTags(ctx context.Context, in dtos.UserTagsRequest) error {
+	if err := v.ValidateUserID(ctx, in.UserID); err != nil {
+		return err
+	}
+	if in.Tags == nil {
+		return errors.New(errors.CodeValidation, "tags required")
+	}
+	return validateTags("tags", in.Tags)
+}
+
+func validateTags(field string, tags []string) error {
+	if len(tags) > maxTags {
+		return errors.New(errors.CodeValidation, "too many "+field)
+	}
+	for i, t := range tags {
+		if !tagPattern.MatchString(strings.ToLower(strings.TrimSpace(t))) {
+			return errors.New(errors.CodeValidation, field+"["+strconv.Itoa(i)+"] is not a valid tag")
+		}
+	}
+	return nil
+}
+
+func (v *userValidator) ValidateSetSeniority(ctx context.Context, in dtos.SetSeniorityRequest) error {
+	if err := v.ValidateUserID(ctx, in.UserID); err != nil {
+		return err
+	}
+	return validateSeniority("seniority", in.Seniority)
+}
+
+func validateSeniority(field, seniority string) error {
+	switch seniority {
+	case models.SeniorityJunior, models.SeniorityMiddle, models.SenioritySenior:
+		return nil
+	default:
+		return errors.New(errors.CodeValidation, field+" must be one of JUNIOR, MIDDLE, SENIOR")
+	}
+}
@@ -0,0 +1,117 @@
+package validators
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/errors"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+type WebhookValidator interface {
+	ValidateAdd(ctx context.Context, in dtos.AddWebhookRequest) error
+	// CheckDestination tells whether deliveries may connect to addr. It is
+	// called for every connection, after DNS resolution.
+	CheckDestination(addr netip.Addr) error
+}
+
+const (
+	maxWebhookURLLen    = 2000
+	minWebhookSecretLen = 16
+	maxWebhookSecretLen = 200
+)
+
+// nonPublicPrefixes are special-purpose ranges not covered by the netip
+// predicates used in publicAddr.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+type webhookValidator struct {
+	allowPrivate bool
+	lookup       func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// NewWebhookValidator rejects subscriptions to loopback, link-local and
+// private addresses unless allowPrivate is set, so that a subscription cannot
+// be used to reach internal services.
+func NewWebhookValidator(allowPrivate bool) WebhookValidator {
+	return &webhookValidator{
+		allowPrivate: allowPrivate,
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+}
+
+func (v *webhookValidator) ValidateAdd(ctx context.Context, in dtos.AddWebhookRequest) error {
+	u, err := url.Parse(in.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(in.URL) > maxWebhookURLLen {
+		return errors.New(errors.CodeValidation, "url must be an absolute http or https URL")
+	}
+	if err := v.validateHost(ctx, u.Hostname()); err != nil {
+		return err
+	}
+	if len(in.Events) == 0 {
+		return errors.New(errors.CodeValidation, "events required")
+	}
+	for _, e := range in.Events {
+		if !slices.Contains(models.WebhookEvents, e) {
+			return errors.New(errors.CodeValidation, "unknown event "+e)
+		}
+	}
+	if in.Secret != "" && (len(in.Secret) < minWebhookSecretLen || len(in.Secret) > maxWebhookSecretLen) {
+		return errors.New(errors.CodeValidation, "secret must be 16-200 characters")
+	}
+	return nil
+}
+
+func (v *webhookValidator) validateHost(ctx context.Context, host string) error {
+	if v.allowPrivate {
+		return nil
+	}
+	addr, err := netip.ParseAddr(host)
+	addrs := []netip.Addr{addr}
+	if err != nil {
+		if addrs, err = v.lookup(ctx, host); err != nil || len(addrs) == 0 {
+			return errors.New(errors.CodeValidation, "url host does not resolve")
+		}
+	}
+	for _, addr := range addrs {
+		if err := v.CheckDestination(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *webhookValidator) CheckDestination(addr netip.Addr) error {
+	if v.allowPrivate || publicAddr(addr) {
+		return nil
+	}
+	return errors.New(errors.CodeValidation, "url must not point to a loopback, link-local or private address")
+}
+
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
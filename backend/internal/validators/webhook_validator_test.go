@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/dtos"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
+)
+
+func TestValidateAddRejectsInternalDestinations(t *testing.T) {
+	v := NewWebhookValidator(false).(*webhookValidator)
+	v.lookup = func(_ context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "hooks.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "internal.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.1.2.3")}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	cases := map[string]bool{
+		"https://hooks.example.com/pr":            true,
+		"https://93.184.216.34/pr":                true,
+		"http://[2606:2800:220:1::1]/pr":          true,
+		"http://127.0.0.1:8080/pr":                false,
+		"http://localhost/pr":                     false,
+		"http://169.254.169.254/latest/meta-data": false,
+		"http://10.0.0.1/pr":                      false,
+		"http://172.16.5.4/pr":                    false,
+		"http://192.168.1.1/pr":                   false,
+		"http://100.64.0.1/pr":                    false,
+		"http://0.0.0.0/pr":                       false,
+		"http://[::1]/pr":                         false,
+		"http://[fe80::1]/pr":                     false,
+		"http://[fd00::1]/pr":                     false,
+		"http://[::ffff:127.0.0.1]/pr":            false,
+		"https://internal.example.com/pr":         false,
+		"https://unknown.example.com/pr":          false,
+	}
+	for url, ok := range cases {
+		err := v.ValidateAdd(context.Background(), dtos.AddWebhookRequest{
+			URL:    url,
+			Events: []string{models.EventPRCreated},
+		})
+		if ok != (err == nil) {
+			t.Errorf("ValidateAdd(%s) error = %v, want ok=%v", url, err, ok)
+		}
+	}
+
+	if err := NewWebhookValidator(true).CheckDestination(netip.MustParseAddr("127.0.0.1")); err != nil {
+		t.Errorf("loopback rejected with private destinations allowed: %v", err)
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/http/handlers"
+	"github.com/KurmaevAmir/pull-request-service/backend/internal/models"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/repositories"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/services"
 	"github.com/KurmaevAmir/pull-request-service/backend/internal/validators"
@@ -21,6 +25,123 @@ func getenv(k, def string) string {
 	return def
 }
 
+func getenvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid %s: %q", k, v)
+	}
+	return n
+}
+
+func getenvDays(k string, def int) time.Duration {
+	days := def
+	if v := os.Getenv(k); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatalf("invalid %s: %q", k, v)
+		}
+		days = n
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func getenvBool(k string) bool {
+	v := os.Getenv(k)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %q", k, v)
+	}
+	return b
+}
+
+// oidcVerifier builds the JWT verifier from OIDC_* settings, or returns nil
+// when OIDC_JWKS is not set.
+func oidcVerifier() *services.OIDCVerifier {
+	jwks := os.Getenv("OIDC_JWKS")
+	if jwks == "" {
+		return nil
+	}
+	groupRoles, err := services.ParseGroupRoles(os.Getenv("OIDC_GROUP_ROLES"))
+	if err != nil {
+		log.Fatalf("invalid OIDC_GROUP_ROLES: %v", err)
+	}
+	return services.NewOIDCVerifier(services.OIDCConfig{
+		JWKS:        jwks,
+		Issuer:      os.Getenv("OIDC_ISSUER"),
+		Audience:    os.Getenv("OIDC_AUDIENCE"),
+		UserClaim:   os.Getenv("OIDC_USER_CLAIM"),
+		GroupsClaim: os.Getenv("OIDC_GROUPS_CLAIM"),
+		OrgClaim:    os.Getenv("OIDC_ORG_CLAIM"),
+		GroupRoles:  groupRoles,
+	})
+}
+
+// rateLimiter builds a limiter from the rules in the env variable key.
+// RATE_LIMIT_STORE=postgres shares buckets between instances; by default they
+// are kept in memory.
+func rateLimiter(pool *pgxpool.Pool, key string) *services.RateLimiter {
+	limits, err := services.ParseRateLimits(os.Getenv(key))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	var store services.RateLimitStore
+	switch v := getenv("RATE_LIMIT_STORE", "memory"); v {
+	case "memory":
+		store = services.NewMemoryRateLimitStore()
+	case "postgres":
+		store = repositories.NewRateLimitRepository(pool)
+	default:
+		log.Fatalf("invalid RATE_LIMIT_STORE: %q", v)
+	}
+	return services.NewRateLimiter(limits, store)
+}
+
+// trustedProxies lists the proxies from TRUSTED_PROXIES whose X-Forwarded-For
+// is believed. Without it the client IP is the peer address.
+func trustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// eventSinks picks the outbox sinks listed in OUTBOX_SINKS.
+func eventSinks(webhooks *services.WebhookService) []services.EventSink {
+	var sinks []services.EventSink
+	for _, name := range strings.Split(getenv("OUTBOX_SINKS", "webhook"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "log":
+			sinks = append(sinks, services.LogSink{})
+		case "webhook":
+			sinks = append(sinks, webhooks)
+		case "nats":
+			sink, err := services.NewNATSSink(services.NATSConfig{
+				URL:           getenv("NATS_URL", "nats://localhost:4222"),
+				SubjectPrefix: getenv("NATS_SUBJECT_PREFIX", "prs"),
+				CAFile:        getenv("NATS_CA_FILE", ""),
+			})
+			if err != nil {
+				log.Fatalf("invalid NATS config: %v", err)
+			}
+			sinks = append(sinks, sink)
+		default:
+			log.Fatalf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks
+}
+
 func main() {
 	if err := godotenv.Load("../.env"); err != nil {
 		log.Printf("env file not loaded: %v", err)
@@ -33,26 +154,106 @@ func main() {
 		log.Fatal(err)
 	}
 
+	pairWindow := getenvDays("PAIR_HISTORY_WINDOW_DAYS", 90)
+	maxOpenReviews := getenvInt("REVIEWER_MAX_OPEN_REVIEWS", 0)
+
+	seeds := services.RandomSeeds()
+	if v := os.Getenv("ASSIGNMENT_SEED"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid ASSIGNMENT_SEED: %q", v)
+		}
+		seeds = services.FixedSeeds(seed)
+	}
+
+	roleRepo := repositories.NewTeamRoleRepository(pool)
+	access := services.NewAccessControl(roleRepo)
+	txManager := repositories.NewTxManager(pool)
+
+	auditRepo := repositories.NewAuditRepository(pool)
+	auditService := services.NewAuditService(auditRepo)
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	webhookRepo := repositories.NewWebhookRepository(pool)
+	webhookService := services.NewWebhookService(webhookRepo, txManager, auditService,
+		validators.NewWebhookValidator(getenvBool("WEBHOOK_ALLOW_PRIVATE_URLS")), 5*time.Second)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	outbox := services.NewOutbox(repositories.NewOutboxRepository(pool), eventSinks(webhookService), time.Second)
+
 	userRepo := repositories.NewPgUserRepository(pool)
 	userValidator := validators.NewUserValidator()
-	userService := services.NewUserService(userRepo, userValidator)
+	userService := services.NewUserService(userRepo, txManager, access, auditService, outbox,
+		userValidator)
 	userHandler := handlers.NewUserHandler(userService)
 
+	repoRepo := repositories.NewRepoRepository(pool)
+	repositoryValidator := validators.NewRepositoryValidator()
+	repositoryService := services.NewRepositoryService(repoRepo, txManager, auditService, repositoryValidator)
+	repositoryHandler := handlers.NewRepositoryHandler(repositoryService)
+
+	groupRepo := repositories.NewGroupRepository(pool)
+	groupValidator := validators.NewGroupValidator()
+	groupService := services.NewGroupService(groupRepo, txManager, auditService, groupValidator)
+	groupHandler := handlers.NewGroupHandler(groupService)
+
+	teamRepo := repositories.NewPgTeamRepository(pool)
+	eventRepo := repositories.NewAssignmentEventRepository(pool)
+	bulkOpRepo := repositories.NewBulkOperationRepository(pool)
+
+	jobRepo := repositories.NewJobRepository(pool)
+	jobService := services.NewJobService(jobRepo, 5*time.Second)
+	jobHandler := handlers.NewJobHandler(jobService)
+
 	prRepo := repositories.NewPRRepository(pool)
-	prValidator := validators.NewPRValidator(prRepo, userRepo)
-	prService := services.NewPRService(prRepo, userRepo, prValidator)
+	prValidator := validators.NewPRValidator(prRepo, userRepo, repoRepo)
+	prService := services.NewPRService(prRepo, userRepo, repoRepo, groupRepo, teamRepo, eventRepo, txManager, access, auditService,
+		outbox, prValidator,
+		pairWindow, maxOpenReviews, seeds)
 	prHandler := handlers.NewPRHandler(prService)
 
-	teamRepo := repositories.NewPgTeamRepository(pool)
 	teamValidator := validators.NewTeamValidator(teamRepo)
-	teamService := services.NewTeamService(teamRepo, userRepo, prRepo, teamValidator)
+	teamService := services.NewTeamService(teamRepo, userRepo, prRepo, groupRepo, eventRepo, bulkOpRepo, roleRepo,
+		txManager, jobService, access, auditService, outbox, teamValidator, maxOpenReviews, seeds)
 	teamHandler := handlers.NewTeamHandler(teamService)
 
 	statsRepo := repositories.NewStatsRepository(pool)
-	statsService := services.NewStatsService(statsRepo)
+	statsService := services.NewStatsService(statsRepo, pairWindow)
 	statsHandler := handlers.NewStatsHandler(statsService)
 
-	router := handlers.NewRouter(teamHandler, userHandler, prHandler, statsHandler)
+	if err := jobService.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	outbox.Start(context.Background())
+	webhookService.Start(context.Background())
+
+	orgRepo := repositories.NewOrganizationRepository(pool)
+	orgValidator := validators.NewOrganizationValidator()
+	orgService := services.NewOrganizationService(orgRepo, orgValidator)
+	orgHandler := handlers.NewOrganizationHandler(orgService)
+
+	integrationService := services.NewIntegrationService(prService, repositories.NewIntegrationRepository(pool),
+		repoRepo, orgRepo, repositories.NewIdentityRepository(pool), txManager, auditService,
+		validators.NewIntegrationValidator(),
+		services.IntegrationConfig{
+			Organization: getenv("INTEGRATION_ORGANIZATION", models.DefaultOrganizationSlug),
+			GitHubSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+			GitLabToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+		})
+	integrationHandler := handlers.NewIntegrationHandler(integrationService)
+
+	tokenRepo := repositories.NewAPITokenRepository(pool)
+	authValidator := validators.NewAuthValidator()
+	authService := services.NewAuthService(tokenRepo, userRepo, orgRepo, txManager, auditService, authValidator,
+		os.Getenv("AUTH_BOOTSTRAP_TOKEN"), oidcVerifier())
+	authHandler := handlers.NewAuthHandler(authService)
+
+	router := handlers.NewRouter(teamHandler, userHandler, prHandler, repositoryHandler, groupHandler, statsHandler,
+		jobHandler, authHandler, auditHandler, orgHandler, webhookHandler,
+		integrationHandler, rateLimiter(pool, "RATE_LIMITS_IP"), rateLimiter(pool, "RATE_LIMITS"),
+		getenvBool("DEBUG_ROUTES"))
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 	if err := router.Run(":8080"); err != nil {
 		log.Fatal(err)
 	}
@@ -22,7 +22,11 @@ CREATE TABLE users
 DO
 $$
     BEGIN
-        IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'pr_status') THEN
+        -- Тип ищется только в текущей схеме: тесты накатывают миграции в свою схему рядом с public.
+        IF NOT EXISTS (SELECT 1
+                       FROM pg_type
+                       WHERE typname = 'pr_status'
+                         AND typnamespace = current_schema()::regnamespace) THEN
             CREATE TYPE pr_status AS ENUM ('OPEN', 'MERGED');
         END IF;
     END
@@ -0,0 +1,7 @@
+ALTER TABLE pull_requests
+    ADD COLUMN repository    VARCHAR(200) NOT NULL DEFAULT '',
+    ADD COLUMN source_branch VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN target_branch VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN description   TEXT         NOT NULL DEFAULT '',
+    ADD COLUMN url           VARCHAR(500) NOT NULL DEFAULT '',
+    ADD COLUMN labels        TEXT[]       NOT NULL DEFAULT '{}';
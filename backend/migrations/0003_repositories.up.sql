@@ -0,0 +1,26 @@
+CREATE TABLE repositories
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(200) NOT NULL,
+    url        VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ  NULL
+);
+
+CREATE UNIQUE INDEX repositories_name_uq_alive
+    ON repositories (name)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE repository_teams
+(
+    repository_id BIGINT NOT NULL REFERENCES repositories (id) ON DELETE CASCADE,
+    team_id       BIGINT NOT NULL REFERENCES teams (id) ON DELETE RESTRICT,
+    PRIMARY KEY (repository_id, team_id)
+);
+
+CREATE TABLE repository_reviewers
+(
+    repository_id BIGINT NOT NULL REFERENCES repositories (id) ON DELETE CASCADE,
+    user_id       BIGINT NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    PRIMARY KEY (repository_id, user_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE repositories
+    ADD COLUMN codeowners TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE pull_requests
+    ADD COLUMN changed_files TEXT[] NOT NULL DEFAULT '{}';
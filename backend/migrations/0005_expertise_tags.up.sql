@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE pull_requests
+    ADD COLUMN required_tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX users_tags_gin ON users USING GIN (tags);
@@ -0,0 +1,33 @@
+CREATE TABLE reviewer_groups
+(
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL,
+    description TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMPTZ  NULL
+);
+
+CREATE UNIQUE INDEX reviewer_groups_name_uq_alive
+    ON reviewer_groups (name)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE reviewer_group_members
+(
+    group_id BIGINT NOT NULL REFERENCES reviewer_groups (id) ON DELETE CASCADE,
+    user_id  BIGINT NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+    PRIMARY KEY (group_id, user_id)
+);
+
+-- Правило требует ревьюера из группы, если у PR есть метка label
+-- или название совпадает с регулярным выражением title_pattern.
+CREATE TABLE reviewer_group_rules
+(
+    id            BIGSERIAL PRIMARY KEY,
+    group_id      BIGINT       NOT NULL REFERENCES reviewer_groups (id) ON DELETE CASCADE,
+    label         VARCHAR(50)  NULL,
+    title_pattern VARCHAR(200) NULL,
+    CHECK (label IS NOT NULL OR title_pattern IS NOT NULL)
+);
+
+ALTER TABLE pr_reviews
+    ADD COLUMN required_group_id BIGINT NULL REFERENCES reviewer_groups (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE users
+    ADD COLUMN seniority VARCHAR(10) NOT NULL DEFAULT 'MIDDLE'
+        CHECK (seniority IN ('JUNIOR', 'MIDDLE', 'SENIOR'));
+
+-- NONE - без ограничений, SENIOR_REQUIRED - хотя бы один SENIOR среди ревьюеров,
+-- MENTOR_PAIR - в паре ревьюеров должны быть SENIOR и JUNIOR.
+ALTER TABLE teams
+    ADD COLUMN review_policy VARCHAR(20) NOT NULL DEFAULT 'NONE'
+        CHECK (review_policy IN ('NONE', 'SENIOR_REQUIRED', 'MENTOR_PAIR'));
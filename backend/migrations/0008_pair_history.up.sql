@@ -0,0 +1,7 @@
+-- Индексы для подсчёта истории пар автор-ревьюер за окно времени.
+CREATE INDEX IF NOT EXISTS pr_reviews_reviewer_assigned_idx
+    ON pr_reviews (reviewer_id, assigned_at);
+
+CREATE INDEX IF NOT EXISTS pull_requests_author_idx
+    ON pull_requests (author_id)
+    WHERE deleted_at IS NULL;
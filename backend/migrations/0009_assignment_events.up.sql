@@ -0,0 +1,15 @@
+-- Журнал решений о назначении ревьюеров: сид генератора и снимок кандидатов
+-- позволяют воспроизвести решение.
+CREATE TABLE assignment_events
+(
+    id         BIGSERIAL PRIMARY KEY,
+    pr_id      BIGINT      NOT NULL REFERENCES pull_requests (id) ON DELETE CASCADE,
+    kind       VARCHAR(20) NOT NULL CHECK (kind IN ('CREATE', 'REASSIGN', 'BULK_REASSIGN')),
+    seed       BIGINT      NOT NULL,
+    snapshot   JSONB       NOT NULL,
+    reviewers  TEXT[]      NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX assignment_events_pr_idx
+    ON assignment_events (pr_id, created_at);
@@ -0,0 +1,17 @@
+-- Объяснение выбора ревьюера для каждого заполненного слота.
+CREATE TABLE assignment_explanations
+(
+    id          BIGSERIAL PRIMARY KEY,
+    event_id    BIGINT      NOT NULL REFERENCES assignment_events (id) ON DELETE CASCADE,
+    pr_id       BIGINT      NOT NULL REFERENCES pull_requests (id) ON DELETE CASCADE,
+    slot        SMALLINT    NOT NULL,
+    reviewer_id VARCHAR(50) NOT NULL, -- Внешний идентификатор выбранного ревьюера.
+    rule        VARCHAR(30) NOT NULL,
+    detail      TEXT        NOT NULL DEFAULT '',
+    considered  TEXT[]      NOT NULL DEFAULT '{}',
+    excluded    JSONB       NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX assignment_explanations_pr_idx
+    ON assignment_explanations (pr_id, created_at);
@@ -0,0 +1,27 @@
+-- Журнал массовых деактиваций для отмены: прежние флаги is_active и
+-- выполненные замены ревьюеров.
+CREATE TABLE bulk_operations
+(
+    id         BIGSERIAL PRIMARY KEY,
+    team_id    BIGINT      NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    undone_at  TIMESTAMPTZ NULL
+);
+
+CREATE TABLE bulk_operation_users
+(
+    operation_id BIGINT  NOT NULL REFERENCES bulk_operations (id) ON DELETE CASCADE,
+    user_id      BIGINT  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    was_active   BOOLEAN NOT NULL,
+    PRIMARY KEY (operation_id, user_id)
+);
+
+CREATE TABLE bulk_operation_replacements
+(
+    operation_id    BIGINT   NOT NULL REFERENCES bulk_operations (id) ON DELETE CASCADE,
+    pr_id           BIGINT   NOT NULL REFERENCES pull_requests (id) ON DELETE CASCADE,
+    slot            SMALLINT NOT NULL,
+    old_reviewer_id BIGINT   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    new_reviewer_id BIGINT   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (operation_id, pr_id, slot)
+);
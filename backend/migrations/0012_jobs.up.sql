@@ -0,0 +1,22 @@
+-- Фоновые задачи. RUNNING-задачи, прерванные перезапуском, возвращаются в
+-- очередь при старте сервиса.
+CREATE TABLE jobs
+(
+    id             BIGSERIAL PRIMARY KEY,
+    kind           VARCHAR(50) NOT NULL,
+    status         VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'RUNNING', 'SUCCEEDED', 'FAILED')),
+    payload        JSONB       NOT NULL,
+    result         JSONB       NULL,
+    error          TEXT        NOT NULL DEFAULT '',
+    progress_done  INT         NOT NULL DEFAULT 0,
+    progress_total INT         NOT NULL DEFAULT 0,
+    items          JSONB       NOT NULL DEFAULT '[]',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at     TIMESTAMPTZ NULL,
+    finished_at    TIMESTAMPTZ NULL
+);
+
+CREATE INDEX jobs_pending_idx
+    ON jobs (id)
+    WHERE status = 'PENDING';
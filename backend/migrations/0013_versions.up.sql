@@ -0,0 +1,75 @@
+-- Версии для оптимистической блокировки (ETag). Версия PR растёт при любом
+-- изменении самого PR или его ревьюеров, версия команды - при изменении
+-- команды или её участников.
+ALTER TABLE pull_requests
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE teams
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_version()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_version_tr ON pull_requests;
+CREATE TRIGGER pr_version_tr
+    BEFORE UPDATE
+    ON pull_requests
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+DROP TRIGGER IF EXISTS team_version_tr ON teams;
+CREATE TRIGGER team_version_tr
+    BEFORE UPDATE
+    ON teams
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+-- Пустой UPDATE запускает pr_version_tr.
+CREATE OR REPLACE FUNCTION touch_pr_version()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE pull_requests SET version = version WHERE id = OLD.pr_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND (TG_OP = 'INSERT' OR NEW.pr_id <> OLD.pr_id) THEN
+        UPDATE pull_requests SET version = version WHERE id = NEW.pr_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_reviews_version_tr ON pr_reviews;
+CREATE TRIGGER pr_reviews_version_tr
+    AFTER INSERT OR UPDATE OR DELETE
+    ON pr_reviews
+    FOR EACH ROW
+EXECUTE FUNCTION touch_pr_version();
+
+CREATE OR REPLACE FUNCTION touch_team_version()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.team_id IS NOT NULL THEN
+        UPDATE teams SET version = version WHERE id = OLD.team_id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.team_id IS NOT NULL
+        AND (TG_OP = 'INSERT' OR NEW.team_id IS DISTINCT FROM OLD.team_id) THEN
+        UPDATE teams SET version = version WHERE id = NEW.team_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS users_team_version_tr ON users;
+CREATE TRIGGER users_team_version_tr
+    AFTER INSERT OR UPDATE OR DELETE
+    ON users
+    FOR EACH ROW
+EXECUTE FUNCTION touch_team_version();
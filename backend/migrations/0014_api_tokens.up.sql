@@ -0,0 +1,15 @@
+-- API-токены. Хранится только SHA-256 от токена; сам токен показывается один
+-- раз при выпуске.
+CREATE TABLE api_tokens
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       VARCHAR(100) NOT NULL,
+    token_hash CHAR(64)     NOT NULL UNIQUE,
+    scopes     TEXT[]       NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ  NULL
+);
+
+CREATE INDEX api_tokens_user_idx
+    ON api_tokens (user_id);
@@ -0,0 +1,9 @@
+-- Роли в командах. Участник команды без записи здесь считается MEMBER;
+-- LEAD и ADMIN могут назначаться и пользователям из других команд.
+CREATE TABLE team_roles
+(
+    team_id BIGINT      NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role    VARCHAR(10) NOT NULL CHECK (role IN ('MEMBER', 'LEAD', 'ADMIN')),
+    PRIMARY KEY (team_id, user_id)
+);
@@ -0,0 +1,24 @@
+-- Журнал изменений: кто, в рамках какого запроса и что изменил. Запись
+-- делается в той же транзакции, что и само изменение.
+CREATE TABLE audit_log
+(
+    id          BIGSERIAL PRIMARY KEY,
+    actor       VARCHAR(50)  NOT NULL,  -- user_id, bootstrap или system.
+    token_id    BIGINT       NULL,
+    request_id  VARCHAR(100) NOT NULL DEFAULT '',
+    action      VARCHAR(50)  NOT NULL,
+    entity_type VARCHAR(30)  NOT NULL,
+    entity_id   VARCHAR(100) NOT NULL,
+    before      JSONB        NULL,
+    after       JSONB        NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_entity_idx
+    ON audit_log (entity_type, entity_id);
+
+CREATE INDEX audit_log_actor_idx
+    ON audit_log (actor, created_at);
+
+CREATE INDEX audit_log_created_idx
+    ON audit_log (created_at);
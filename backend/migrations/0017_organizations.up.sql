@@ -0,0 +1,78 @@
+-- Организации (арендаторы). Все существующие данные переносятся в организацию
+-- default; имена команд, репозиториев и групп, а также внешние user_id и pr_id
+-- уникальны в пределах организации.
+CREATE TABLE organizations
+(
+    id         BIGSERIAL PRIMARY KEY,
+    slug       VARCHAR(50)  NOT NULL UNIQUE,
+    name       VARCHAR(150) NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO organizations (id, slug, name)
+VALUES (1, 'default', 'Default');
+
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), 1);
+
+-- DEFAULT нужен только для заполнения существующих строк; новые строки
+-- должны явно указывать организацию.
+ALTER TABLE teams
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE teams
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE users
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE users
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE pull_requests
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE pull_requests
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE repositories
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE repositories
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE reviewer_groups
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE reviewer_groups
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE jobs
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE jobs
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+ALTER TABLE audit_log
+    ADD COLUMN organization_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations (id);
+ALTER TABLE audit_log
+    ALTER COLUMN organization_id DROP DEFAULT;
+
+DROP INDEX teams_name_uq_alive;
+CREATE UNIQUE INDEX teams_name_uq_alive
+    ON teams (organization_id, name)
+    WHERE deleted_at IS NULL;
+
+DROP INDEX repositories_name_uq_alive;
+CREATE UNIQUE INDEX repositories_name_uq_alive
+    ON repositories (organization_id, name)
+    WHERE deleted_at IS NULL;
+
+DROP INDEX reviewer_groups_name_uq_alive;
+CREATE UNIQUE INDEX reviewer_groups_name_uq_alive
+    ON reviewer_groups (organization_id, name)
+    WHERE deleted_at IS NULL;
+
+CREATE UNIQUE INDEX users_user_id_uq_alive
+    ON users (organization_id, user_id)
+    WHERE deleted_at IS NULL;
+
+CREATE UNIQUE INDEX pull_requests_pr_id_uq_alive
+    ON pull_requests (organization_id, pr_id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX audit_log_org_idx
+    ON audit_log (organization_id, id);
@@ -0,0 +1,11 @@
+-- Состояние token bucket'ов ограничителя запросов, когда он общий для
+-- нескольких экземпляров сервиса (RATE_LIMIT_STORE=postgres).
+CREATE TABLE rate_limit_buckets
+(
+    key        VARCHAR(300)     PRIMARY KEY,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX rate_limit_buckets_updated_idx
+    ON rate_limit_buckets (updated_at);
@@ -0,0 +1,42 @@
+-- Подписки на исходящие вебхуки. Секрет используется для подписи тела
+-- запроса HMAC-SHA256.
+CREATE TABLE webhook_subscriptions
+(
+    id              BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT        NOT NULL REFERENCES organizations (id),
+    url             VARCHAR(2000) NOT NULL,
+    secret          VARCHAR(200)  NOT NULL,
+    events          TEXT[]        NOT NULL,
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    deleted_at      TIMESTAMPTZ   NULL
+);
+
+CREATE INDEX webhook_subscriptions_org_idx
+    ON webhook_subscriptions (organization_id)
+    WHERE deleted_at IS NULL;
+
+-- Очередь доставок: строки пишутся в той же транзакции, что и изменение,
+-- о котором сообщают. Неудачные попытки повторяются с экспоненциальной
+-- задержкой, после исчерпания попыток доставка переходит в DEAD.
+CREATE TABLE webhook_deliveries
+(
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT      NOT NULL REFERENCES webhook_subscriptions (id),
+    event           VARCHAR(50) NOT NULL,
+    payload         JSONB       NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT        NOT NULL DEFAULT '',
+    response_status INT         NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ NULL
+);
+
+CREATE INDEX webhook_deliveries_due_idx
+    ON webhook_deliveries (next_attempt_at, id)
+    WHERE status = 'PENDING';
+
+CREATE INDEX webhook_deliveries_subscription_idx
+    ON webhook_deliveries (subscription_id, id);
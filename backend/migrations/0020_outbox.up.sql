@@ -0,0 +1,29 @@
+-- Исходящие доменные события. Пишутся в той же транзакции, что и изменение;
+-- фоновый релей публикует их в приёмники (лог, вебхуки) не менее одного раза.
+-- События с одинаковым ordering_key (например, одного PR) публикуются строго
+-- по порядку id.
+CREATE TABLE outbox_events
+(
+    id              BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT       NOT NULL REFERENCES organizations (id),
+    event           VARCHAR(50)  NOT NULL,
+    ordering_key    VARCHAR(200) NOT NULL,
+    payload         JSONB        NOT NULL,
+    attempts        INT          NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_error      TEXT         NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    published_at    TIMESTAMPTZ  NULL
+);
+
+CREATE INDEX outbox_events_pending_idx
+    ON outbox_events (id)
+    WHERE published_at IS NULL;
+
+CREATE INDEX outbox_events_key_idx
+    ON outbox_events (organization_id, ordering_key, id)
+    WHERE published_at IS NULL;
+
+CREATE INDEX outbox_events_published_idx
+    ON outbox_events (published_at)
+    WHERE published_at IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Обработанные входящие вебхуки внешних систем (GitHub, GitLab). Повторная
+-- доставка с тем же идентификатором не выполняется второй раз.
+CREATE TABLE integration_deliveries
+(
+    provider        VARCHAR(20)  NOT NULL,
+    delivery_id     VARCHAR(100) NOT NULL,
+    organization_id BIGINT       NOT NULL REFERENCES organizations (id),
+    event           VARCHAR(50)  NOT NULL,
+    received_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, delivery_id)
+);
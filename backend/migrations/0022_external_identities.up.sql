@@ -0,0 +1,11 @@
+-- Соответствие учётных записей внешних систем (например, username в GitLab)
+-- пользователям сервиса.
+CREATE TABLE external_identities
+(
+    organization_id BIGINT       NOT NULL REFERENCES organizations (id),
+    provider        VARCHAR(20)  NOT NULL,
+    username        VARCHAR(255) NOT NULL,
+    user_id         BIGINT       NOT NULL REFERENCES users (id),
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, provider, username)
+);
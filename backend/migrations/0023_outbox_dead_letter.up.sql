@@ -0,0 +1,20 @@
+-- Событие, которое не удалось опубликовать за отведённое число попыток,
+-- помечается dead_at и больше не задерживает следующие события своего
+-- ordering_key. Такие события не удаляются очисткой опубликованных.
+ALTER TABLE outbox_events
+    ADD COLUMN dead_at TIMESTAMPTZ NULL;
+
+DROP INDEX outbox_events_pending_idx;
+DROP INDEX outbox_events_key_idx;
+
+CREATE INDEX outbox_events_pending_idx
+    ON outbox_events (id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+
+CREATE INDEX outbox_events_key_idx
+    ON outbox_events (organization_id, ordering_key, id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+
+CREATE INDEX outbox_events_dead_idx
+    ON outbox_events (dead_at)
+    WHERE dead_at IS NOT NULL;